@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"godo/internal/auth"
+	"godo/internal/blob"
 	"godo/internal/config"
+	"godo/internal/domain"
 	"godo/internal/handlers"
+	"godo/internal/mail"
 	"godo/internal/service"
 	"godo/internal/store"
 	"log"
@@ -40,22 +45,98 @@ func main() {
 	// Repositories
 	userRepo := store.NewUserRepo(db)
 	todoRepo := store.NewTodoRepo(db)
+	tagRepo := store.NewTagRepo(db)
+	subtaskRepo := store.NewSubtaskRepo(db)
+	projectRepo := store.NewProjectRepo(db)
+	commentRepo := store.NewCommentRepo(db)
+	attachmentRepo := store.NewAttachmentRepo(db)
+	shareRepo := store.NewShareRepo(db)
+	dependencyRepo := store.NewDependencyRepo(db)
+	auditRepo := store.NewAuditRepo(db)
+	revisionRepo := store.NewRevisionRepo(db)
+	refreshTokenRepo := store.NewRefreshTokenRepo(db)
+	revokedTokenRepo := store.NewRevokedTokenRepo(db)
+	sessionRepo := store.NewSessionRepo(db)
+	passwordResetRepo := store.NewPasswordResetRepo(db)
+
+	blobStore, err := newBlobStore(cfg)
+	if err != nil {
+		logger.Error("Failed to initialize blob store", "error", err)
+		os.Exit(1)
+	}
+
+	mailer, err := newMailer(cfg)
+	if err != nil {
+		logger.Error("Failed to initialize mailer", "error", err)
+		os.Exit(1)
+	}
 
 	// Services
-	authService := service.NewAuthService(userRepo)
-	todoService := service.NewTodoService(todoRepo)
-	userService := service.NewUserService(userRepo)
+	transactor := store.NewTransactor(db)
+	tokenRevocations := service.NewTokenRevocations(revokedTokenRepo, userRepo)
+	authService := service.NewAuthService(userRepo, sessionRepo, refreshTokenRepo, tokenRevocations, auditRepo, service.TokenConfig{
+		Secret:     cfg.JWTSecret,
+		AccessTTL:  cfg.AccessTokenTTL,
+		RefreshTTL: cfg.RefreshTokenTTL,
+	})
+	passwordResetService := service.NewPasswordResetService(userRepo, passwordResetRepo, mailer, authService, auditRepo, service.PasswordResetConfig{
+		TTL:     cfg.PasswordResetTTL,
+		BaseURL: cfg.BaseURL,
+	}, logger, transactor)
+	todoService := service.NewTodoService(
+		domain.Repos{
+			Todos:        todoRepo,
+			Tags:         tagRepo,
+			Subtasks:     subtaskRepo,
+			Projects:     projectRepo,
+			Shares:       shareRepo,
+			Users:        userRepo,
+			Dependencies: dependencyRepo,
+			Audit:        auditRepo,
+			Revisions:    revisionRepo,
+		},
+		transactor,
+		service.DeletePolicy{
+			OwnersCanDelete:     cfg.OwnersCanDelete,
+			OwnersCanHardDelete: cfg.OwnersCanHardDelete,
+		},
+		service.CompletionPolicy{RequireBlockersDone: cfg.RequireBlockersDone},
+	)
+	userService := service.NewUserService(userRepo, auditRepo, transactor)
+	tagService := service.NewTagService(tagRepo, auditRepo, transactor)
+	subtaskService := service.NewSubtaskService(subtaskRepo, auditRepo, todoService, transactor)
+	projectService := service.NewProjectService(projectRepo, auditRepo, transactor)
+	commentService := service.NewCommentService(commentRepo, auditRepo, todoService, transactor)
+	attachmentService := service.NewAttachmentService(attachmentRepo, blobStore, auditRepo, todoService, cfg.AttachmentMaxBytes, transactor)
+	auditService := service.NewAuditService(auditRepo)
+	shareService := service.NewShareService(shareRepo, userRepo, auditRepo, todoService, projectService, transactor)
 
 	// Handlers
-	authHandler := handlers.NewAuthHandler(authService, logger, cfg.JWTSecret)
+	authHandler := handlers.NewAuthHandler(authService, logger)
 	todoHandler := handlers.NewTodoHandler(todoService, logger)
 	userHandler := handlers.NewUserHandler(userService, logger)
-	webHandler := handlers.NewWebHandler(authService, todoService, cfg.JWTSecret)
+	tagHandler := handlers.NewTagHandler(tagService, logger)
+	subtaskHandler := handlers.NewSubtaskHandler(subtaskService, logger)
+	projectHandler := handlers.NewProjectHandler(projectService, logger)
+	commentHandler := handlers.NewCommentHandler(commentService, logger)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, logger)
+	shareHandler := handlers.NewShareHandler(shareService, logger)
+	auditHandler := handlers.NewAuditHandler(auditService, logger)
+	sessionHandler := handlers.NewSessionHandler(authService, logger)
+	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService, logger)
+	webHandler := handlers.NewWebHandler(authService, passwordResetService, todoService, projectService, commentService)
+
+	// Background jobs
+	go runPeriodically(context.Background(), trashPurgeInterval, purgeTrash(todoService, cfg.TrashRetention, logger))
+	go runPeriodically(context.Background(), autoArchiveInterval, autoArchive(todoService, logger))
+	go runPeriodically(context.Background(), blobCleanupInterval, cleanupBlobs(attachmentService, logger))
+	go runPeriodically(context.Background(), revocationPurgeInterval, purgeRevocations(tokenRevocations, logger))
 
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
+	r.Use(requestInfoMiddleware)
 	r.Use(loggerMiddleware(logger))
 	r.Use(middleware.Recoverer)
 	r.Use(corsMiddleware(cfg.AllowedOrigins))
@@ -64,32 +145,136 @@ func main() {
 
 	r.With(authRateLimiter(logger)).Post("/api/register", authHandler.Register)
 	r.With(authRateLimiter(logger)).Post("/api/login", authHandler.Login)
+	r.Post("/api/token/refresh", authHandler.Refresh)
+	r.With(authRateLimiter(logger)).Post("/api/password/forgot", passwordResetHandler.Forgot)
+	r.With(authRateLimiter(logger)).Post("/api/password/reset", passwordResetHandler.Reset)
+	r.With(auth.Middleware(cfg.JWTSecret, tokenRevocations)).Post("/api/logout", authHandler.Logout)
+	r.With(auth.Middleware(cfg.JWTSecret, tokenRevocations)).Post("/api/logout/all", authHandler.LogoutEverywhere)
 
 	r.Route("/api/todos", func(r chi.Router) {
-		r.Use(auth.Middleware(cfg.JWTSecret))
+		r.Use(auth.Middleware(cfg.JWTSecret, tokenRevocations))
 		r.Post("/", todoHandler.Create)
 		r.Get("/", todoHandler.List)
+		r.Post("/bulk", todoHandler.Bulk)
+		r.Get("/search", todoHandler.Search)
+		r.Get("/trash", todoHandler.Trash)
+		r.Get("/archive", todoHandler.Archive)
 		r.Get("/{id}", todoHandler.GetByID)
 		r.Patch("/{id}", todoHandler.Update)
+		r.Post("/{id}/move", todoHandler.Move)
+		r.Post("/{id}/assign", todoHandler.Assign)
+		r.Get("/{id}/blockers", todoHandler.Blockers)
+		r.Post("/{id}/blockers", todoHandler.AddBlocker)
+		r.Delete("/{id}/blockers/{blockerID}", todoHandler.RemoveBlocker)
+		r.Get("/{id}/history", todoHandler.History)
+		r.Post("/{id}/revert/{rev}", todoHandler.Revert)
+		r.Post("/{id}/restore", todoHandler.Restore)
+		r.Post("/{id}/unarchive", todoHandler.Unarchive)
 		r.Delete("/{id}", todoHandler.Delete)
+
+		r.Route("/{id}/subtasks", func(r chi.Router) {
+			r.Get("/", subtaskHandler.List)
+			r.Post("/", subtaskHandler.Create)
+			r.Post("/reorder", subtaskHandler.Reorder)
+			r.Patch("/{subtaskID}", subtaskHandler.Update)
+			r.Post("/{subtaskID}/toggle", subtaskHandler.Toggle)
+			r.Delete("/{subtaskID}", subtaskHandler.Delete)
+		})
+
+		r.Route("/{id}/comments", func(r chi.Router) {
+			r.Get("/", commentHandler.List)
+			r.Post("/", commentHandler.Create)
+			r.Patch("/{commentID}", commentHandler.Update)
+			r.Delete("/{commentID}", commentHandler.Delete)
+		})
+
+		r.Route("/{id}/attachments", func(r chi.Router) {
+			r.Get("/", attachmentHandler.List)
+			r.Post("/", attachmentHandler.Upload)
+			r.Get("/{attachmentID}", attachmentHandler.Download)
+			r.Delete("/{attachmentID}", attachmentHandler.Delete)
+		})
+
+		r.Route("/{id}/shares", func(r chi.Router) {
+			r.Get("/", shareHandler.ListTodoShares)
+			r.Post("/", shareHandler.ShareTodo)
+			r.Delete("/{shareID}", shareHandler.RevokeTodoShare)
+		})
+	})
+
+	r.Route("/api/tags", func(r chi.Router) {
+		r.Use(auth.Middleware(cfg.JWTSecret, tokenRevocations))
+		r.Post("/", tagHandler.Create)
+		r.Get("/", tagHandler.List)
+		r.Get("/{id}", tagHandler.GetByID)
+		r.Patch("/{id}", tagHandler.Update)
+		r.Delete("/{id}", tagHandler.Delete)
+	})
+
+	r.Route("/api/projects", func(r chi.Router) {
+		r.Use(auth.Middleware(cfg.JWTSecret, tokenRevocations))
+		r.Post("/", projectHandler.Create)
+		r.Get("/", projectHandler.List)
+		r.Get("/{id}", projectHandler.GetByID)
+		r.Patch("/{id}", projectHandler.Update)
+		r.Delete("/{id}", projectHandler.Delete)
+
+		r.Route("/{id}/shares", func(r chi.Router) {
+			r.Get("/", shareHandler.ListProjectShares)
+			r.Post("/", shareHandler.ShareProject)
+			r.Delete("/{shareID}", shareHandler.RevokeProjectShare)
+		})
 	})
 
 	r.Route("/api/users", func(r chi.Router) {
-		r.Use(auth.Middleware(cfg.JWTSecret))
+		r.Use(auth.Middleware(cfg.JWTSecret, tokenRevocations))
 		r.Get("/", userHandler.List)
 		r.Get("/{id}", userHandler.GetByID)
 		r.Patch("/{id}", userHandler.Update)
 		r.Delete("/{id}", userHandler.Delete)
+		r.Get("/{id}/sessions", sessionHandler.ListUserSessions)
+		r.Delete("/{id}/sessions/{sessionID}", sessionHandler.RevokeUserSession)
+	})
+
+	r.Route("/api/sessions", func(r chi.Router) {
+		r.Use(auth.Middleware(cfg.JWTSecret, tokenRevocations))
+		r.Get("/", sessionHandler.List)
+		r.Delete("/{id}", sessionHandler.Revoke)
+	})
+
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(auth.Middleware(cfg.JWTSecret, tokenRevocations))
+		r.Get("/audit", auditHandler.List)
 	})
 
 	r.Get("/login", webHandler.LoginPage)
 	r.Post("/login", webHandler.Login)
+	r.Get("/forgot-password", webHandler.ForgotPasswordPage)
+	r.With(authRateLimiter(logger)).Post("/forgot-password", webHandler.ForgotPassword)
+	r.Get("/reset-password", webHandler.ResetPasswordPage)
+	r.With(authRateLimiter(logger)).Post("/reset-password", webHandler.ResetPassword)
 
 	r.Group(func(r chi.Router) {
-		r.Use(auth.CookieMiddleware(cfg.JWTSecret))
+		r.Use(auth.CookieMiddleware(cfg.JWTSecret, tokenRevocations, authService.Refresh))
+		r.Post("/logout", webHandler.Logout)
+		r.Post("/logout/all", webHandler.LogoutEverywhere)
+		r.Get("/sessions", webHandler.SessionsPage)
+		r.Delete("/sessions/{id}", webHandler.RevokeSession)
 		r.Get("/todos", webHandler.TodosPage)
+		r.Get("/todos/search", webHandler.SearchTodos)
+		r.Get("/todos/{id}", webHandler.TodoPage)
+		r.Get("/trash", webHandler.TrashPage)
 		r.Post("/todos", webHandler.CreateTodo)
+		r.Post("/todos/bulk", webHandler.BulkTodos)
+		r.Post("/projects", webHandler.CreateProject)
 		r.Patch("/todos/{id}", webHandler.UpdateTodo)
+		r.Delete("/todos/{id}", webHandler.DeleteTodo)
+		r.Post("/todos/{id}/move", webHandler.MoveTodo)
+		r.Post("/todos/{id}/restore", webHandler.RestoreTodo)
+		r.Post("/todos/{id}/unarchive", webHandler.UnarchiveTodo)
+		r.Post("/todos/{id}/revert/{rev}", webHandler.RevertTodo)
+		r.Post("/todos/{id}/comments", webHandler.CreateComment)
+		r.Delete("/todos/{id}/comments/{commentID}", webHandler.DeleteComment)
 	})
 
 	addr := ":" + cfg.Port
@@ -127,3 +312,39 @@ func setupLogger(level, format string) *slog.Logger {
 
 	return slog.New(handler)
 }
+
+// newBlobStore opens the configured store for attachment contents.
+func newBlobStore(cfg *config.Config) (domain.BlobStore, error) {
+	if cfg.BlobStore == "s3" {
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	}
+	return blob.NewLocalStore(cfg.BlobDir)
+}
+
+// newMailer picks the mailer configured by MAILER.
+func newMailer(cfg *config.Config) (domain.Mailer, error) {
+	if cfg.Mailer == "smtp" {
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+	}
+
+	if cfg.MailLogFile == "" {
+		return mail.NewLogMailer(os.Stdout, cfg.MailFrom), nil
+	}
+	f, err := os.OpenFile(cfg.MailLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mail log: %w", err)
+	}
+	return mail.NewLogMailer(f, cfg.MailFrom), nil
+}
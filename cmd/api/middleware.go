@@ -1,7 +1,9 @@
 package main
 
 import (
+	"godo/internal/service"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
@@ -33,6 +35,25 @@ func loggerMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// requestInfoMiddleware passes the request ID and client IP on to the
+// services, which record them in the audit log. It runs after RequestID and
+// RealIP.
+func requestInfoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+
+		ctx := service.WithRequestInfo(r.Context(), service.RequestInfo{
+			RequestID: middleware.GetReqID(r.Context()),
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func corsMiddleware(allowedOrigins string) func(http.Handler) http.Handler {
 	return cors.Handler(cors.Options{
 		AllowedOrigins:   []string{allowedOrigins},
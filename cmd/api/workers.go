@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"godo/internal/service"
+	"log/slog"
+	"time"
+)
+
+// trashPurgeInterval is how often deleted todos past their retention are purged.
+const trashPurgeInterval = time.Hour
+
+// autoArchiveInterval is how often completed todos are checked for archiving.
+const autoArchiveInterval = time.Hour
+
+// blobCleanupInterval is how often blobs of removed attachments are deleted.
+const blobCleanupInterval = 10 * time.Minute
+
+// revocationPurgeInterval is how often revocations of expired tokens are dropped.
+const revocationPurgeInterval = time.Hour
+
+// runPeriodically calls fn with ctx right away and then every interval until
+// ctx is done.
+func runPeriodically(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fn(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeTrash permanently removes todos that have been deleted for longer than retention.
+func purgeTrash(todoService *service.TodoService, retention time.Duration, logger *slog.Logger) func(context.Context) {
+	return func(ctx context.Context) {
+		purged, err := todoService.PurgeTrash(ctx, retention)
+		if err != nil {
+			logger.Error("Failed to purge trash", "error", err)
+			return
+		}
+		if purged > 0 {
+			logger.Info("Trash purged", "count", purged)
+		}
+	}
+}
+
+// autoArchive archives completed todos for users who turned auto-archiving on.
+func autoArchive(todoService *service.TodoService, logger *slog.Logger) func(context.Context) {
+	return func(ctx context.Context) {
+		archived, err := todoService.AutoArchive(ctx)
+		if err != nil {
+			logger.Error("Failed to archive todos", "error", err)
+			return
+		}
+		if archived > 0 {
+			logger.Info("Todos archived", "count", archived)
+		}
+	}
+}
+
+// cleanupBlobs deletes the stored files of attachments that were removed,
+// directly or along with their todo.
+func cleanupBlobs(attachmentService *service.AttachmentService, logger *slog.Logger) func(context.Context) {
+	return func(context.Context) {
+		deleted, err := attachmentService.CleanupBlobs()
+		if err != nil {
+			logger.Error("Failed to clean up blobs", "error", err)
+			return
+		}
+		if deleted > 0 {
+			logger.Info("Blobs cleaned up", "count", deleted)
+		}
+	}
+}
+
+// purgeRevocations forgets revoked access tokens once they have expired.
+func purgeRevocations(revocations *service.TokenRevocations, logger *slog.Logger) func(context.Context) {
+	return func(context.Context) {
+		purged, err := revocations.PurgeExpired()
+		if err != nil {
+			logger.Error("Failed to purge token revocations", "error", err)
+			return
+		}
+		if purged > 0 {
+			logger.Info("Token revocations purged", "count", purged)
+		}
+	}
+}
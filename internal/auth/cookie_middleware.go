@@ -2,19 +2,45 @@ package auth
 
 import "net/http"
 
-func CookieMiddleware(secret string) func(http.Handler) http.Handler {
+// CookieMiddleware authenticates web requests by the access token cookie.
+// Once that has expired, the refresh token cookie is traded in through
+// refresh for a new session, so users stay logged in without noticing.
+// Tokens revoked according to revocations are turned away.
+func CookieMiddleware(secret string, revocations RevocationChecker, refresh RefreshFunc) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			cookie, err := r.Cookie("auth_token")
-			if err != nil {
-				http.Redirect(w, r, "/login", http.StatusSeeOther)
-				return
+			var claims *Claims
+			if cookie, err := r.Cookie(AccessTokenCookie); err == nil {
+				claims, err = authenticate(cookie.Value, secret, revocations)
+				if err != nil && !rejected(err) {
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
 			}
 
-			claims, err := ValidateToken(cookie.Value, secret)
-			if err != nil {
-				http.Redirect(w, r, "/login", http.StatusSeeOther)
-				return
+			if claims == nil {
+				cookie, err := r.Cookie(RefreshTokenCookie)
+				if err != nil {
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
+
+				session, err := refresh(r.Context(), cookie.Value)
+				if err != nil && !rejected(err) {
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					return
+				}
+				if err != nil {
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
+
+				claims, err = ValidateToken(session.AccessToken, secret)
+				if err != nil {
+					http.Redirect(w, r, "/login", http.StatusSeeOther)
+					return
+				}
+				SetSessionCookies(w, session)
 			}
 
 			ctx := SetClaims(r.Context(), claims)
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
@@ -12,20 +13,29 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// Claims identify the user a token was issued to. The registered ID (jti)
+// names the token so it can be revoked on its own, and SessionID the login it
+// belongs to; TokenVersion is the user's token version at issue, and all
+// tokens with an older one are revoked.
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID       string `json:"user_id"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	TokenVersion int    `json:"ver"`
+	SessionID    string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID, email, role, secret string, expiration time.Duration) (string, error) {
+func GenerateToken(userID, email, role string, tokenVersion int, sessionID, secret string, expiration time.Duration) (string, error) {
 	now := time.Now()
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:       userID,
+		Email:        email,
+		Role:         role,
+		TokenVersion: tokenVersion,
+		SessionID:    sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(now.Add(expiration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
@@ -8,7 +8,7 @@ import (
 const testSecret = "test-secret-key"
 
 func TestGenerateToken(t *testing.T) {
-	token, err := GenerateToken("user-123", "test@example.com", "user", testSecret, time.Hour)
+	token, err := GenerateToken("user-123", "test@example.com", "user", 0, "", testSecret, time.Hour)
 	if err != nil {
 		t.Fatalf("GenerateToken failed: %v", err)
 	}
@@ -30,11 +30,15 @@ func TestGenerateToken(t *testing.T) {
 	if claims.Role != "user" {
 		t.Errorf("expected Role 'user', got '%s'", claims.Role)
 	}
+
+	if claims.ID == "" {
+		t.Error("expected a jti identifying the token")
+	}
 }
 
 func TestValidateToken(t *testing.T) {
-	validToken, _ := GenerateToken("user-123", "test@example.com", "user", testSecret, time.Hour)
-	expiredToken, _ := GenerateToken("user-123", "test@example.com", "user", testSecret, -time.Hour)
+	validToken, _ := GenerateToken("user-123", "test@example.com", "user", 0, "", testSecret, time.Hour)
+	expiredToken, _ := GenerateToken("user-123", "test@example.com", "user", 0, "", testSecret, -time.Hour)
 
 	tests := []struct {
 		name      string
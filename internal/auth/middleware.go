@@ -10,7 +10,9 @@ type contextKey string
 
 const userContextKey contextKey = "user"
 
-func Middleware(secret string) func(http.Handler) http.Handler {
+// Middleware authenticates API requests by their bearer token, turning away
+// tokens revoked according to revocations.
+func Middleware(secret string, revocations RevocationChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -27,9 +29,13 @@ func Middleware(secret string) func(http.Handler) http.Handler {
 
 			tokenString := parts[1]
 
-			claims, err := ValidateToken(tokenString, secret)
+			claims, err := authenticate(tokenString, secret, revocations)
 			if err != nil {
-				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				if rejected(err) {
+					http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+					return
+				}
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
 
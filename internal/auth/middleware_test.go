@@ -2,15 +2,25 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 )
 
+// revokedVersions revokes tokens older than the user's version in it.
+type revokedVersions map[string]int
+
+func (r revokedVersions) IsRevoked(claims *Claims) (bool, error) {
+	return claims.TokenVersion < r[claims.UserID], nil
+}
+
 func TestMiddleware(t *testing.T) {
-	validToken, _ := GenerateToken("user-123", "test@example.com", "user", testSecret, time.Hour)
-	expiredToken, _ := GenerateToken("user-123", "test@example.com", "user", testSecret, -time.Hour)
+	validToken, _ := GenerateToken("user-123", "test@example.com", "user", 0, "", testSecret, time.Hour)
+	expiredToken, _ := GenerateToken("user-123", "test@example.com", "user", 0, "", testSecret, -time.Hour)
+	revokedToken, _ := GenerateToken("user-456", "other@example.com", "user", 0, "", testSecret, time.Hour)
+	revocations := revokedVersions{"user-456": 1}
 
 	tests := []struct {
 		name           string
@@ -49,6 +59,11 @@ func TestMiddleware(t *testing.T) {
 			authHeader: "Bearer invalid.token.here",
 			wantStatus: http.StatusUnauthorized,
 		},
+		{
+			name:       "revoked token",
+			authHeader: "Bearer " + revokedToken,
+			wantStatus: http.StatusUnauthorized,
+		},
 	}
 
 	for _, tt := range tests {
@@ -59,7 +74,7 @@ func TestMiddleware(t *testing.T) {
 				w.WriteHeader(http.StatusOK)
 			})
 
-			handler := Middleware(testSecret)(next)
+			handler := Middleware(testSecret, revocations)(next)
 
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
 			if tt.authHeader != "" {
@@ -105,3 +120,84 @@ func TestGetClaims(t *testing.T) {
 		}
 	})
 }
+
+func TestCookieMiddleware(t *testing.T) {
+	validToken, _ := GenerateToken("user-123", "test@example.com", "user", 0, "", testSecret, time.Hour)
+	expiredToken, _ := GenerateToken("user-123", "test@example.com", "user", 0, "", testSecret, -time.Hour)
+	revokedToken, _ := GenerateToken("user-456", "other@example.com", "user", 0, "", testSecret, time.Hour)
+	revocations := revokedVersions{"user-456": 1}
+
+	refresh := func(ctx context.Context, refreshToken string) (*Session, error) {
+		if refreshToken == "broken-refresh" {
+			return nil, errors.New("database is locked")
+		}
+		if refreshToken != "good-refresh" {
+			return nil, ErrInvalidToken
+		}
+		return &Session{
+			AccessToken:      validToken,
+			AccessExpiresAt:  time.Now().Add(time.Hour),
+			RefreshToken:     "next-refresh",
+			RefreshExpiresAt: time.Now().Add(24 * time.Hour),
+		}, nil
+	}
+
+	tests := []struct {
+		name          string
+		accessToken   string
+		refreshToken  string
+		wantNext      bool
+		wantRefreshed bool
+		wantCode      int
+	}{
+		{name: "valid access token", accessToken: validToken, wantNext: true},
+		{name: "expired access token is refreshed", accessToken: expiredToken, refreshToken: "good-refresh", wantNext: true, wantRefreshed: true},
+		{name: "missing access token is refreshed", refreshToken: "good-refresh", wantNext: true, wantRefreshed: true},
+		{name: "expired without refresh token", accessToken: expiredToken},
+		{name: "revoked access token is refreshed", accessToken: revokedToken, refreshToken: "good-refresh", wantNext: true, wantRefreshed: true},
+		{name: "revoked without refresh token", accessToken: revokedToken},
+		{name: "rejected refresh token", accessToken: expiredToken, refreshToken: "bad-refresh"},
+		{name: "failed refresh is an error", accessToken: expiredToken, refreshToken: "broken-refresh", wantCode: http.StatusInternalServerError},
+		{name: "no cookies"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotClaims *Claims
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotClaims, _ = GetClaims(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			if tt.accessToken != "" {
+				req.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: tt.accessToken})
+			}
+			if tt.refreshToken != "" {
+				req.AddCookie(&http.Cookie{Name: RefreshTokenCookie, Value: tt.refreshToken})
+			}
+			rr := httptest.NewRecorder()
+
+			CookieMiddleware(testSecret, revocations, refresh)(next).ServeHTTP(rr, req)
+
+			if (gotClaims != nil) != tt.wantNext {
+				t.Fatalf("expected next called=%v, got %v", tt.wantNext, gotClaims != nil)
+			}
+			if tt.wantCode != 0 && rr.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rr.Code)
+			}
+			if !tt.wantNext && tt.wantCode == 0 && rr.Code != http.StatusSeeOther {
+				t.Errorf("expected a redirect to login, got %d", rr.Code)
+			}
+
+			refreshed := false
+			for _, c := range rr.Result().Cookies() {
+				if c.Name == RefreshTokenCookie && c.Value == "next-refresh" {
+					refreshed = true
+				}
+			}
+			if refreshed != tt.wantRefreshed {
+				t.Errorf("expected refreshed cookies=%v, got %v", tt.wantRefreshed, refreshed)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package auth
+
+import "errors"
+
+var ErrRevokedToken = errors.New("token has been revoked")
+
+// RevocationChecker reports whether a validly signed token was revoked
+// before it expired, e.g. when its user logged out.
+type RevocationChecker interface {
+	IsRevoked(claims *Claims) (bool, error)
+}
+
+// authenticate validates the token and checks that it wasn't revoked. It
+// returns ErrInvalidToken, ErrExpiredToken or ErrRevokedToken for tokens that
+// must be turned away, and any other error when the check itself failed.
+func authenticate(tokenString, secret string, revocations RevocationChecker) (*Claims, error) {
+	claims, err := ValidateToken(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := revocations.IsRevoked(claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrRevokedToken
+	}
+
+	return claims, nil
+}
+
+// rejected reports whether err turns a token away, rather than reporting a
+// failed revocation check.
+func rejected(err error) bool {
+	return errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrExpiredToken) || errors.Is(err, ErrRevokedToken)
+}
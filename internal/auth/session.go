@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	AccessTokenCookie  = "auth_token"
+	RefreshTokenCookie = "refresh_token"
+)
+
+// Session is what a client holds after logging in: a short-lived access
+// token, and a refresh token that gets it a new Session once that expires.
+type Session struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// RefreshFunc trades a refresh token in for a new Session. Tokens that must
+// be turned away give an error wrapping ErrInvalidToken or ErrRevokedToken;
+// any other error means the refresh itself failed.
+type RefreshFunc func(ctx context.Context, refreshToken string) (*Session, error)
+
+// SetSessionCookies stores the session in the browser. Each cookie lasts as
+// long as its token.
+func SetSessionCookies(w http.ResponseWriter, session *Session) {
+	http.SetCookie(w, sessionCookie(AccessTokenCookie, session.AccessToken, session.AccessExpiresAt))
+	http.SetCookie(w, sessionCookie(RefreshTokenCookie, session.RefreshToken, session.RefreshExpiresAt))
+}
+
+func sessionCookie(name, value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(time.Until(expires).Seconds()),
+	}
+}
+
+// ClearSessionCookies removes the session from the browser.
+func ClearSessionCookies(w http.ResponseWriter) {
+	for _, name := range []string{AccessTokenCookie, RefreshTokenCookie} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+			MaxAge:   -1,
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package blob
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLocalStore_RoundTrip(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	testStoreRoundTrip(t, store, "todos/1/report.pdf")
+}
+
+func TestLocalStore_RejectsEscapingKeys(t *testing.T) {
+	store, _ := NewLocalStore(t.TempDir())
+
+	for _, key := range []string{"", "../outside", "todos/../../outside", "/etc/passwd"} {
+		if err := store.Put(key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
+
+func TestLocalStore_SizeMismatch(t *testing.T) {
+	store, _ := NewLocalStore(t.TempDir())
+
+	if err := store.Put("short", strings.NewReader("abc"), 10, "text/plain"); err == nil {
+		t.Error("expected a short upload to fail")
+	}
+	if _, err := store.Get("short"); err == nil {
+		t.Error("expected no blob to be left behind")
+	}
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +17,45 @@ type Config struct {
 	LogLevel          string
 	LogFormat         string
 	AllowedOrigins    string
+	// AccessTokenTTL is how long an access token is valid; clients refresh
+	// it with a refresh token, which lasts RefreshTokenTTL.
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// TrashRetention is how long deleted todos stay in the trash before
+	// they are purged.
+	TrashRetention time.Duration
+	// OwnersCanDelete lets users delete their own todos; admins always can.
+	OwnersCanDelete bool
+	// OwnersCanHardDelete lets users also delete their own todos
+	// permanently instead of moving them to the trash.
+	OwnersCanHardDelete bool
+	// RequireBlockersDone refuses to complete a todo while any todo it
+	// depends on is still open.
+	RequireBlockersDone bool
+	// AttachmentMaxBytes is the largest file that may be attached to a todo.
+	AttachmentMaxBytes int64
+	// BlobStore picks where attachment contents are kept: "local" for a
+	// directory on disk (BlobDir) or "s3" for an S3-compatible bucket.
+	BlobStore   string
+	BlobDir     string
+	S3Endpoint  string
+	S3Bucket    string
+	S3Region    string
+	S3AccessKey string
+	S3SecretKey string
+	// BaseURL is where the web UI is served, for links in emails.
+	BaseURL string
+	// PasswordResetTTL is how long a mailed password reset link works.
+	PasswordResetTTL time.Duration
+	// Mailer picks how email goes out: "log" writes it to MailLogFile, or
+	// stdout if unset, for development; "smtp" sends it through SMTPHost.
+	Mailer       string
+	MailFrom     string
+	MailLogFile  string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
 }
 
 func Load() (*Config, error) {
@@ -29,6 +70,79 @@ func Load() (*Config, error) {
 		LogLevel:          getEnv("LOG_LEVEL", "info"),
 		LogFormat:         getEnv("LOG_FORMAT", "json"),
 		AllowedOrigins:    getEnv("ALLOWED_ORIGINS", "http://localhost:3000"),
+		BlobStore:         getEnv("BLOB_STORE", "local"),
+		BlobDir:           getEnv("BLOB_DIR", "./data/attachments"),
+		S3Endpoint:        getEnv("S3_ENDPOINT", ""),
+		S3Bucket:          getEnv("S3_BUCKET", ""),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3AccessKey:       getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:       getEnv("S3_SECRET_KEY", ""),
+		BaseURL:           getEnv("BASE_URL", "http://localhost:8080"),
+		Mailer:            getEnv("MAILER", "log"),
+		MailFrom:          getEnv("MAIL_FROM", "godo@localhost"),
+		MailLogFile:       getEnv("MAIL_LOG_FILE", ""),
+		SMTPHost:          getEnv("SMTP_HOST", ""),
+		SMTPUsername:      getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:      getEnv("SMTP_PASSWORD", ""),
+	}
+
+	retention, err := time.ParseDuration(getEnv("TRASH_RETENTION", "720h"))
+	if err != nil || retention <= 0 {
+		return nil, fmt.Errorf("TRASH_RETENTION must be a positive duration such as 720h")
+	}
+	cfg.TrashRetention = retention
+
+	if cfg.AccessTokenTTL, err = getDurationEnv("ACCESS_TOKEN_TTL", "15m"); err != nil {
+		return nil, err
+	}
+	if cfg.RefreshTokenTTL, err = getDurationEnv("REFRESH_TOKEN_TTL", "720h"); err != nil {
+		return nil, err
+	}
+
+	if cfg.PasswordResetTTL, err = getDurationEnv("PASSWORD_RESET_TTL", "1h"); err != nil {
+		return nil, err
+	}
+
+	if cfg.OwnersCanDelete, err = getBoolEnv("OWNERS_CAN_DELETE", true); err != nil {
+		return nil, err
+	}
+	if cfg.OwnersCanHardDelete, err = getBoolEnv("OWNERS_CAN_HARD_DELETE", false); err != nil {
+		return nil, err
+	}
+	if cfg.RequireBlockersDone, err = getBoolEnv("REQUIRE_BLOCKERS_DONE", false); err != nil {
+		return nil, err
+	}
+
+	maxBytes, err := strconv.ParseInt(getEnv("ATTACHMENT_MAX_BYTES", "10485760"), 10, 64)
+	if err != nil || maxBytes <= 0 {
+		return nil, fmt.Errorf("ATTACHMENT_MAX_BYTES must be a positive number of bytes")
+	}
+	cfg.AttachmentMaxBytes = maxBytes
+
+	switch cfg.BlobStore {
+	case "local":
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+			return nil, fmt.Errorf("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required when BLOB_STORE is s3")
+		}
+	default:
+		return nil, fmt.Errorf("BLOB_STORE must be local or s3")
+	}
+
+	smtpPort, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	if err != nil || smtpPort <= 0 {
+		return nil, fmt.Errorf("SMTP_PORT must be a port number")
+	}
+	cfg.SMTPPort = smtpPort
+
+	switch cfg.Mailer {
+	case "log":
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required when MAILER is smtp")
+		}
+	default:
+		return nil, fmt.Errorf("MAILER must be log or smtp")
 	}
 
 	// Validate required fields
@@ -48,3 +162,23 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getBoolEnv(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", key)
+	}
+	return b, nil
+}
+
+func getDurationEnv(key, defaultValue string) (time.Duration, error) {
+	d, err := time.ParseDuration(getEnv(key, defaultValue))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration such as %s", key, defaultValue)
+	}
+	return d, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadSuccess(t *testing.T) {
@@ -27,6 +28,90 @@ func TestLoadSuccess(t *testing.T) {
 	if cfg.Port != "8080" {
 		t.Errorf("expected default Port=8080, got %s", cfg.Port)
 	}
+	if cfg.TrashRetention != 30*24*time.Hour {
+		t.Errorf("expected default TrashRetention=720h, got %s", cfg.TrashRetention)
+	}
+	if cfg.AccessTokenTTL != 15*time.Minute || cfg.RefreshTokenTTL != 30*24*time.Hour {
+		t.Errorf("expected 15m access and 720h refresh tokens by default, got %s/%s", cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	}
+	if !cfg.OwnersCanDelete || cfg.OwnersCanHardDelete {
+		t.Errorf("expected owners to delete only to the trash by default, got %t/%t", cfg.OwnersCanDelete, cfg.OwnersCanHardDelete)
+	}
+	if cfg.RequireBlockersDone {
+		t.Errorf("expected blocked todos to be completable by default")
+	}
+	if cfg.BlobStore != "local" || cfg.AttachmentMaxBytes != 10<<20 {
+		t.Errorf("expected local blobs up to 10 MiB by default, got %s/%d", cfg.BlobStore, cfg.AttachmentMaxBytes)
+	}
+	if cfg.Mailer != "log" || cfg.PasswordResetTTL != time.Hour {
+		t.Errorf("expected logged email and 1h reset links by default, got %s/%s", cfg.Mailer, cfg.PasswordResetTTL)
+	}
+}
+
+func TestLoad_IncompleteSMTPMailer(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("DATABASE_URL", "/tmp/test.db")
+	os.Setenv("JWT_SECRET", "test-secret")
+	os.Setenv("MAILER", "smtp")
+	defer os.Clearenv()
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for smtp mailer without a host, got nil")
+	}
+}
+
+func TestLoad_IncompleteS3BlobStore(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("DATABASE_URL", "/tmp/test.db")
+	os.Setenv("JWT_SECRET", "test-secret")
+	os.Setenv("BLOB_STORE", "s3")
+	os.Setenv("S3_ENDPOINT", "http://localhost:9000")
+	defer os.Clearenv()
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for s3 blob store without a bucket and keys, got nil")
+	}
+}
+
+func TestLoad_InvalidDeletePolicy(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("DATABASE_URL", "/tmp/test.db")
+	os.Setenv("JWT_SECRET", "test-secret")
+	os.Setenv("OWNERS_CAN_DELETE", "sometimes")
+	defer os.Clearenv()
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for invalid OWNERS_CAN_DELETE, got nil")
+	}
+}
+
+func TestLoad_InvalidTrashRetention(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("DATABASE_URL", "/tmp/test.db")
+	os.Setenv("JWT_SECRET", "test-secret")
+	os.Setenv("TRASH_RETENTION", "a month")
+	defer os.Clearenv()
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for invalid TRASH_RETENTION, got nil")
+	}
+}
+
+func TestLoad_InvalidAccessTokenTTL(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("DATABASE_URL", "/tmp/test.db")
+	os.Setenv("JWT_SECRET", "test-secret")
+	os.Setenv("ACCESS_TOKEN_TTL", "-5m")
+	defer os.Clearenv()
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for negative ACCESS_TOKEN_TTL, got nil")
+	}
 }
 
 func TestLoad_MissingDatabaseURL(t *testing.T) {
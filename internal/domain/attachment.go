@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"fmt"
+	"mime"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const MaxAttachmentFilenameLength = 255
+
+// AttachmentTypes lists the MIME types that may be uploaded.
+var AttachmentTypes = []string{
+	"application/pdf",
+	"image/gif",
+	"image/jpeg",
+	"image/png",
+	"image/webp",
+	"text/plain",
+}
+
+// Attachment is a file uploaded to a todo. The contents live in a BlobStore
+// under StorageKey; the row only keeps what's needed to serve them back.
+type Attachment struct {
+	ID          string    `json:"id"`
+	TodoID      string    `json:"todo_id"`
+	UserID      string    `json:"user_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func NewAttachment(todoID, userID, filename, contentType string, size int64) *Attachment {
+	id := NewID()
+	return &Attachment{
+		ID:          id,
+		TodoID:      todoID,
+		UserID:      userID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  "todos/" + todoID + "/" + id,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// AttachmentTypeAllowed reports whether a MIME type, parameters aside, may be
+// uploaded.
+func AttachmentTypeAllowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && slices.Contains(AttachmentTypes, mediaType)
+}
+
+// NormalizeAttachmentFilename keeps the last element of an uploaded file's
+// name, drops control characters and checks its length.
+func NormalizeAttachmentFilename(name string) (string, error) {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == "/" {
+		return "", fmt.Errorf("%w: filename is required", ErrInvalidAttachment)
+	}
+	if len(name) > MaxAttachmentFilenameLength {
+		return "", fmt.Errorf("%w: filename must be at most %d characters", ErrInvalidAttachment, MaxAttachmentFilenameLength)
+	}
+	return name, nil
+}
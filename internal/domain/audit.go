@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// AuditEvent records one change made through the service layer. ActorID is
+// empty for changes made by background jobs.
+type AuditEvent struct {
+	ID         string                 `json:"id"`
+	ActorID    string                 `json:"actor_id"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type"`
+	TargetID   string                 `json:"target_id"`
+	Changes    map[string]AuditChange `json:"changes"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	IP         string                 `json:"ip,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// AuditChange holds the old and new value of one field. Before is null for
+// a created record and After is null for a deleted one.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+func NewAuditEvent(actorID, action, targetType, targetID string) *AuditEvent {
+	return &AuditEvent{
+		ID:         NewID(),
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Changes:    map[string]AuditChange{},
+		CreatedAt:  time.Now(),
+	}
+}
+
+// AuditFilter narrows an audit listing. Empty fields match every event.
+type AuditFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	Since      *time.Time
+	Until      *time.Time
+}
+
+// AuditDiff compares the JSON forms of before and after field by field and
+// returns the fields that differ. Either side may be nil, or JSON already
+// encoded as a json.RawMessage.
+func AuditDiff(before, after any) (map[string]AuditChange, error) {
+	old, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]AuditChange{}
+	for field, value := range old {
+		if !reflect.DeepEqual(value, updated[field]) {
+			changes[field] = AuditChange{Before: value, After: updated[field]}
+		}
+	}
+	for field, value := range updated {
+		if _, ok := old[field]; !ok {
+			changes[field] = AuditChange{After: value}
+		}
+	}
+
+	return changes, nil
+}
+
+func auditFields(v any) (map[string]any, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return map[string]any{}, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const MaxCommentLength = 10000
+
+// Comment is one entry in a todo's discussion thread. AuthorEmail is filled
+// in when comments are read back so the thread can show who wrote what.
+type Comment struct {
+	ID          string    `json:"id"`
+	TodoID      string    `json:"todo_id"`
+	UserID      string    `json:"user_id"`
+	AuthorEmail string    `json:"author_email,omitempty"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewComment(todoID, userID, body string) *Comment {
+	now := time.Now()
+	return &Comment{
+		ID:        NewID(),
+		TodoID:    todoID,
+		UserID:    userID,
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Edited reports whether the comment was changed after it was posted.
+func (c *Comment) Edited() bool {
+	return c.UpdatedAt.After(c.CreatedAt)
+}
+
+// NormalizeCommentBody trims a comment body and checks its length.
+func NormalizeCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("%w: body is required", ErrInvalidComment)
+	}
+	if len(body) > MaxCommentLength {
+		return "", fmt.Errorf("%w: body must be at most %d characters", ErrInvalidComment, MaxCommentLength)
+	}
+	return body, nil
+}
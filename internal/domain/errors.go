@@ -3,6 +3,39 @@ package domain
 import "errors"
 
 var (
-	ErrUserNotFound = errors.New("user not found")
-	ErrTodoNotFound = errors.New("todo not found")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrTodoNotFound      = errors.New("todo not found")
+	ErrInvalidDueDate    = errors.New("invalid due date")
+	ErrInvalidPriority   = errors.New("invalid priority")
+	ErrTagNotFound       = errors.New("tag not found")
+	ErrInvalidTag        = errors.New("invalid tag")
+	ErrSubtaskNotFound   = errors.New("subtask not found")
+	ErrProjectNotFound   = errors.New("project not found")
+	ErrInvalidProject    = errors.New("invalid project")
+	ErrInvalidPosition   = errors.New("invalid position")
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrInvalidRecurrence = errors.New("invalid recurrence")
+	ErrInvalidSetting    = errors.New("invalid setting")
+	ErrCommentNotFound   = errors.New("comment not found")
+	ErrInvalidComment    = errors.New("invalid comment")
+
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrInvalidAttachment  = errors.New("invalid attachment")
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	ErrBlobNotFound       = errors.New("blob not found")
+
+	ErrShareNotFound = errors.New("share not found")
+	ErrInvalidShare  = errors.New("invalid share")
+
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrInvalidDependency  = errors.New("invalid dependency")
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+	ErrTodoBlocked        = errors.New("todo is blocked by open todos")
+
+	ErrRevisionNotFound = errors.New("revision not found")
+
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrSessionNotFound      = errors.New("session not found")
+
+	ErrPasswordResetTokenNotFound = errors.New("password reset token not found")
 )
@@ -0,0 +1,9 @@
+package domain
+
+// PageRequest asks for one page of a listing. Cursor is the NextCursor
+// handed out with the previous page, or empty for the first page. A zero
+// Limit returns everything after the cursor.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// PasswordResetToken lets whoever holds it set a new password for the user,
+// once and before it expires. It is mailed to the user's address; only the
+// hash of the token is stored.
+type PasswordResetToken struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+// NewPasswordResetToken creates a token for userID that expires after ttl,
+// and returns it along with the secret value to mail to the user.
+func NewPasswordResetToken(userID string, ttl time.Duration) (*PasswordResetToken, string, error) {
+	value, err := newSecretToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	return &PasswordResetToken{
+		ID:        NewID(),
+		UserID:    userID,
+		TokenHash: HashPasswordResetToken(value),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, value, nil
+}
+
+// HashPasswordResetToken returns the hash a reset token is stored and looked
+// up by.
+func HashPasswordResetToken(value string) string {
+	return hashSecretToken(value)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const MaxProjectNameLength = 100
+
+// Project groups a user's todos into a list. Progress counts the project's
+// completed todos against all of them.
+type Project struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Archived    bool      `json:"archived"`
+	Progress    Progress  `json:"progress"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewProject(userID, name, description string) *Project {
+	now := time.Now()
+	return &Project{
+		ID:          NewID(),
+		UserID:      userID,
+		Name:        name,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// NormalizeProjectName trims a project name and checks its length.
+func NormalizeProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidProject)
+	}
+	if len(name) > MaxProjectNameLength {
+		return "", fmt.Errorf("%w: name must be at most %d characters", ErrInvalidProject, MaxProjectNameLength)
+	}
+	return name, nil
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Ranks order todos by plain string comparison. A rank is read as a base-36
+// fraction, so there is always room for another rank between two others and
+// moving a todo only rewrites that todo. Ranks never end in '0', which keeps
+// each fraction's spelling unique.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// InitialRank places a new todo above every todo created before it, so a
+// list nobody has rearranged reads newest first. The id suffix keeps todos
+// created on the same clock tick apart, as the position migration does.
+func InitialRank(createdAt time.Time, id string) string {
+	return strings.TrimRight(fmt.Sprintf("%019d", math.MaxInt64-createdAt.UnixNano())+strings.ReplaceAll(id, "-", ""), "0")
+}
+
+// RankBetween returns a rank that sorts after lower and before upper. An
+// empty lower or upper leaves that side open.
+func RankBetween(lower, upper string) (string, error) {
+	if upper != "" && lower >= upper {
+		return "", fmt.Errorf("%w: %q does not sort before %q", ErrInvalidPosition, lower, upper)
+	}
+
+	var rank []byte
+	bounded := upper != ""
+	for i := 0; ; i++ {
+		lo := rankDigit(lower, i)
+		hi := len(rankDigits)
+		if bounded {
+			if i >= len(upper) {
+				return "", fmt.Errorf("%w: %q is not a valid rank", ErrInvalidPosition, upper)
+			}
+			hi = rankDigit(upper, i)
+		}
+
+		if hi-lo > 1 {
+			return string(append(rank, rankDigits[(lo+hi)/2])), nil
+		}
+
+		// No digit fits at this place; copy the lower digit and look further
+		// right. Once below upper's digit, upper no longer constrains us.
+		rank = append(rank, rankDigits[lo])
+		if hi > lo {
+			bounded = false
+		}
+	}
+}
+
+// rankDigit returns the value of the i-th digit of rank, which is 0 past
+// its end.
+func rankDigit(rank string, i int) int {
+	if i >= len(rank) {
+		return 0
+	}
+	if d := strings.IndexByte(rankDigits, rank[i]); d >= 0 {
+		return d
+	}
+	return 0
+}
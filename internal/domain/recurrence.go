@@ -0,0 +1,294 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the base period of a recurrence rule.
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+// Frequencies lists the supported frequencies from shortest to longest.
+func Frequencies() []Frequency {
+	return []Frequency{FreqDaily, FreqWeekly, FreqMonthly, FreqYearly}
+}
+
+const untilLayout = "20060102"
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence is the subset of an RFC 5545 RRULE that todos support: FREQ,
+// INTERVAL, BYDAY (daily and weekly rules only, without ordinals), UNTIL and
+// COUNT. Weeks start on Monday. Count is the number of occurrences left,
+// including the current one; zero means unbounded.
+type Recurrence struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	Until    *time.Time
+	Count    int
+}
+
+// ParseRecurrence parses an RRULE such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH".
+// The "RRULE:" prefix is optional and keys are case-insensitive.
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	if rule == "" {
+		return nil, fmt.Errorf("%w: rule is empty", ErrInvalidRecurrence)
+	}
+
+	r := &Recurrence{Interval: 1}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRecurrence, part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s given twice", ErrInvalidRecurrence, key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				r.Freq = Frequency(value)
+			default:
+				return nil, fmt.Errorf("%w: FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY", ErrInvalidRecurrence)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRecurrence)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive number", ErrInvalidRecurrence)
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = &until
+		case "BYDAY":
+			days, err := parseByDay(value)
+			if err != nil {
+				return nil, err
+			}
+			r.ByDay = days
+		default:
+			return nil, fmt.Errorf("%w: %s is not supported", ErrInvalidRecurrence, key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrence)
+	}
+	if r.Until != nil && r.Count > 0 {
+		return nil, fmt.Errorf("%w: UNTIL and COUNT cannot be combined", ErrInvalidRecurrence)
+	}
+	if len(r.ByDay) > 0 && r.Freq != FreqDaily && r.Freq != FreqWeekly {
+		return nil, fmt.Errorf("%w: BYDAY is only supported with DAILY or WEEKLY", ErrInvalidRecurrence)
+	}
+
+	return r, nil
+}
+
+// parseUntil accepts the RRULE date and UTC date-time forms. Only the date
+// is kept; occurrences are compared by day.
+func parseUntil(value string) (time.Time, error) {
+	if len(value) == len("20060102T150405Z") && value[8] == 'T' {
+		value = value[:8]
+	}
+	until, err := time.Parse(untilLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: UNTIL must be YYYYMMDD", ErrInvalidRecurrence)
+	}
+	return until, nil
+}
+
+// parseByDay reads a BYDAY list into weekdays ordered Monday first.
+func parseByDay(value string) ([]time.Weekday, error) {
+	seen := make(map[time.Weekday]bool)
+	var days []time.Weekday
+	for _, code := range strings.Split(value, ",") {
+		day, ok := weekdayCodes[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown BYDAY value %q", ErrInvalidRecurrence, code)
+		}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return weekOffset(days[i]) < weekOffset(days[j]) })
+	return days, nil
+}
+
+// weekOffset is the number of days from Monday to day.
+func weekOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+func weekdayCode(day time.Weekday) string {
+	for code, d := range weekdayCodes {
+		if d == day {
+			return code
+		}
+	}
+	return ""
+}
+
+// String renders the rule in canonical RRULE form, without the "RRULE:" prefix.
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = weekdayCode(day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format(untilLayout))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// MarshalText encodes the rule as an RRULE string so it reads naturally in JSON.
+func (r Recurrence) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Recurrence) UnmarshalText(text []byte) error {
+	parsed, err := ParseRecurrence(string(text))
+	if err != nil {
+		return err
+	}
+	*r = *parsed
+	return nil
+}
+
+var frequencyUnits = map[Frequency]string{
+	FreqDaily:   "day",
+	FreqWeekly:  "week",
+	FreqMonthly: "month",
+	FreqYearly:  "year",
+}
+
+// Describe summarises the rule for display, e.g. "Every 2 weeks on Mon, Thu".
+func (r *Recurrence) Describe() string {
+	s := "Every " + frequencyUnits[r.Freq]
+	if r.Interval > 1 {
+		s = fmt.Sprintf("Every %d %ss", r.Interval, frequencyUnits[r.Freq])
+	}
+	if len(r.ByDay) > 0 {
+		names := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			names[i] = day.String()[:3]
+		}
+		s += " on " + strings.Join(names, ", ")
+	}
+	if r.Until != nil {
+		s += " until " + r.Until.Format("Jan 2, 2006")
+	}
+	if r.Count == 1 {
+		s += ", last time"
+	} else if r.Count > 1 {
+		s += fmt.Sprintf(", %d more times", r.Count-1)
+	}
+	return s
+}
+
+// Next returns the first occurrence date strictly after from, ignoring COUNT.
+// from is treated as a calendar date. It reports false once the series has
+// passed UNTIL or no later date can match the rule.
+func (r *Recurrence) Next(from time.Time) (time.Time, bool) {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	interval := max(r.Interval, 1)
+
+	next, ok := r.step(from, interval)
+	if !ok || (r.Until != nil && next.After(*r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func (r *Recurrence) step(from time.Time, interval int) (time.Time, bool) {
+	switch r.Freq {
+	case FreqDaily:
+		// Stepping by the interval revisits every reachable weekday within a week's worth of steps
+		for k := 1; k <= 7; k++ {
+			next := from.AddDate(0, 0, k*interval)
+			if len(r.ByDay) == 0 || r.onDay(next.Weekday()) {
+				return next, true
+			}
+		}
+	case FreqWeekly:
+		if len(r.ByDay) == 0 {
+			return from.AddDate(0, 0, 7*interval), true
+		}
+		weekStart := from.AddDate(0, 0, -weekOffset(from.Weekday()))
+		for next := from.AddDate(0, 0, 1); next.Before(weekStart.AddDate(0, 0, 7)); next = next.AddDate(0, 0, 1) {
+			if r.onDay(next.Weekday()) {
+				return next, true
+			}
+		}
+		return weekStart.AddDate(0, 0, 7*interval+weekOffset(r.ByDay[0])), true
+	case FreqMonthly:
+		// Months too short for the day are skipped, as RFC 5545 does
+		for k := 1; k <= 12; k++ {
+			year, month := from.Year(), from.Month()+time.Month(k*interval)
+			if next := time.Date(year, month, from.Day(), 0, 0, 0, 0, time.UTC); next.Day() == from.Day() {
+				return next, true
+			}
+		}
+	case FreqYearly:
+		for k := 1; k <= 8; k++ {
+			year := from.Year() + k*interval
+			if next := time.Date(year, from.Month(), from.Day(), 0, 0, 0, 0, time.UTC); next.Day() == from.Day() {
+				return next, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Recurrence) onDay(day time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
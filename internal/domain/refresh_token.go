@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// RefreshToken lets a client trade it in for a new access token, once. Each
+// exchange hands out a new token in the same family, so a family follows one
+// login; presenting a token that was already used means it leaked, and the
+// whole family is revoked. Only the hash of the token is stored.
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+// NewRefreshToken creates a token for userID in familyID that expires after
+// ttl, and returns it along with the secret value to hand to the client.
+func NewRefreshToken(userID, familyID string, ttl time.Duration) (*RefreshToken, string, error) {
+	value, err := newSecretToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	return &RefreshToken{
+		ID:        NewID(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: HashRefreshToken(value),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, value, nil
+}
+
+// HashRefreshToken returns the hash a refresh token is stored and looked up by.
+func HashRefreshToken(value string) string {
+	return hashSecretToken(value)
+}
+
+// newSecretToken returns 32 random bytes encoded for use in URLs.
+func newSecretToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func hashSecretToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
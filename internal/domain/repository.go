@@ -1,20 +1,210 @@
 package domain
 
+import (
+	"io"
+	"time"
+)
+
 type UserRepository interface {
 	Create(user *User) error
 	GetByEmail(email string) (*User, error)
 	GetByID(id string) (*User, error)
-	GetAll() ([]*User, error)
+	GetAll(page PageRequest) ([]*User, string, error)
 	Update(user *User) error
 	Delete(id string) error
 	CountByRole(role string) (int, error)
+	BumpTokenVersion(id string) error
+}
+
+// RefreshTokenRepository keeps issued refresh tokens by the hash of their value.
+type RefreshTokenRepository interface {
+	Create(token *RefreshToken) error
+	GetByHash(tokenHash string) (*RefreshToken, error)
+	// MarkUsed records that the token was exchanged. It returns
+	// ErrRefreshTokenNotFound when the token was already used or revoked,
+	// so only one exchange of a token can succeed.
+	MarkUsed(id string, at time.Time) error
+	RevokeFamily(familyID string, at time.Time) error
+	RevokeUser(userID string, at time.Time) error
+}
+
+// SessionRepository tracks the logins of users. Only sessions that are
+// neither revoked nor expired are found.
+type SessionRepository interface {
+	Create(session *Session) error
+	GetByID(id string) (*Session, error)
+	// GetByUserID lists the user's sessions, most recently seen first.
+	GetByUserID(userID string) ([]*Session, error)
+	// Touch records that the session was used again from ip and userAgent,
+	// and extends it to expiresAt.
+	Touch(id, userAgent, ip string, at, expiresAt time.Time) error
+	Revoke(id string, at time.Time) error
+	RevokeUser(userID string, at time.Time) error
+}
+
+// RevokedTokenRepository lists access tokens, by their jti claim, that were
+// revoked before they expired. Tokens only need listing until they expire.
+type RevokedTokenRepository interface {
+	Revoke(jti, userID string, expiresAt time.Time) error
+	IsRevoked(jti string) (bool, error)
+	PurgeExpired(now time.Time) (int64, error)
+}
+
+// PasswordResetRepository keeps issued password reset tokens by the hash of
+// their value.
+type PasswordResetRepository interface {
+	Create(token *PasswordResetToken) error
+	GetByHash(tokenHash string) (*PasswordResetToken, error)
+	// MarkUsed records that the token reset the password. It returns
+	// ErrPasswordResetTokenNotFound when the token was already used, so a
+	// token resets the password at most once.
+	MarkUsed(id string, at time.Time) error
+	// MarkUserUsed uses up every unused token of the user.
+	MarkUserUsed(userID string, at time.Time) error
+	// CountSince counts the tokens issued to the user since the given time.
+	CountSince(userID string, since time.Time) (int, error)
 }
 
 type TodoRepository interface {
 	Create(todo *Todo) error
 	GetByID(id string) (*Todo, error)
-	GetByUserID(userID string) ([]*Todo, error)
-	GetAll() ([]*Todo, error)
+	GetByUserID(userID string, filter TodoFilter, page PageRequest) ([]*Todo, string, error)
+	GetAll(filter TodoFilter, page PageRequest) ([]*Todo, string, error)
+	GetSharedWith(userID string, filter TodoFilter, page PageRequest) ([]*Todo, string, error)
+	Search(userID, query string, limit int) ([]*SearchResult, error)
 	Update(todo *Todo) error
+	SetPosition(id, position string) error
+	AdjacentPosition(userID, position string, above bool) (string, error)
+	Delete(id string) error
+	GetTrash(userID string, page PageRequest) ([]*Todo, string, error)
+	GetTrashedByID(id string) (*Todo, error)
+	Restore(id string) error
+	GetArchive(userID string, page PageRequest) ([]*Todo, string, error)
+	Unarchive(id string) error
+	ArchiveCompleted(now time.Time) (int64, error)
+	HardDelete(id string) error
+	Purge(deletedBefore time.Time) (int64, error)
+}
+
+type TagRepository interface {
+	Create(tag *Tag) error
+	GetByID(id string) (*Tag, error)
+	GetByName(userID, name string) (*Tag, error)
+	GetByUserID(userID string) ([]*Tag, error)
+	Update(tag *Tag) error
+	Delete(id string) error
+	SetTodoTags(todoID string, tagIDs []string) error
+}
+
+type SubtaskRepository interface {
+	Create(subtask *Subtask) error
+	GetByID(id string) (*Subtask, error)
+	GetByTodoID(todoID string) ([]*Subtask, error)
+	Update(subtask *Subtask) error
+	Delete(id string) error
+	Reorder(todoID string, ids []string) error
+	CompleteAll(todoID string) error
+}
+
+// Repos bundles the repositories whose changes services save together,
+// along with the audit events recording them.
+type Repos struct {
+	Todos          TodoRepository
+	Tags           TagRepository
+	Subtasks       SubtaskRepository
+	Projects       ProjectRepository
+	Shares         ShareRepository
+	Users          UserRepository
+	Dependencies   DependencyRepository
+	Audit          AuditRepository
+	Revisions      RevisionRepository
+	Comments       CommentRepository
+	Attachments    AttachmentRepository
+	PasswordResets PasswordResetRepository
+}
+
+// Transactor runs fn against repositories bound to one transaction, which
+// is committed when fn returns nil and rolled back otherwise.
+type Transactor interface {
+	InTx(fn func(repos Repos) error) error
+}
+
+// DependencyRepository records which todos block which. Adding a dependency
+// that already exists is not an error.
+type DependencyRepository interface {
+	Add(todoID, blockerID string) error
+	Remove(todoID, blockerID string) error
+	GetBlockerIDs(todoID string) ([]string, error)
+}
+
+// AuditRepository appends to the audit log. Recorded events can't be changed
+// or removed.
+type AuditRepository interface {
+	Record(event *AuditEvent) error
+	List(filter AuditFilter, page PageRequest) ([]*AuditEvent, string, error)
+}
+
+// RevisionRepository keeps the history of a todo. Revisions are numbered
+// from 1 per todo and are removed along with the todo.
+type RevisionRepository interface {
+	Create(revision *TodoRevision) error
+	GetByTodoID(todoID string) ([]*TodoRevision, error)
+	GetByNumber(todoID string, number int) (*TodoRevision, error)
+	LatestNumber(todoID string) (int, error)
+}
+
+type CommentRepository interface {
+	Create(comment *Comment) error
+	GetByID(id string) (*Comment, error)
+	GetByTodoID(todoID string) ([]*Comment, error)
+	Update(comment *Comment) error
+	Delete(id string) error
+}
+
+type ShareRepository interface {
+	// Save grants the share, or changes the role of the user's existing
+	// share on the same todo or project, whose ID and CreatedAt are then
+	// copied into share.
+	Save(share *Share) error
+	GetByID(id string) (*Share, error)
+	GetByTodoID(todoID string) ([]*Share, error)
+	GetByProjectID(projectID string) ([]*Share, error)
+	Delete(id string) error
+	// RoleFor returns the strongest role the user holds on the todo, either
+	// directly or through the project, or "" when it isn't shared with them.
+	RoleFor(userID, todoID, projectID string) (ShareRole, error)
+}
+
+type AttachmentRepository interface {
+	Create(attachment *Attachment) error
+	GetByID(id string) (*Attachment, error)
+	GetByTodoID(todoID string) ([]*Attachment, error)
+	Delete(id string) error
+	// PendingBlobDeletions lists storage keys of removed attachments whose
+	// blobs still have to be deleted; ClearBlobDeletion marks one done.
+	PendingBlobDeletions(limit int) ([]string, error)
+	ClearBlobDeletion(key string) error
+}
+
+// BlobStore keeps file contents by key. Deleting a missing blob is not an
+// error; reading one returns ErrBlobNotFound.
+type BlobStore interface {
+	Put(key string, content io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// Mailer sends email. Implementations either deliver it or, in development,
+// only write it somewhere to be read.
+type Mailer interface {
+	Send(email *Email) error
+}
+
+type ProjectRepository interface {
+	Create(project *Project) error
+	GetByID(id string) (*Project, error)
+	GetByUserID(userID string, includeArchived bool) ([]*Project, error)
+	GetSharedWith(userID string) ([]*Project, error)
+	Update(project *Project) error
 	Delete(id string) error
 }
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TodoState is the part of a todo its owner edits, as kept in its history.
+type TodoState struct {
+	ProjectID   string      `json:"project_id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Completed   bool        `json:"completed"`
+	Priority    Priority    `json:"priority"`
+	Due         *Due        `json:"due"`
+	Tags        []string    `json:"tags"`
+	Recurrence  *Recurrence `json:"recurrence"`
+}
+
+// State returns the editable fields of the todo.
+func (t *Todo) State() TodoState {
+	return TodoState{
+		ProjectID:   t.ProjectID,
+		Title:       t.Title,
+		Description: t.Description,
+		Completed:   t.Completed,
+		Priority:    t.Priority,
+		Due:         t.Due,
+		Tags:        append([]string{}, t.Tags...),
+		Recurrence:  t.Recurrence,
+	}
+}
+
+// TodoRevision is a todo as it was after one update by UserID. Changes holds
+// the fields that differ from the previous revision. The first revision is
+// the state the todo had before its first recorded update; it has no UserID
+// and no changes. UserEmail is filled in when revisions are read back.
+type TodoRevision struct {
+	ID        string                 `json:"id"`
+	TodoID    string                 `json:"todo_id"`
+	Revision  int                    `json:"revision"`
+	UserID    string                 `json:"user_id"`
+	UserEmail string                 `json:"user_email,omitempty"`
+	State     TodoState              `json:"state"`
+	Changes   map[string]AuditChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+func NewTodoRevision(todoID string, revision int, userID string, state TodoState) *TodoRevision {
+	return &TodoRevision{
+		ID:        NewID(),
+		TodoID:    todoID,
+		Revision:  revision,
+		UserID:    userID,
+		State:     state,
+		Changes:   map[string]AuditChange{},
+		CreatedAt: time.Now(),
+	}
+}
+
+var stateFieldLabels = map[string]string{
+	"project_id":  "Project",
+	"title":       "Title",
+	"description": "Description",
+	"completed":   "Status",
+	"priority":    "Priority",
+	"due":         "Due",
+	"tags":        "Tags",
+	"recurrence":  "Repeats",
+}
+
+// StateFieldLabel names a TodoState field, given by its JSON name, for display.
+func StateFieldLabel(field string) string {
+	if label, ok := stateFieldLabels[field]; ok {
+		return label
+	}
+	return field
+}
+
+// Describe formats one field of the state, given by its JSON name, for display.
+func (s TodoState) Describe(field string) string {
+	switch field {
+	case "project_id":
+		return orNone(s.ProjectID)
+	case "title":
+		return s.Title
+	case "description":
+		return orNone(s.Description)
+	case "completed":
+		if s.Completed {
+			return "Completed"
+		}
+		return "Open"
+	case "priority":
+		return s.Priority.String()
+	case "due":
+		if s.Due == nil {
+			return "none"
+		}
+		return s.Due.String()
+	case "tags":
+		if len(s.Tags) == 0 {
+			return "none"
+		}
+		return "#" + strings.Join(s.Tags, " #")
+	case "recurrence":
+		if s.Recurrence == nil {
+			return "none"
+		}
+		return s.Recurrence.Describe()
+	}
+	return ""
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// ChangedFields lists the JSON names of the fields the revision changed, in
+// a stable order.
+func (r *TodoRevision) ChangedFields() []string {
+	return slices.Sorted(maps.Keys(r.Changes))
+}
@@ -0,0 +1,11 @@
+package domain
+
+// SearchResult is a todo matched by a full-text search, best matches first.
+// Title and Snippet are HTML-escaped with the matched terms wrapped in
+// <mark> tags; Snippet is an excerpt of the description around the matches.
+type SearchResult struct {
+	Todo    *Todo   `json:"todo"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Session is one login of a user, on one device. It lasts as long as its
+// family of refresh tokens, whose ID it shares. IP and UserAgent are those
+// of the latest request that refreshed it, at LastSeenAt.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Current marks the session the listing was requested with.
+	Current bool `json:"current"`
+}
+
+func NewSession(userID, userAgent, ip string, expiresAt time.Time) *Session {
+	now := time.Now()
+	return &Session{
+		ID:         NewID(),
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ShareRole is the level of access a share grants.
+type ShareRole string
+
+const (
+	// ShareViewer may see the todo and read its subtasks, comments and
+	// attachments.
+	ShareViewer ShareRole = "viewer"
+	// ShareEditor may also change the todo and manage its subtasks,
+	// comments and attachments.
+	ShareEditor ShareRole = "editor"
+)
+
+// ParseShareRole validates a share role name.
+func ParseShareRole(s string) (ShareRole, error) {
+	switch ShareRole(s) {
+	case ShareViewer, ShareEditor:
+		return ShareRole(s), nil
+	}
+	return "", fmt.Errorf("%w: role must be viewer or editor", ErrInvalidShare)
+}
+
+// Access ranks what a user may do with a todo. Higher levels include the
+// lower ones.
+type Access int
+
+const (
+	AccessNone Access = iota
+	AccessView
+	// AccessComplete is held by a todo's assignee, who may see it and mark
+	// it done or reopen it.
+	AccessComplete
+	AccessEdit
+	// AccessOwner is held by the todo's owner and by admins. Only they may
+	// delete, move, restore or share it.
+	AccessOwner
+)
+
+// Access converts a share role into the access it grants.
+func (r ShareRole) Access() Access {
+	switch r {
+	case ShareViewer:
+		return AccessView
+	case ShareEditor:
+		return AccessEdit
+	}
+	return AccessNone
+}
+
+// Share grants a user access to a single todo or, with ProjectID set, to
+// every todo in a project. UserEmail is filled in when shares are read back.
+type Share struct {
+	ID        string    `json:"id"`
+	TodoID    string    `json:"todo_id,omitempty"`
+	ProjectID string    `json:"project_id,omitempty"`
+	UserID    string    `json:"user_id"`
+	UserEmail string    `json:"user_email,omitempty"`
+	Role      ShareRole `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewTodoShare(todoID, userID string, role ShareRole) *Share {
+	return &Share{ID: NewID(), TodoID: todoID, UserID: userID, Role: role, CreatedAt: time.Now()}
+}
+
+func NewProjectShare(projectID, userID string, role ShareRole) *Share {
+	return &Share{ID: NewID(), ProjectID: projectID, UserID: userID, Role: role, CreatedAt: time.Now()}
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+)
+
+type Subtask struct {
+	ID        string    `json:"id"`
+	TodoID    string    `json:"todo_id"`
+	Title     string    `json:"title"`
+	Completed bool      `json:"completed"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewSubtask(todoID, title string, position int) *Subtask {
+	now := time.Now()
+	return &Subtask{
+		ID:        NewID(),
+		TodoID:    todoID,
+		Title:     title,
+		Position:  position,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Progress counts how many of a todo's subtasks are done.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+func (p Progress) String() string {
+	return strconv.Itoa(p.Done) + "/" + strconv.Itoa(p.Total)
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const MaxTagNameLength = 50
+
+type Tag struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewTag(userID, name string) *Tag {
+	return &Tag{
+		ID:        NewID(),
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+}
+
+// NormalizeTagName trims and lowercases a tag name so "Work" and " work " are
+// the same tag.
+func NormalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidTag)
+	}
+	if len(name) > MaxTagNameLength {
+		return "", fmt.Errorf("%w: name must be at most %d characters", ErrInvalidTag, MaxTagNameLength)
+	}
+	if strings.ContainsRune(name, ',') {
+		return "", fmt.Errorf("%w: name must not contain commas", ErrInvalidTag)
+	}
+	return name, nil
+}
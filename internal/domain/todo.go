@@ -1,28 +1,315 @@
 package domain
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
+// Todo is owned by the user who created it, UserID. It may also be assigned
+// to another user, AssigneeID, who is then responsible for getting it done.
+// AssigneeEmail and Blocked are filled in when todos are read back; a todo is
+// blocked while any todo it depends on is still open.
 type Todo struct {
-	ID          string    `json:"id"`
-	UserID      string    `json:"user_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID            string      `json:"id"`
+	UserID        string      `json:"user_id"`
+	AssigneeID    string      `json:"assignee_id,omitempty"`
+	AssigneeEmail string      `json:"assignee_email,omitempty"`
+	ProjectID     string      `json:"project_id,omitempty"`
+	Title         string      `json:"title"`
+	Description   string      `json:"description"`
+	Completed     bool        `json:"completed"`
+	Blocked       bool        `json:"blocked"`
+	Priority      Priority    `json:"priority"`
+	Position      string      `json:"position"`
+	Due           *Due        `json:"due,omitempty"`
+	Tags          []string    `json:"tags"`
+	Progress      Progress    `json:"progress"`
+	Recurrence    *Recurrence `json:"recurrence,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	CompletedAt   *time.Time  `json:"completed_at,omitempty"`
+	ArchivedAt    *time.Time  `json:"archived_at,omitempty"`
+	DeletedAt     *time.Time  `json:"deleted_at,omitempty"`
+
+	// NextOccurrence is the todo generated when this one completed a
+	// recurring series step. It is only set on the result of that update.
+	NextOccurrence *Todo `json:"next_occurrence,omitempty"`
 }
 
 func NewTodo(userID, title, description string) *Todo {
 	now := time.Now()
+	id := NewID()
 	return &Todo{
-		ID:          NewID(),
+		ID:          id,
 		UserID:      userID,
 		Title:       title,
 		Description: description,
 		Completed:   false,
+		Position:    InitialRank(now, id),
+		Tags:        []string{},
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 }
+
+// Recur builds the next occurrence of a recurring todo completed at
+// completedAt. The next due date follows the current one, or the completion
+// day when the todo has no due date, and keeps its time and timezone. It
+// reports false when the todo doesn't recur or its series is over.
+func (t *Todo) Recur(completedAt time.Time) (*Todo, bool) {
+	if t.Recurrence == nil || t.Recurrence.Count == 1 {
+		return nil, false
+	}
+
+	from := completedAt.UTC()
+	var timeOfDay, timezone string
+	if t.Due != nil {
+		if day, err := time.Parse(DueDateLayout, t.Due.Date); err == nil {
+			from = day
+		}
+		timeOfDay, timezone = t.Due.Time, t.Due.Timezone
+	}
+
+	date, ok := t.Recurrence.Next(from)
+	if !ok {
+		return nil, false
+	}
+	due, err := NewDue(date.Format(DueDateLayout), timeOfDay, timezone)
+	if err != nil {
+		return nil, false
+	}
+
+	recurrence := *t.Recurrence
+	if recurrence.Count > 0 {
+		recurrence.Count--
+	}
+
+	next := NewTodo(t.UserID, t.Title, t.Description)
+	next.ProjectID = t.ProjectID
+	next.AssigneeID = t.AssigneeID
+	next.Priority = t.Priority
+	next.Due = due
+	next.Tags = append([]string{}, t.Tags...)
+	next.Recurrence = &recurrence
+	return next, true
+}
+
+// IsOverdue reports whether the todo is still open past its due time.
+func (t *Todo) IsOverdue(now time.Time) bool {
+	return !t.Completed && t.Due != nil && t.Due.At.Before(now)
+}
+
+const (
+	DueDateLayout = "2006-01-02"
+	DueTimeLayout = "15:04"
+)
+
+// Due describes when a todo is due. Date is always set; Time and Timezone are
+// optional. At is the resolved instant used for sorting and filtering: the
+// given time on Date in Timezone, or the end of that day when no time is set.
+type Due struct {
+	Date     string    `json:"date"`
+	Time     string    `json:"time,omitempty"`
+	Timezone string    `json:"timezone,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// NewDue validates the date, time and timezone and resolves the due instant.
+// An empty timezone means UTC.
+func NewDue(date, timeOfDay, timezone string) (*Due, error) {
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidDueDate, timezone)
+		}
+	}
+
+	day, err := time.ParseInLocation(DueDateLayout, date, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidDueDate)
+	}
+
+	at := day.Add(24*time.Hour - time.Second)
+	if timeOfDay != "" {
+		clock, err := time.Parse(DueTimeLayout, timeOfDay)
+		if err != nil {
+			return nil, fmt.Errorf("%w: time must be HH:MM", ErrInvalidDueDate)
+		}
+		at = time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	}
+
+	return &Due{
+		Date:     date,
+		Time:     timeOfDay,
+		Timezone: timezone,
+		At:       at.UTC(),
+	}, nil
+}
+
+// String formats the due date for display, e.g. "Oct 20, 2026 14:30 (Europe/Berlin)".
+func (d *Due) String() string {
+	day, err := time.Parse(DueDateLayout, d.Date)
+	if err != nil {
+		return d.Date
+	}
+
+	s := day.Format("Jan 2, 2006")
+	if d.Time != "" {
+		s += " " + d.Time
+	}
+	if d.Timezone != "" {
+		s += " (" + d.Timezone + ")"
+	}
+	return s
+}
+
+// Priority ranks how urgent a todo is. Higher values sort first.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// Priorities lists every priority from lowest to highest.
+func Priorities() []Priority {
+	return []Priority{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+}
+
+// ParsePriority converts a priority name into a Priority. An empty string is PriorityNone.
+func ParsePriority(s string) (Priority, error) {
+	if s == "" {
+		return PriorityNone, nil
+	}
+	for i, name := range priorityNames {
+		if name == s {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("%w: must be one of none, low, medium, high, urgent", ErrInvalidPriority)
+}
+
+func (p Priority) String() string {
+	if p < PriorityNone || p > PriorityUrgent {
+		return priorityNames[PriorityNone]
+	}
+	return priorityNames[p]
+}
+
+// MarshalText encodes the priority by name so it reads naturally in JSON.
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Priority) UnmarshalText(text []byte) error {
+	parsed, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// TodoSort selects the ordering of a todo listing.
+type TodoSort string
+
+const (
+	// SortManual follows the order users arrange by hand. New todos start
+	// at the top, so until something is moved it matches SortCreated. It is
+	// the default.
+	SortManual TodoSort = "manual"
+	// SortCreated lists the newest todos first.
+	SortCreated TodoSort = "created"
+	// SortPriority lists the most urgent todos first, then by due date, then newest.
+	SortPriority TodoSort = "priority"
+	// SortDue lists the soonest due todos first; undated todos come last.
+	SortDue TodoSort = "due"
+)
+
+// ParseTodoSort validates a sort name. An empty string is SortManual.
+func ParseTodoSort(s string) (TodoSort, error) {
+	switch TodoSort(s) {
+	case "", SortManual:
+		return SortManual, nil
+	case SortCreated, SortPriority, SortDue:
+		return TodoSort(s), nil
+	}
+	return SortManual, fmt.Errorf("sort must be one of: manual, created, priority, due")
+}
+
+// TodoSortField is one key of a custom todo ordering.
+type TodoSortField struct {
+	Field string
+	Desc  bool
+}
+
+var todoSortFieldNames = []string{"created_at", "updated_at", "title", "priority", "due_at", "completed"}
+
+// ParseTodoSortFields parses a comma separated list of fields to order by,
+// each prefixed with "-" for descending order, e.g. "-updated_at,title".
+func ParseTodoSortFields(s string) ([]TodoSortField, error) {
+	var fields []TodoSortField
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		field := TodoSortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !slices.Contains(todoSortFieldNames, field.Field) {
+			return nil, fmt.Errorf("unknown sort field %q; use %s", field.Field, strings.Join(todoSortFieldNames, ", "))
+		}
+		if seen[field.Field] {
+			return nil, fmt.Errorf("sort field %q given twice", field.Field)
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func (f TodoSortField) String() string {
+	if f.Desc {
+		return "-" + f.Field
+	}
+	return f.Field
+}
+
+// TodoFilter narrows a todo listing. The zero value matches every todo.
+// ProjectID limits the listing to one project and UserID to one owner, which
+// only admins may pick. AssigneeID limits it to the todos assigned to one
+// user; users may always list the todos assigned to themselves. Tags matches
+// todos carrying any of the named tags, or all of them when MatchAllTags is
+// set. TitleContains matches a substring of the title regardless of case.
+// Archived todos are left out unless IncludeArchived is set. Shared lists the
+// todos other users shared with the requesting user instead of their own.
+// SortFields, when given, replaces Sort.
+type TodoFilter struct {
+	ProjectID       string
+	UserID          string
+	AssigneeID      string
+	Completed       *bool
+	TitleContains   string
+	Overdue         bool
+	DueOn           string
+	DueBefore       *time.Time
+	DueAfter        *time.Time
+	CreatedBefore   *time.Time
+	CreatedAfter    *time.Time
+	UpdatedBefore   *time.Time
+	UpdatedAfter    *time.Time
+	Tags            []string
+	MatchAllTags    bool
+	IncludeArchived bool
+	Shared          bool
+	Sort            TodoSort
+	SortFields      []TodoSortField
+}
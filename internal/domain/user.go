@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,12 @@ type User struct {
 	PasswordHash string    `json:"-"` // - means never serialize password hash
 	Role         string    `json:"role"`
 	CreatedAt    time.Time `json:"created_at"`
+	// AutoArchiveDays archives completed todos this many days after they
+	// were completed. Zero turns auto-archiving off.
+	AutoArchiveDays int `json:"auto_archive_days"`
+	// TokenVersion is stamped into access tokens; bumping it revokes all
+	// tokens issued before.
+	TokenVersion int `json:"-"`
 }
 
 const (
@@ -20,6 +27,18 @@ const (
 	RoleAdmin = "admin"
 )
 
+// MaxAutoArchiveDays caps the auto-archive delay at a year.
+const MaxAutoArchiveDays = 365
+
+// ValidateAutoArchiveDays checks an auto-archive delay; zero is allowed and
+// turns auto-archiving off.
+func ValidateAutoArchiveDays(days int) error {
+	if days < 0 || days > MaxAutoArchiveDays {
+		return fmt.Errorf("%w: auto_archive_days must be between 0 and %d", ErrInvalidSetting, MaxAutoArchiveDays)
+	}
+	return nil
+}
+
 func NewID() string {
 	return uuid.NewString()
 }
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// multipartOverhead leaves room for the multipart framing around the file
+// when capping the request body.
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentService *service.AttachmentService
+	logger            *slog.Logger
+}
+
+func NewAttachmentHandler(attachmentService *service.AttachmentService, logger *slog.Logger) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		logger:            logger,
+	}
+}
+
+type AttachmentResponse struct {
+	Attachment domain.Attachment `json:"attachment"`
+}
+
+type AttachmentsResponse struct {
+	Attachments []*domain.Attachment `json:"attachments"`
+}
+
+func (h *AttachmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	attachments, err := h.attachmentService.List(todoID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to list attachments", todoID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, AttachmentsResponse{Attachments: attachments}, h.logger)
+}
+
+// Upload handles POST /api/todos/{id}/attachments with the file in the
+// "file" field of a multipart form.
+func (h *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.attachmentService.MaxSize()+multipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Attachment too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.logger.Warn("Invalid upload", "error", err)
+		http.Error(w, "A file is required in the \"file\" field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	defer r.MultipartForm.RemoveAll()
+
+	attachment, err := h.attachmentService.Upload(r.Context(), todoID, claims.UserID, claims.Role, header.Filename, header.Size, file)
+	if err != nil {
+		h.writeError(w, err, "Failed to upload attachment", todoID)
+		return
+	}
+
+	h.logger.Info("Attachment uploaded", "attachment_id", attachment.ID, "todo_id", todoID, "user_id", claims.UserID, "size", attachment.Size)
+
+	writeJsonResponse(w, http.StatusCreated, AttachmentResponse{Attachment: *attachment}, h.logger)
+}
+
+// Download streams an attachment back as a file download.
+func (h *AttachmentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	attachmentID := chi.URLParam(r, "attachmentID")
+
+	attachment, content, err := h.attachmentService.Open(todoID, attachmentID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to open attachment", todoID)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, content); err != nil {
+		h.logger.Warn("Failed to stream attachment", "error", err, "attachment_id", attachmentID)
+	}
+}
+
+func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	attachmentID := chi.URLParam(r, "attachmentID")
+
+	if err := h.attachmentService.Delete(r.Context(), todoID, attachmentID, claims.UserID, claims.Role); err != nil {
+		h.writeError(w, err, "Failed to delete attachment", todoID)
+		return
+	}
+
+	h.logger.Info("Attachment deleted", "attachment_id", attachmentID, "todo_id", todoID, "user_id", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AttachmentHandler) writeError(w http.ResponseWriter, err error, msg, todoID string) {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound):
+		http.Error(w, "Todo not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrAttachmentNotFound), errors.Is(err, domain.ErrBlobNotFound):
+		http.Error(w, "Attachment not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, domain.ErrAttachmentTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, domain.ErrInvalidAttachment):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error(msg, "error", err, "todo_id", todoID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
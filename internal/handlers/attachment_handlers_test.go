@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"godo/internal/auth"
+	"godo/internal/blob"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// A minimal PNG signature is enough for content sniffing
+var testPNG = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+
+func setupAttachmentTestHandler(t *testing.T, maxSize int64) (http.Handler, *store.UserRepo, *store.TodoRepo, *service.AttachmentService) {
+	t.Helper()
+
+	db := testutil.SetupTestDB(t)
+	userRepo := store.NewUserRepo(db)
+	todoRepo := store.NewTodoRepo(db)
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create blob store: %v", err)
+	}
+	attachmentService := service.NewAttachmentService(store.NewAttachmentRepo(db), blobs, store.NewAuditRepo(db), newTestTodoService(db), maxSize, store.NewTransactor(db))
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := NewAttachmentHandler(attachmentService, logger)
+
+	r := chi.NewRouter()
+	r.Route("/api/todos/{id}/attachments", func(r chi.Router) {
+		r.Get("/", handler.List)
+		r.Post("/", handler.Upload)
+		r.Get("/{attachmentID}", handler.Download)
+		r.Delete("/{attachmentID}", handler.Delete)
+	})
+
+	return r, userRepo, todoRepo, attachmentService
+}
+
+func uploadRequest(t *testing.T, todoID, filename string, content []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", filename)
+	part.Write(content)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/todos/"+todoID+"/attachments", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestAttachment_UploadDownloadDelete(t *testing.T) {
+	router, userRepo, todoRepo, attachmentService := setupAttachmentTestHandler(t, 1<<20)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	rec := serveWithClaims(router, uploadRequest(t, todo.ID, `C:\Users\me\shot.png`, testPNG), claims)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var created AttachmentResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.Attachment.Filename != "shot.png" || created.Attachment.ContentType != "image/png" {
+		t.Errorf("Expected shot.png as image/png, got %s as %s", created.Attachment.Filename, created.Attachment.ContentType)
+	}
+
+	attachmentURL := "/api/todos/" + todo.ID + "/attachments/" + created.Attachment.ID
+	rec = serveWithClaims(router, httptest.NewRequest(http.MethodGet, attachmentURL, nil), claims)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), testPNG) {
+		t.Error("Expected the uploaded bytes back")
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename=shot.png` {
+		t.Errorf("Expected a download named shot.png, got %q", got)
+	}
+
+	rec = serveWithClaims(router, httptest.NewRequest(http.MethodDelete, attachmentURL, nil), claims)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+	rec = serveWithClaims(router, httptest.NewRequest(http.MethodGet, attachmentURL, nil), claims)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d after delete, got %d", http.StatusNotFound, rec.Code)
+	}
+	if cleaned, _ := attachmentService.CleanupBlobs(); cleaned != 0 {
+		t.Errorf("Expected the blob to be deleted with the attachment, got %d left to clean up", cleaned)
+	}
+}
+
+func TestAttachment_UploadLimits(t *testing.T) {
+	router, userRepo, todoRepo, _ := setupAttachmentTestHandler(t, 128)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	tests := []struct {
+		name     string
+		content  []byte
+		expected int
+	}{
+		{"too large", append(testPNG, bytes.Repeat([]byte{0}, 128)...), http.StatusRequestEntityTooLarge},
+		{"disallowed type", []byte("<html><script>alert(1)</script></html>"), http.StatusBadRequest},
+		{"empty", nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		rec := serveWithClaims(router, uploadRequest(t, todo.ID, "file.png", tt.content), claims)
+		if rec.Code != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, rec.Code)
+		}
+	}
+}
+
+func TestAttachment_Forbidden(t *testing.T) {
+	router, userRepo, todoRepo, _ := setupAttachmentTestHandler(t, 1<<20)
+
+	owner := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, owner.ID)
+	ownerClaims := &auth.Claims{UserID: owner.ID, Email: owner.Email, Role: domain.RoleUser}
+	otherClaims := &auth.Claims{UserID: other.ID, Email: other.Email, Role: domain.RoleUser}
+
+	if rec := serveWithClaims(router, uploadRequest(t, todo.ID, "shot.png", testPNG), otherClaims); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d uploading to another user's todo, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	rec := serveWithClaims(router, uploadRequest(t, todo.ID, "shot.png", testPNG), ownerClaims)
+	var created AttachmentResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/todos/"+todo.ID+"/attachments/"+created.Attachment.ID, nil)
+	if rec := serveWithClaims(router, req, otherClaims); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d downloading another user's attachment, got %d", http.StatusForbidden, rec.Code)
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+	logger       *slog.Logger
+}
+
+func NewAuditHandler(auditService *service.AuditService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+type AuditEventsResponse struct {
+	Events     []*domain.AuditEvent `json:"events"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// List handles GET /api/admin/audit, newest events first.
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := parsePageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, next, err := h.auditService.List(claims.Role, filter, page)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to list audit events", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, AuditEventsResponse{Events: events, NextCursor: next}, h.logger)
+}
+
+// parseAuditFilter reads the audit filters from the query string:
+//
+//	actor_id            events caused by one user
+//	action              e.g. create, update, delete
+//	target_type         e.g. todo, project, user
+//	target_id           events about one record
+//	since, until        RFC 3339 timestamps or YYYY-MM-DD dates (UTC)
+func parseAuditFilter(r *http.Request) (domain.AuditFilter, error) {
+	q := r.URL.Query()
+	filter := domain.AuditFilter{
+		ActorID:    q.Get("actor_id"),
+		Action:     q.Get("action"),
+		TargetType: q.Get("target_type"),
+		TargetID:   q.Get("target_id"),
+	}
+
+	bounds := []struct {
+		param string
+		dest  **time.Time
+	}{
+		{"since", &filter.Since},
+		{"until", &filter.Until},
+	}
+	for _, bound := range bounds {
+		if v := q.Get(bound.param); v != "" {
+			t, err := parseTimeParam(v, time.UTC)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: %w", bound.param, err)
+			}
+			*bound.dest = &t
+		}
+	}
+
+	return filter, nil
+}
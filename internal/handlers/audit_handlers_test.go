@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestAuditList(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	userRepo := store.NewUserRepo(db)
+	auditRepo := store.NewAuditRepo(db)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	userHandler := NewUserHandler(service.NewUserService(userRepo, auditRepo, store.NewTransactor(db)), logger)
+	auditHandler := NewAuditHandler(service.NewAuditService(auditRepo), logger)
+
+	admin := createTestUser(t, userRepo, domain.RoleAdmin)
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	adminClaims := &auth.Claims{UserID: admin.ID, Email: admin.Email, Role: domain.RoleAdmin}
+	userClaims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	// An admin promotes the user from a known request
+	reqBody, _ := json.Marshal(UpdateUserRequest{Role: &adminClaims.Role})
+	req := httptest.NewRequest(http.MethodPatch, "/api/users/"+user.ID, bytes.NewBuffer(reqBody))
+	req = requestWithClaimsAndID(req, adminClaims, "id", user.ID)
+	req = req.WithContext(service.WithRequestInfo(req.Context(), service.RequestInfo{RequestID: "req-42", IP: "203.0.113.7"}))
+	rec := httptest.NewRecorder()
+	userHandler.Update(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	list := func(query string, claims *auth.Claims) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/audit"+query, nil)
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+		auditHandler.List(rec, req)
+		return rec
+	}
+
+	rec = list("?target_type=user&actor_id="+admin.ID, adminClaims)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	var resp AuditEventsResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(resp.Events))
+	}
+	event := resp.Events[0]
+	if event.TargetID != user.ID || event.RequestID != "req-42" || event.IP != "203.0.113.7" {
+		t.Errorf("Expected the role change from req-42, got %+v", event)
+	}
+	if change := event.Changes["role"]; change.Before != domain.RoleUser || change.After != domain.RoleAdmin {
+		t.Errorf("Expected the role change in the diff, got %+v", event.Changes)
+	}
+
+	if rec := list("?action=delete", adminClaims); rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	} else {
+		var resp AuditEventsResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if len(resp.Events) != 0 {
+			t.Errorf("Expected no delete events, got %d", len(resp.Events))
+		}
+	}
+
+	if rec := list("", userClaims); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for a non-admin, got %d", http.StatusForbidden, rec.Code)
+	}
+	if rec := list("?since=yesterday", adminClaims); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a bad since, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
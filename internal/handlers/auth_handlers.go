@@ -14,14 +14,12 @@ import (
 type AuthHandler struct {
 	authService *service.AuthService
 	logger      *slog.Logger
-	jwtSecret   string
 }
 
-func NewAuthHandler(authService *service.AuthService, logger *slog.Logger, jwtSecret string) *AuthHandler {
+func NewAuthHandler(authService *service.AuthService, logger *slog.Logger) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
 		logger:      logger,
-		jwtSecret:   jwtSecret,
 	}
 }
 
@@ -35,9 +33,37 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutRequest optionally names the refresh token of the session, so it
+// stops working along with the access token.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// TokenResponse carries a short-lived access token, sent as a bearer token,
+// and the refresh token that trades it for a new pair at /api/token/refresh.
+type TokenResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
 type AuthResponse struct {
-	Token string      `json:"token"`
-	User  domain.User `json:"user"`
+	TokenResponse
+	User domain.User `json:"user"`
+}
+
+func newTokenResponse(session *auth.Session) TokenResponse {
+	return TokenResponse{
+		Token:            session.AccessToken,
+		ExpiresAt:        session.AccessExpiresAt,
+		RefreshToken:     session.RefreshToken,
+		RefreshExpiresAt: session.RefreshExpiresAt,
+	}
 }
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
@@ -48,7 +74,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.authService.Register(req.Email, req.Password)
+	user, err := h.authService.Register(r.Context(), req.Email, req.Password)
 	if err != nil {
 		switch err {
 		case service.ErrInvalidInput, service.ErrPasswordTooShort:
@@ -66,16 +92,16 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.Info("User registered", "user_id", user.ID)
 
-	token, err := auth.GenerateToken(user.ID, user.Email, user.Role, h.jwtSecret, 24*time.Hour)
+	session, err := h.authService.StartSession(r.Context(), user)
 	if err != nil {
-		h.logger.Error("Failed to generate token", "error", err)
+		h.logger.Error("Failed to start session", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	resp := AuthResponse{
-		Token: token,
-		User:  *user,
+		TokenResponse: newTokenResponse(session),
+		User:          *user,
 	}
 
 	writeJsonResponse(w, http.StatusCreated, resp, h.logger)
@@ -106,9 +132,9 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Email, user.Role, h.jwtSecret, 24*time.Hour)
+	session, err := h.authService.StartSession(r.Context(), user)
 	if err != nil {
-		h.logger.Error("Failed to generate token", "error", err)
+		h.logger.Error("Failed to start session", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
@@ -116,9 +142,91 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("User logged in", "user_id", user.ID)
 
 	resp := AuthResponse{
-		Token: token,
-		User:  *user,
+		TokenResponse: newTokenResponse(session),
+		User:          *user,
 	}
 
 	writeJsonResponse(w, http.StatusOK, resp, h.logger)
 }
+
+// Refresh trades a refresh token for a new access and refresh token. The old
+// refresh token stops working.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.RefreshToken == "" {
+		http.Error(w, "Refresh token is required", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			h.logger.Warn("Refresh token reused, revoked its session")
+			http.Error(w, "Invalid or expired refresh token", http.StatusUnauthorized)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			http.Error(w, "Invalid or expired refresh token", http.StatusUnauthorized)
+			return
+		}
+		h.logger.Error("Failed to refresh token", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, newTokenResponse(session), h.logger)
+}
+
+// Logout revokes the access token the request was made with, and the
+// session's refresh token when it is given in the body.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req LogoutRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.logger.Warn("Invalid request body", "error", err)
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.authService.Logout(r.Context(), claims, req.RefreshToken); err != nil {
+		h.logger.Error("Failed to log out", "error", err, "user_id", claims.UserID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("User logged out", "user_id", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutEverywhere revokes every token issued to the user, on all devices.
+func (h *AuthHandler) LogoutEverywhere(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authService.LogoutEverywhere(r.Context(), claims.UserID); err != nil {
+		h.logger.Error("Failed to log out everywhere", "error", err, "user_id", claims.UserID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("User logged out everywhere", "user_id", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,7 +2,11 @@ package handlers
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"godo/internal/auth"
 	"godo/internal/domain"
 	"godo/internal/service"
 	"godo/internal/store"
@@ -12,6 +16,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 )
 
 func setupAuthTestHandler(t *testing.T) (*AuthHandler, *store.UserRepo) {
@@ -19,15 +24,25 @@ func setupAuthTestHandler(t *testing.T) (*AuthHandler, *store.UserRepo) {
 
 	db := testutil.SetupTestDB(t)
 	userRepo := store.NewUserRepo(db)
-	authService := service.NewAuthService(userRepo)
+	authService := newTestAuthService(db)
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
-	handler := NewAuthHandler(authService, logger, "test-jwt-secret")
+	handler := NewAuthHandler(authService, logger)
 
 	return handler, userRepo
 }
 
+func newTestAuthService(db *sql.DB) *service.AuthService {
+	userRepo := store.NewUserRepo(db)
+	revocations := service.NewTokenRevocations(store.NewRevokedTokenRepo(db), userRepo)
+	return service.NewAuthService(userRepo, store.NewSessionRepo(db), store.NewRefreshTokenRepo(db), revocations, store.NewAuditRepo(db), service.TokenConfig{
+		Secret:     "test-jwt-secret",
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 24 * time.Hour,
+	})
+}
+
 func TestRegister_Success(t *testing.T) {
 	handler, _ := setupAuthTestHandler(t)
 
@@ -177,6 +192,10 @@ func TestLogin_Success(t *testing.T) {
 		t.Error("Expected token, got empty string")
 	}
 
+	if resp.RefreshToken == "" || !resp.ExpiresAt.Before(resp.RefreshExpiresAt) {
+		t.Error("Expected a refresh token outliving the access token")
+	}
+
 	if resp.User.Email != user.Email {
 		t.Errorf("Expected email %s, got %s", user.Email, resp.User.Email)
 	}
@@ -268,3 +287,73 @@ func TestLogin_Failures(t *testing.T) {
 		})
 	}
 }
+
+func TestRefresh(t *testing.T) {
+	handler, _ := setupAuthTestHandler(t)
+
+	body, _ := json.Marshal(RegisterRequest{Email: "refresh@example.com", Password: "password123"})
+	rec := httptest.NewRecorder()
+	handler.Register(rec, httptest.NewRequest(http.MethodPost, "/api/register", bytes.NewBuffer(body)))
+	var registered AuthResponse
+	json.NewDecoder(rec.Body).Decode(&registered)
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(RefreshRequest{RefreshToken: token})
+		rec := httptest.NewRecorder()
+		handler.Refresh(rec, httptest.NewRequest(http.MethodPost, "/api/token/refresh", bytes.NewBuffer(body)))
+		return rec
+	}
+
+	rec = refresh(registered.RefreshToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp TokenResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Token == "" || resp.RefreshToken == "" || resp.RefreshToken == registered.RefreshToken {
+		t.Errorf("Expected a new token pair, got %+v", resp)
+	}
+
+	if rec := refresh(registered.RefreshToken); rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d for a parallel refresh with the same token, got %d", http.StatusOK, rec.Code)
+	}
+	if rec := refresh("not-a-token"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for an unknown token, got %d", http.StatusUnauthorized, rec.Code)
+	}
+	if rec := refresh(""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without a token, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	authService := newTestAuthService(db)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := NewAuthHandler(authService, logger)
+	revocations := service.NewTokenRevocations(store.NewRevokedTokenRepo(db), store.NewUserRepo(db))
+
+	user, _ := authService.Register(context.Background(), "logout@example.com", "password123")
+	session, _ := authService.StartSession(context.Background(), user)
+	claims, _ := auth.ValidateToken(session.AccessToken, "test-jwt-secret")
+
+	body, _ := json.Marshal(LogoutRequest{RefreshToken: session.RefreshToken})
+	req := requestWithClaims(httptest.NewRequest(http.MethodPost, "/api/logout", bytes.NewBuffer(body)), claims)
+	rec := httptest.NewRecorder()
+	handler.Logout(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+
+	protected := auth.Middleware("test-jwt-secret", revocations)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req = httptest.NewRequest(http.MethodGet, "/api/todos", nil)
+	req.Header.Set("Authorization", "Bearer "+session.AccessToken)
+	rec = httptest.NewRecorder()
+	protected.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for a logged out token, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	if _, err := authService.Refresh(context.Background(), session.RefreshToken); !errors.Is(err, service.ErrInvalidRefreshToken) {
+		t.Errorf("Expected the refresh token to be revoked, got %v", err)
+	}
+}
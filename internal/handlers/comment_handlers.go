@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CommentHandler struct {
+	commentService *service.CommentService
+	logger         *slog.Logger
+}
+
+func NewCommentHandler(commentService *service.CommentService, logger *slog.Logger) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		logger:         logger,
+	}
+}
+
+type CommentRequest struct {
+	Body string `json:"body"`
+}
+
+type CommentResponse struct {
+	Comment domain.Comment `json:"comment"`
+}
+
+type CommentsResponse struct {
+	Comments []*domain.Comment `json:"comments"`
+}
+
+func (h *CommentHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	comments, err := h.commentService.List(todoID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to list comments", todoID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, CommentsResponse{Comments: comments}, h.logger)
+}
+
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.commentService.Create(r.Context(), todoID, claims.UserID, claims.Role, req.Body)
+	if err != nil {
+		h.writeError(w, err, "Failed to create comment", todoID)
+		return
+	}
+
+	h.logger.Info("Comment created", "comment_id", comment.ID, "todo_id", todoID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusCreated, CommentResponse{Comment: *comment}, h.logger)
+}
+
+func (h *CommentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	commentID := chi.URLParam(r, "commentID")
+
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.commentService.Update(r.Context(), todoID, commentID, claims.UserID, claims.Role, req.Body)
+	if err != nil {
+		h.writeError(w, err, "Failed to update comment", todoID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, CommentResponse{Comment: *comment}, h.logger)
+}
+
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	commentID := chi.URLParam(r, "commentID")
+
+	if err := h.commentService.Delete(r.Context(), todoID, commentID, claims.UserID, claims.Role); err != nil {
+		h.writeError(w, err, "Failed to delete comment", todoID)
+		return
+	}
+
+	h.logger.Info("Comment deleted", "comment_id", commentID, "todo_id", todoID, "user_id", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CommentHandler) writeError(w http.ResponseWriter, err error, msg, todoID string) {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound):
+		http.Error(w, "Todo not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrCommentNotFound):
+		http.Error(w, "Comment not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, domain.ErrInvalidComment):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error(msg, "error", err, "todo_id", todoID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
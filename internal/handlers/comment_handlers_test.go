@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func setupCommentTestHandler(t *testing.T) (http.Handler, *store.UserRepo, *store.TodoRepo) {
+	t.Helper()
+
+	db := testutil.SetupTestDB(t)
+	userRepo := store.NewUserRepo(db)
+	todoRepo := store.NewTodoRepo(db)
+	commentService := service.NewCommentService(store.NewCommentRepo(db), store.NewAuditRepo(db), newTestTodoService(db), store.NewTransactor(db))
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := NewCommentHandler(commentService, logger)
+
+	r := chi.NewRouter()
+	r.Route("/api/todos/{id}/comments", func(r chi.Router) {
+		r.Get("/", handler.List)
+		r.Post("/", handler.Create)
+		r.Patch("/{commentID}", handler.Update)
+		r.Delete("/{commentID}", handler.Delete)
+	})
+
+	return r, userRepo, todoRepo
+}
+
+func postComment(t *testing.T, router http.Handler, todoID, body string, claims *auth.Claims) *httptest.ResponseRecorder {
+	t.Helper()
+	reqBody, _ := json.Marshal(CommentRequest{Body: body})
+	req := httptest.NewRequest(http.MethodPost, "/api/todos/"+todoID+"/comments", bytes.NewBuffer(reqBody))
+	return serveWithClaims(router, req, claims)
+}
+
+func TestCommentCreate_AndList(t *testing.T) {
+	router, userRepo, todoRepo := setupCommentTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	rec := postComment(t, router, todo.ID, "  Waiting on the supplier  ", claims)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var created CommentResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.Comment.Body != "Waiting on the supplier" || created.Comment.AuthorEmail != user.Email {
+		t.Errorf("Expected trimmed body by %s, got %q by %q", user.Email, created.Comment.Body, created.Comment.AuthorEmail)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/todos/"+todo.ID+"/comments", nil)
+	rec = serveWithClaims(router, req, claims)
+
+	var list CommentsResponse
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Comments) != 1 || list.Comments[0].ID != created.Comment.ID {
+		t.Errorf("Expected the new comment in the thread, got %d comments", len(list.Comments))
+	}
+}
+
+func TestCommentCreate_Invalid(t *testing.T) {
+	router, userRepo, todoRepo := setupCommentTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	for _, body := range []string{"   ", strings.Repeat("x", domain.MaxCommentLength+1)} {
+		if rec := postComment(t, router, todo.ID, body, claims); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for a %d character body, got %d", http.StatusBadRequest, len(body), rec.Code)
+		}
+	}
+}
+
+// Only the todo's owner and admins may take part in its thread
+func TestComment_Permissions(t *testing.T) {
+	router, userRepo, todoRepo := setupCommentTestHandler(t)
+
+	owner := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	admin := createTestUser(t, userRepo, domain.RoleAdmin)
+	todo := createTestTodo(t, todoRepo, owner.ID)
+	ownerClaims := &auth.Claims{UserID: owner.ID, Email: owner.Email, Role: domain.RoleUser}
+	otherClaims := &auth.Claims{UserID: other.ID, Email: other.Email, Role: domain.RoleUser}
+	adminClaims := &auth.Claims{UserID: admin.ID, Email: admin.Email, Role: domain.RoleAdmin}
+
+	if rec := postComment(t, router, todo.ID, "Let me in", otherClaims); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for another user, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	var created CommentResponse
+	rec := postComment(t, router, todo.ID, "Owner's note", ownerClaims)
+	json.NewDecoder(rec.Body).Decode(&created)
+	commentURL := "/api/todos/" + todo.ID + "/comments/" + created.Comment.ID
+
+	// Admins may remove comments but not put words in the author's mouth
+	reqBody, _ := json.Marshal(CommentRequest{Body: "Rewritten"})
+	req := httptest.NewRequest(http.MethodPatch, commentURL, bytes.NewBuffer(reqBody))
+	if rec := serveWithClaims(router, req, adminClaims); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d editing as admin, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, commentURL, bytes.NewBuffer(reqBody))
+	if rec := serveWithClaims(router, req, ownerClaims); rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d editing as author, got %d", http.StatusOK, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, commentURL, nil)
+	if rec := serveWithClaims(router, req, adminClaims); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d deleting as admin, got %d", http.StatusNoContent, rec.Code)
+	}
+
+	// A comment asked for under another todo doesn't exist there
+	otherTodo := createTestTodo(t, todoRepo, owner.ID)
+	rec = postComment(t, router, todo.ID, "Second", ownerClaims)
+	json.NewDecoder(rec.Body).Decode(&created)
+	req = httptest.NewRequest(http.MethodDelete, "/api/todos/"+otherTodo.ID+"/comments/"+created.Comment.ID, nil)
+	if rec := serveWithClaims(router, req, ownerClaims); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d under the wrong todo, got %d", http.StatusNotFound, rec.Code)
+	}
+}
@@ -2,8 +2,16 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"godo/internal/domain"
 	"log/slog"
 	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
 )
 
 func writeJsonResponse(w http.ResponseWriter, statusCode int, data any, logger *slog.Logger) {
@@ -14,3 +22,19 @@ func writeJsonResponse(w http.ResponseWriter, statusCode int, data any, logger *
 		logger.Error("Error encoding response", "error", err)
 	}
 }
+
+// parsePageRequest reads the limit and cursor query parameters of a paged
+// listing.
+func parsePageRequest(r *http.Request) (domain.PageRequest, error) {
+	page := domain.PageRequest{Limit: defaultPageLimit, Cursor: r.URL.Query().Get("cursor")}
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return page, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		page.Limit = limit
+	}
+
+	return page, nil
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"godo/internal/service"
+	"log/slog"
+	"net/http"
+)
+
+type PasswordResetHandler struct {
+	passwordResetService *service.PasswordResetService
+	logger               *slog.Logger
+}
+
+func NewPasswordResetHandler(passwordResetService *service.PasswordResetService, logger *slog.Logger) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		passwordResetService: passwordResetService,
+		logger:               logger,
+	}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Forgot handles POST /api/password/forgot. It answers 202 Accepted whether
+// or not an account uses the email.
+func (h *PasswordResetHandler) Forgot(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.passwordResetService.RequestReset(r.Context(), req.Email); err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			http.Error(w, "Email is required", http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to request password reset", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Reset handles POST /api/password/reset.
+func (h *PasswordResetHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidResetToken), errors.Is(err, service.ErrPasswordTooShort):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			h.logger.Error("Failed to reset password", "error", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.logger.Info("Password reset")
+
+	w.WriteHeader(http.StatusNoContent)
+}
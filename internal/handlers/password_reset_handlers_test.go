@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingMailer hands over the email it is asked to send. Reset emails
+// are sent in the background, so tests wait for them.
+type recordingMailer struct {
+	sent chan *domain.Email
+}
+
+func newRecordingMailer() *recordingMailer {
+	return &recordingMailer{sent: make(chan *domain.Email, 10)}
+}
+
+func (m *recordingMailer) Send(email *domain.Email) error {
+	m.sent <- email
+	return nil
+}
+
+// nextResetToken waits for the next email and pulls the token out of its
+// link.
+func (m *recordingMailer) nextResetToken(t *testing.T) string {
+	t.Helper()
+
+	var body string
+	select {
+	case email := <-m.sent:
+		body = email.Body
+	case <-time.After(time.Second):
+		t.Fatal("Expected a reset email")
+	}
+	start := strings.Index(body, "http://localhost/reset-password?")
+	if start < 0 {
+		t.Fatalf("Expected a reset link in the email, got %q", body)
+	}
+	link, _ := url.Parse(strings.Fields(body[start:])[0])
+	return link.Query().Get("token")
+}
+
+func newTestPasswordResetService(db *sql.DB, authService *service.AuthService, mailer domain.Mailer) *service.PasswordResetService {
+	return service.NewPasswordResetService(store.NewUserRepo(db), store.NewPasswordResetRepo(db), mailer, authService, store.NewAuditRepo(db), service.PasswordResetConfig{
+		TTL:     time.Hour,
+		BaseURL: "http://localhost",
+	}, slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})), store.NewTransactor(db))
+}
+
+func TestPasswordReset_ForgotAndReset(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	authService := newTestAuthService(db)
+	mailer := newRecordingMailer()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := NewPasswordResetHandler(newTestPasswordResetService(db, authService, mailer), logger)
+
+	authService.Register(context.Background(), "reset@example.com", "old-password")
+
+	for _, email := range []string{"reset@example.com", "unknown@example.com"} {
+		body, _ := json.Marshal(ForgotPasswordRequest{Email: email})
+		rec := httptest.NewRecorder()
+		handler.Forgot(rec, httptest.NewRequest(http.MethodPost, "/api/password/forgot", bytes.NewBuffer(body)))
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusAccepted, email, rec.Code)
+		}
+	}
+	token := mailer.nextResetToken(t)
+	if len(mailer.sent) != 0 {
+		t.Fatalf("Expected only the known address to get an email, got %d more", len(mailer.sent))
+	}
+
+	body, _ := json.Marshal(ResetPasswordRequest{Token: token, Password: "new-password"})
+	rec := httptest.NewRecorder()
+	handler.Reset(rec, httptest.NewRequest(http.MethodPost, "/api/password/reset", bytes.NewBuffer(body)))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+	if _, err := authService.Authenticate("reset@example.com", "new-password"); err != nil {
+		t.Errorf("Expected the new password to work, got %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.Reset(rec, httptest.NewRequest(http.MethodPost, "/api/password/reset", bytes.NewBuffer(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a used token, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
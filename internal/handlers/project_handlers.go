@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ProjectHandler struct {
+	projectService *service.ProjectService
+	logger         *slog.Logger
+}
+
+func NewProjectHandler(projectService *service.ProjectService, logger *slog.Logger) *ProjectHandler {
+	return &ProjectHandler{
+		projectService: projectService,
+		logger:         logger,
+	}
+}
+
+type CreateProjectRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UpdateProjectRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Archived    *bool   `json:"archived,omitempty"`
+}
+
+type ProjectResponse struct {
+	Project domain.Project `json:"project"`
+}
+
+type ProjectsResponse struct {
+	Projects []*domain.Project `json:"projects"`
+}
+
+func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.projectService.Create(r.Context(), claims.UserID, req.Name, req.Description)
+	if err != nil {
+		h.writeError(w, err, "Failed to create project", "")
+		return
+	}
+
+	h.logger.Info("Project created", "project_id", project.ID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusCreated, ProjectResponse{Project: *project}, h.logger)
+}
+
+// List returns the caller's projects. Archived projects are included only
+// with ?archived=true; ?shared=true lists the projects other users shared
+// with the caller instead.
+func (h *ProjectHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	includeArchived := r.URL.Query().Get("archived") == "true"
+
+	var projects []*domain.Project
+	var err error
+	if r.URL.Query().Get("shared") == "true" {
+		projects, err = h.projectService.ListShared(claims.UserID)
+	} else {
+		projects, err = h.projectService.List(claims.UserID, includeArchived)
+	}
+	if err != nil {
+		h.logger.Error("Failed to list projects", "error", err, "user_id", claims.UserID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, ProjectsResponse{Projects: projects}, h.logger)
+}
+
+func (h *ProjectHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	projectID := chi.URLParam(r, "id")
+	if projectID == "" {
+		http.Error(w, "Project ID required", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.projectService.GetByID(projectID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to get project", projectID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, ProjectResponse{Project: *project}, h.logger)
+}
+
+func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	projectID := chi.URLParam(r, "id")
+	if projectID == "" {
+		http.Error(w, "Project ID required", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.projectService.Update(r.Context(), projectID, claims.UserID, claims.Role, service.UpdateProjectParams{
+		Name:        req.Name,
+		Description: req.Description,
+		Archived:    req.Archived,
+	})
+	if err != nil {
+		h.writeError(w, err, "Failed to update project", projectID)
+		return
+	}
+
+	h.logger.Info("Project updated", "project_id", projectID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusOK, ProjectResponse{Project: *project}, h.logger)
+}
+
+func (h *ProjectHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	projectID := chi.URLParam(r, "id")
+	if projectID == "" {
+		http.Error(w, "Project ID required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.projectService.Delete(r.Context(), projectID, claims.UserID, claims.Role); err != nil {
+		h.writeError(w, err, "Failed to delete project", projectID)
+		return
+	}
+
+	h.logger.Info("Project deleted", "project_id", projectID, "user_id", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProjectHandler) writeError(w http.ResponseWriter, err error, msg, projectID string) {
+	switch {
+	case errors.Is(err, domain.ErrProjectNotFound):
+		http.Error(w, "Project not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, domain.ErrInvalidProject):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error(msg, "error", err, "project_id", projectID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func setupProjectTestHandler(t *testing.T) (*ProjectHandler, *TodoHandler, *store.UserRepo, *store.ProjectRepo) {
+	t.Helper()
+
+	db := testutil.SetupTestDB(t)
+	userRepo := store.NewUserRepo(db)
+	projectRepo := store.NewProjectRepo(db)
+	projectService := service.NewProjectService(projectRepo, store.NewAuditRepo(db), store.NewTransactor(db))
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	return NewProjectHandler(projectService, logger), NewTodoHandler(newTestTodoService(db), logger), userRepo, projectRepo
+}
+
+func TestProjectCreate_AndList(t *testing.T) {
+	handler, _, userRepo, _ := setupProjectTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	for _, name := range []string{" Work ", ""} {
+		body, _ := json.Marshal(CreateProjectRequest{Name: name})
+		req := httptest.NewRequest(http.MethodPost, "/api/projects", bytes.NewBuffer(body))
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		expected := http.StatusCreated
+		if name == "" {
+			expected = http.StatusBadRequest
+		}
+		if rec.Code != expected {
+			t.Fatalf("%q: expected status %d, got %d", name, expected, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/projects", nil)
+	req = requestWithClaims(req, claims)
+	rec := httptest.NewRecorder()
+
+	handler.List(rec, req)
+
+	var resp ProjectsResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Projects) != 1 || resp.Projects[0].Name != "Work" {
+		t.Errorf("Expected one project named Work, got %+v", resp.Projects)
+	}
+}
+
+func TestProjectUpdate_Archive(t *testing.T) {
+	handler, _, userRepo, projectRepo := setupProjectTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	project := domain.NewProject(user.ID, "Old", "")
+	projectRepo.Create(project)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/projects/"+project.ID, bytes.NewBufferString(`{"archived": true}`))
+	req = requestWithClaimsAndID(req, claims, "id", project.ID)
+	rec := httptest.NewRecorder()
+
+	handler.Update(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	for query, expected := range map[string]int{"": 0, "?archived=true": 1} {
+		req := httptest.NewRequest(http.MethodGet, "/api/projects"+query, nil)
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		var resp ProjectsResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if len(resp.Projects) != expected {
+			t.Errorf("%q: expected %d projects, got %d", query, expected, len(resp.Projects))
+		}
+	}
+}
+
+func TestProjectGetByID_Forbidden(t *testing.T) {
+	handler, _, userRepo, projectRepo := setupProjectTestHandler(t)
+
+	owner := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	project := domain.NewProject(owner.ID, "Private", "")
+	projectRepo.Create(project)
+	claims := &auth.Claims{UserID: other.ID, Email: other.Email, Role: domain.RoleUser}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/projects/"+project.ID, nil)
+	req = requestWithClaimsAndID(req, claims, "id", project.ID)
+	rec := httptest.NewRecorder()
+
+	handler.GetByID(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, rec.Code)
+	}
+}
+
+func TestCreateTodo_InProject(t *testing.T) {
+	_, todoHandler, userRepo, projectRepo := setupProjectTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	project := domain.NewProject(user.ID, "Mine", "")
+	archived := domain.NewProject(user.ID, "Done", "")
+	archived.Archived = true
+	foreign := domain.NewProject(other.ID, "Theirs", "")
+	projectRepo.Create(project)
+	projectRepo.Create(archived)
+	projectRepo.Create(foreign)
+
+	tests := []struct {
+		name           string
+		projectID      string
+		expectedStatus int
+	}{
+		{name: "own project", projectID: project.ID, expectedStatus: http.StatusCreated},
+		{name: "archived project", projectID: archived.ID, expectedStatus: http.StatusBadRequest},
+		{name: "someone else's project", projectID: foreign.ID, expectedStatus: http.StatusBadRequest},
+		{name: "missing project", projectID: "nonexistent-id", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(CreateTodoRequest{Title: "Filed", ProjectID: tt.projectID})
+			req := httptest.NewRequest(http.MethodPost, "/api/todos", bytes.NewBuffer(body))
+			req = requestWithClaims(req, claims)
+			rec := httptest.NewRecorder()
+
+			todoHandler.Create(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/todos?project="+project.ID, nil)
+	req = requestWithClaims(req, claims)
+	rec := httptest.NewRecorder()
+
+	todoHandler.List(rec, req)
+
+	var resp TodosResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Todos) != 1 || resp.Todos[0].ProjectID != project.ID {
+		t.Errorf("Expected one todo in the project, got %+v", resp.Todos)
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SessionHandler serves the sessions, or logged in devices, of users. The
+// caller's own are at /api/sessions and anyone's at /api/users/{id}/sessions.
+type SessionHandler struct {
+	authService *service.AuthService
+	logger      *slog.Logger
+}
+
+func NewSessionHandler(authService *service.AuthService, logger *slog.Logger) *SessionHandler {
+	return &SessionHandler{
+		authService: authService,
+		logger:      logger,
+	}
+}
+
+type SessionsResponse struct {
+	Sessions []*domain.Session `json:"sessions"`
+}
+
+// List handles GET /api/sessions.
+func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	h.list(w, claims, claims.UserID)
+}
+
+// Revoke handles DELETE /api/sessions/{id}.
+func (h *SessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	h.revoke(w, r, claims, claims.UserID, chi.URLParam(r, "id"))
+}
+
+// ListUserSessions handles GET /api/users/{id}/sessions.
+func (h *SessionHandler) ListUserSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	h.list(w, claims, chi.URLParam(r, "id"))
+}
+
+// RevokeUserSession handles DELETE /api/users/{id}/sessions/{sessionID}.
+func (h *SessionHandler) RevokeUserSession(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	h.revoke(w, r, claims, chi.URLParam(r, "id"), chi.URLParam(r, "sessionID"))
+}
+
+func (h *SessionHandler) list(w http.ResponseWriter, claims *auth.Claims, userID string) {
+	sessions, err := h.authService.ListSessions(userID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to list sessions", userID)
+		return
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == claims.SessionID
+	}
+
+	writeJsonResponse(w, http.StatusOK, SessionsResponse{Sessions: sessions}, h.logger)
+}
+
+func (h *SessionHandler) revoke(w http.ResponseWriter, r *http.Request, claims *auth.Claims, userID, sessionID string) {
+	if err := h.authService.RevokeSession(r.Context(), userID, sessionID, claims.UserID, claims.Role); err != nil {
+		h.writeError(w, err, "Failed to revoke session", sessionID)
+		return
+	}
+
+	h.logger.Info("Session revoked", "session_id", sessionID, "user_id", userID, "revoked_by", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SessionHandler) writeError(w http.ResponseWriter, err error, msg, resourceID string) {
+	switch {
+	case errors.Is(err, domain.ErrSessionNotFound):
+		http.Error(w, "Session not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	default:
+		h.logger.Error(msg, "error", err, "resource_id", resourceID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	authService := newTestAuthService(db)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := NewSessionHandler(authService, logger)
+
+	user, _ := authService.Register(context.Background(), "sessions@example.com", "password123")
+	other, _ := authService.Register(context.Background(), "other@example.com", "password123")
+	current, _ := authService.StartSession(context.Background(), user)
+	authService.StartSession(context.Background(), user)
+	claims, _ := auth.ValidateToken(current.AccessToken, "test-jwt-secret")
+
+	rec := httptest.NewRecorder()
+	handler.List(rec, requestWithClaims(httptest.NewRequest(http.MethodGet, "/api/sessions", nil), claims))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var response SessionsResponse
+	json.NewDecoder(rec.Body).Decode(&response)
+	if len(response.Sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(response.Sessions))
+	}
+	var currentCount int
+	for _, session := range response.Sessions {
+		if session.Current {
+			currentCount++
+			if session.ID != claims.SessionID {
+				t.Errorf("Expected session %s to be current, got %s", claims.SessionID, session.ID)
+			}
+		}
+	}
+	if currentCount != 1 {
+		t.Errorf("Expected exactly one current session, got %d", currentCount)
+	}
+
+	// Another user can neither see nor revoke the sessions
+	otherClaims := &auth.Claims{UserID: other.ID, Email: other.Email, Role: domain.RoleUser}
+	rec = httptest.NewRecorder()
+	handler.ListUserSessions(rec, requestWithClaimsAndID(httptest.NewRequest(http.MethodGet, "/api/users/"+user.ID+"/sessions", nil), otherClaims, "id", user.ID))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.Revoke(rec, requestWithClaimsAndID(httptest.NewRequest(http.MethodDelete, "/api/sessions/"+claims.SessionID, nil), otherClaims, "id", claims.SessionID))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+
+	// An admin revokes one of the user's sessions
+	adminClaims := &auth.Claims{UserID: other.ID, Email: other.Email, Role: domain.RoleAdmin}
+	req := httptest.NewRequest(http.MethodDelete, "/api/users/"+user.ID+"/sessions/"+claims.SessionID, nil)
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", user.ID)
+	routeCtx.URLParams.Add("sessionID", claims.SessionID)
+	req = requestWithClaims(req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)), adminClaims)
+	rec = httptest.NewRecorder()
+	handler.RevokeUserSession(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+
+	revocations := service.NewTokenRevocations(store.NewRevokedTokenRepo(db), store.NewUserRepo(db))
+	protected := auth.Middleware("test-jwt-secret", revocations)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req = httptest.NewRequest(http.MethodGet, "/api/todos", nil)
+	req.Header.Set("Authorization", "Bearer "+current.AccessToken)
+	rec = httptest.NewRecorder()
+	protected.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for a revoked session, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ListUserSessions(rec, requestWithClaimsAndID(httptest.NewRequest(http.MethodGet, "/api/users/"+user.ID+"/sessions", nil), adminClaims, "id", user.ID))
+	response = SessionsResponse{}
+	json.NewDecoder(rec.Body).Decode(&response)
+	if rec.Code != http.StatusOK || len(response.Sessions) != 1 {
+		t.Errorf("Expected 1 session left, got status %d and %d sessions", rec.Code, len(response.Sessions))
+	}
+}
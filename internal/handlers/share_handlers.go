@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ShareHandler serves the shares of todos and projects. The same handler
+// backs /api/todos/{id}/shares and /api/projects/{id}/shares.
+type ShareHandler struct {
+	shareService *service.ShareService
+	logger       *slog.Logger
+}
+
+func NewShareHandler(shareService *service.ShareService, logger *slog.Logger) *ShareHandler {
+	return &ShareHandler{
+		shareService: shareService,
+		logger:       logger,
+	}
+}
+
+type ShareRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type ShareResponse struct {
+	Share domain.Share `json:"share"`
+}
+
+type SharesResponse struct {
+	Shares []*domain.Share `json:"shares"`
+}
+
+func (h *ShareHandler) ListTodoShares(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	shares, err := h.shareService.TodoShares(todoID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to list shares", todoID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, SharesResponse{Shares: shares}, h.logger)
+}
+
+func (h *ShareHandler) ShareTodo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	var req ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	share, err := h.shareService.ShareTodo(r.Context(), todoID, claims.UserID, claims.Role, req.Email, req.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to share todo", todoID)
+		return
+	}
+
+	h.logger.Info("Todo shared", "todo_id", todoID, "share_id", share.ID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusCreated, ShareResponse{Share: *share}, h.logger)
+}
+
+func (h *ShareHandler) RevokeTodoShare(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	shareID := chi.URLParam(r, "shareID")
+
+	if err := h.shareService.RevokeTodoShare(r.Context(), todoID, shareID, claims.UserID, claims.Role); err != nil {
+		h.writeError(w, err, "Failed to revoke share", todoID)
+		return
+	}
+
+	h.logger.Info("Todo share revoked", "todo_id", todoID, "share_id", shareID, "user_id", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ShareHandler) ListProjectShares(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	projectID := chi.URLParam(r, "id")
+
+	shares, err := h.shareService.ProjectShares(projectID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to list shares", projectID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, SharesResponse{Shares: shares}, h.logger)
+}
+
+func (h *ShareHandler) ShareProject(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	projectID := chi.URLParam(r, "id")
+
+	var req ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	share, err := h.shareService.ShareProject(r.Context(), projectID, claims.UserID, claims.Role, req.Email, req.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to share project", projectID)
+		return
+	}
+
+	h.logger.Info("Project shared", "project_id", projectID, "share_id", share.ID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusCreated, ShareResponse{Share: *share}, h.logger)
+}
+
+func (h *ShareHandler) RevokeProjectShare(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	projectID := chi.URLParam(r, "id")
+	shareID := chi.URLParam(r, "shareID")
+
+	if err := h.shareService.RevokeProjectShare(r.Context(), projectID, shareID, claims.UserID, claims.Role); err != nil {
+		h.writeError(w, err, "Failed to revoke share", projectID)
+		return
+	}
+
+	h.logger.Info("Project share revoked", "project_id", projectID, "share_id", shareID, "user_id", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ShareHandler) writeError(w http.ResponseWriter, err error, msg, resourceID string) {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound):
+		http.Error(w, "Todo not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrProjectNotFound):
+		http.Error(w, "Project not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrShareNotFound):
+		http.Error(w, "Share not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, domain.ErrInvalidShare):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error(msg, "error", err, "resource_id", resourceID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
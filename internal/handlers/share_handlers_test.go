@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type shareTestEnv struct {
+	router   http.Handler
+	users    *store.UserRepo
+	todos    *store.TodoRepo
+	projects *store.ProjectRepo
+}
+
+// setupShareTestHandler serves the share endpoints next to the todo routes
+// they open up.
+func setupShareTestHandler(t *testing.T) shareTestEnv {
+	t.Helper()
+
+	db := testutil.SetupTestDB(t)
+	env := shareTestEnv{
+		users:    store.NewUserRepo(db),
+		todos:    store.NewTodoRepo(db),
+		projects: store.NewProjectRepo(db),
+	}
+	todoService := newTestTodoService(db)
+	projectService := service.NewProjectService(env.projects, store.NewAuditRepo(db), store.NewTransactor(db))
+	shareService := service.NewShareService(store.NewShareRepo(db), env.users, store.NewAuditRepo(db), todoService, projectService, store.NewTransactor(db))
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	todoHandler := NewTodoHandler(todoService, logger)
+	shareHandler := NewShareHandler(shareService, logger)
+
+	r := chi.NewRouter()
+	r.Route("/api/todos", func(r chi.Router) {
+		r.Get("/", todoHandler.List)
+		r.Get("/{id}", todoHandler.GetByID)
+		r.Patch("/{id}", todoHandler.Update)
+		r.Post("/{id}/move", todoHandler.Move)
+		r.Route("/{id}/shares", func(r chi.Router) {
+			r.Get("/", shareHandler.ListTodoShares)
+			r.Post("/", shareHandler.ShareTodo)
+			r.Delete("/{shareID}", shareHandler.RevokeTodoShare)
+		})
+	})
+	r.Route("/api/projects/{id}/shares", func(r chi.Router) {
+		r.Get("/", shareHandler.ListProjectShares)
+		r.Post("/", shareHandler.ShareProject)
+		r.Delete("/{shareID}", shareHandler.RevokeProjectShare)
+	})
+	env.router = r
+
+	return env
+}
+
+func claimsFor(user *domain.User) *auth.Claims {
+	return &auth.Claims{UserID: user.ID, Email: user.Email, Role: user.Role}
+}
+
+func postShare(t *testing.T, router http.Handler, path, email, role string, claims *auth.Claims) *httptest.ResponseRecorder {
+	t.Helper()
+	reqBody, _ := json.Marshal(ShareRequest{Email: email, Role: role})
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(reqBody))
+	return serveWithClaims(router, req, claims)
+}
+
+func patchTitle(router http.Handler, todoID, title string, claims *auth.Claims) *httptest.ResponseRecorder {
+	reqBody, _ := json.Marshal(map[string]string{"title": title})
+	req := httptest.NewRequest(http.MethodPatch, "/api/todos/"+todoID, bytes.NewBuffer(reqBody))
+	return serveWithClaims(router, req, claims)
+}
+
+func TestShareTodo_ViewerCanReadButNotEdit(t *testing.T) {
+	env := setupShareTestHandler(t)
+	owner := createTestUser(t, env.users, domain.RoleUser)
+	viewer := createTestUser(t, env.users, domain.RoleUser)
+	todo := createTestTodo(t, env.todos, owner.ID)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/todos/"+todo.ID, nil)
+	if rec := serveWithClaims(env.router, req, claimsFor(viewer)); rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d before sharing, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	rec := postShare(t, env.router, "/api/todos/"+todo.ID+"/shares", viewer.Email, "viewer", claimsFor(owner))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/todos/"+todo.ID, nil)
+	if rec := serveWithClaims(env.router, req, claimsFor(viewer)); rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d for a viewer, got %d", http.StatusOK, rec.Code)
+	}
+	if rec := patchTitle(env.router, todo.ID, "Renamed", claimsFor(viewer)); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for a viewer edit, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/todos?shared=true", nil)
+	rec = serveWithClaims(env.router, req, claimsFor(viewer))
+	var list TodosResponse
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Todos) != 1 || list.Todos[0].ID != todo.ID {
+		t.Errorf("Expected the todo under shared=true, got %d todos", len(list.Todos))
+	}
+}
+
+func TestShareTodo_EditorCanEditButNotMove(t *testing.T) {
+	env := setupShareTestHandler(t)
+	owner := createTestUser(t, env.users, domain.RoleUser)
+	editor := createTestUser(t, env.users, domain.RoleUser)
+	todo := createTestTodo(t, env.todos, owner.ID)
+
+	postShare(t, env.router, "/api/todos/"+todo.ID+"/shares", editor.Email, "editor", claimsFor(owner))
+
+	if rec := patchTitle(env.router, todo.ID, "Renamed", claimsFor(editor)); rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d for an editor edit, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	other := createTestTodo(t, env.todos, owner.ID)
+	reqBody, _ := json.Marshal(MoveTodoRequest{AfterID: other.ID})
+	req := httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/move", bytes.NewBuffer(reqBody))
+	if rec := serveWithClaims(env.router, req, claimsFor(editor)); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for an editor move, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	// Only the owner manages shares
+	rec := postShare(t, env.router, "/api/todos/"+todo.ID+"/shares", owner.Email, "editor", claimsFor(editor))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d when an editor shares, got %d", http.StatusForbidden, rec.Code)
+	}
+}
+
+func TestShareTodo_Invalid(t *testing.T) {
+	env := setupShareTestHandler(t)
+	owner := createTestUser(t, env.users, domain.RoleUser)
+	other := createTestUser(t, env.users, domain.RoleUser)
+	todo := createTestTodo(t, env.todos, owner.ID)
+	path := "/api/todos/" + todo.ID + "/shares"
+
+	tests := []struct {
+		name   string
+		email  string
+		role   string
+		status int
+	}{
+		{"unknown role", other.Email, "admin", http.StatusBadRequest},
+		{"owner", owner.Email, "viewer", http.StatusBadRequest},
+		{"unknown user", "nobody@example.com", "viewer", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := postShare(t, env.router, path, tt.email, tt.role, claimsFor(owner)); rec.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRevokeTodoShare(t *testing.T) {
+	env := setupShareTestHandler(t)
+	owner := createTestUser(t, env.users, domain.RoleUser)
+	viewer := createTestUser(t, env.users, domain.RoleUser)
+	todo := createTestTodo(t, env.todos, owner.ID)
+
+	rec := postShare(t, env.router, "/api/todos/"+todo.ID+"/shares", viewer.Email, "viewer", claimsFor(owner))
+	var created ShareResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/todos/"+todo.ID+"/shares/"+created.Share.ID, nil)
+	if rec := serveWithClaims(env.router, req, claimsFor(owner)); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/todos/"+todo.ID, nil)
+	if rec := serveWithClaims(env.router, req, claimsFor(viewer)); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d after revoking, got %d", http.StatusForbidden, rec.Code)
+	}
+}
+
+func TestShareProject_GrantsItsTodos(t *testing.T) {
+	env := setupShareTestHandler(t)
+	owner := createTestUser(t, env.users, domain.RoleUser)
+	editor := createTestUser(t, env.users, domain.RoleUser)
+
+	project := domain.NewProject(owner.ID, "Household", "")
+	env.projects.Create(project)
+	todo := domain.NewTodo(owner.ID, "Fix the sink", "")
+	todo.ProjectID = project.ID
+	env.todos.Create(todo)
+
+	rec := postShare(t, env.router, "/api/projects/"+project.ID+"/shares", editor.Email, "editor", claimsFor(owner))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/todos?project="+project.ID, nil)
+	rec = serveWithClaims(env.router, req, claimsFor(editor))
+	var list TodosResponse
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Todos) != 1 || list.Todos[0].ID != todo.ID {
+		t.Errorf("Expected the project's todo, got %d todos", len(list.Todos))
+	}
+
+	if rec := patchTitle(env.router, todo.ID, "Fixed the sink", claimsFor(editor)); rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d for an editor edit, got %d", http.StatusOK, rec.Code)
+	}
+
+	// Editors can't file the todo elsewhere
+	reqBody, _ := json.Marshal(map[string]string{"project_id": ""})
+	req = httptest.NewRequest(http.MethodPatch, "/api/todos/"+todo.ID, bytes.NewBuffer(reqBody))
+	if rec := serveWithClaims(env.router, req, claimsFor(editor)); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d when an editor changes the project, got %d", http.StatusForbidden, rec.Code)
+	}
+}
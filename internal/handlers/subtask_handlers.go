@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SubtaskHandler struct {
+	subtaskService *service.SubtaskService
+	logger         *slog.Logger
+}
+
+func NewSubtaskHandler(subtaskService *service.SubtaskService, logger *slog.Logger) *SubtaskHandler {
+	return &SubtaskHandler{
+		subtaskService: subtaskService,
+		logger:         logger,
+	}
+}
+
+type CreateSubtaskRequest struct {
+	Title string `json:"title"`
+}
+
+type UpdateSubtaskRequest struct {
+	Title     *string `json:"title,omitempty"`
+	Completed *bool   `json:"completed,omitempty"`
+}
+
+type ReorderSubtasksRequest struct {
+	IDs []string `json:"ids"`
+}
+
+type SubtaskResponse struct {
+	Subtask domain.Subtask `json:"subtask"`
+}
+
+type SubtasksResponse struct {
+	Subtasks []*domain.Subtask `json:"subtasks"`
+}
+
+func (h *SubtaskHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	subtasks, err := h.subtaskService.List(todoID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to list subtasks", todoID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, SubtasksResponse{Subtasks: subtasks}, h.logger)
+}
+
+func (h *SubtaskHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	var req CreateSubtaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	subtask, err := h.subtaskService.Create(r.Context(), todoID, claims.UserID, claims.Role, req.Title)
+	if err != nil {
+		h.writeError(w, err, "Failed to create subtask", todoID)
+		return
+	}
+
+	h.logger.Info("Subtask created", "subtask_id", subtask.ID, "todo_id", todoID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusCreated, SubtaskResponse{Subtask: *subtask}, h.logger)
+}
+
+func (h *SubtaskHandler) Update(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	subtaskID := chi.URLParam(r, "subtaskID")
+
+	var req UpdateSubtaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	subtask, err := h.subtaskService.Update(r.Context(), todoID, subtaskID, claims.UserID, claims.Role, req.Title, req.Completed)
+	if err != nil {
+		h.writeError(w, err, "Failed to update subtask", todoID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, SubtaskResponse{Subtask: *subtask}, h.logger)
+}
+
+func (h *SubtaskHandler) Toggle(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	subtaskID := chi.URLParam(r, "subtaskID")
+
+	subtask, err := h.subtaskService.Toggle(r.Context(), todoID, subtaskID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to toggle subtask", todoID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, SubtaskResponse{Subtask: *subtask}, h.logger)
+}
+
+func (h *SubtaskHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	var req ReorderSubtasksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	subtasks, err := h.subtaskService.Reorder(r.Context(), todoID, claims.UserID, claims.Role, req.IDs)
+	if err != nil {
+		h.writeError(w, err, "Failed to reorder subtasks", todoID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, SubtasksResponse{Subtasks: subtasks}, h.logger)
+}
+
+func (h *SubtaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	subtaskID := chi.URLParam(r, "subtaskID")
+
+	if err := h.subtaskService.Delete(r.Context(), todoID, subtaskID, claims.UserID, claims.Role); err != nil {
+		h.writeError(w, err, "Failed to delete subtask", todoID)
+		return
+	}
+
+	h.logger.Info("Subtask deleted", "subtask_id", subtaskID, "todo_id", todoID, "user_id", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SubtaskHandler) writeError(w http.ResponseWriter, err error, msg, todoID string) {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound):
+		http.Error(w, "Todo not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrSubtaskNotFound):
+		http.Error(w, "Subtask not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error(msg, "error", err, "todo_id", todoID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
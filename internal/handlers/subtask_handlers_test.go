@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func setupSubtaskTestHandler(t *testing.T) (http.Handler, *store.UserRepo, *store.TodoRepo, *store.SubtaskRepo) {
+	t.Helper()
+
+	db := testutil.SetupTestDB(t)
+	userRepo := store.NewUserRepo(db)
+	todoRepo := store.NewTodoRepo(db)
+	subtaskRepo := store.NewSubtaskRepo(db)
+	subtaskService := service.NewSubtaskService(subtaskRepo, store.NewAuditRepo(db), newTestTodoService(db), store.NewTransactor(db))
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := NewSubtaskHandler(subtaskService, logger)
+
+	// Route through chi so both {id} and {subtaskID} are populated
+	r := chi.NewRouter()
+	r.Route("/api/todos/{id}/subtasks", func(r chi.Router) {
+		r.Get("/", handler.List)
+		r.Post("/", handler.Create)
+		r.Post("/reorder", handler.Reorder)
+		r.Patch("/{subtaskID}", handler.Update)
+		r.Post("/{subtaskID}/toggle", handler.Toggle)
+		r.Delete("/{subtaskID}", handler.Delete)
+	})
+
+	return r, userRepo, todoRepo, subtaskRepo
+}
+
+func serveWithClaims(router http.Handler, req *http.Request, claims *auth.Claims) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, requestWithClaims(req, claims))
+	return rec
+}
+
+func TestSubtaskCreate_AndToggle(t *testing.T) {
+	router, userRepo, todoRepo, _ := setupSubtaskTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	body, _ := json.Marshal(CreateSubtaskRequest{Title: "Step one"})
+	req := httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/subtasks", bytes.NewBuffer(body))
+	rec := serveWithClaims(router, req, claims)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var created SubtaskResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/subtasks/"+created.Subtask.ID+"/toggle", nil)
+	rec = serveWithClaims(router, req, claims)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var toggled SubtaskResponse
+	json.NewDecoder(rec.Body).Decode(&toggled)
+	if !toggled.Subtask.Completed {
+		t.Error("Expected subtask to be completed after toggle")
+	}
+
+	saved, _ := todoRepo.GetByID(todo.ID)
+	if saved.Progress.Done != 1 || saved.Progress.Total != 1 {
+		t.Errorf("Expected todo progress 1/1, got %s", saved.Progress)
+	}
+}
+
+func TestSubtaskCreate_Forbidden(t *testing.T) {
+	router, userRepo, todoRepo, _ := setupSubtaskTestHandler(t)
+
+	owner := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, owner.ID)
+	claims := &auth.Claims{UserID: other.ID, Email: other.Email, Role: domain.RoleUser}
+
+	body, _ := json.Marshal(CreateSubtaskRequest{Title: "Sneaky"})
+	req := httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/subtasks", bytes.NewBuffer(body))
+	rec := serveWithClaims(router, req, claims)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, rec.Code)
+	}
+}
+
+func TestSubtaskReorder(t *testing.T) {
+	router, userRepo, todoRepo, subtaskRepo := setupSubtaskTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	a := domain.NewSubtask(todo.ID, "A", 0)
+	b := domain.NewSubtask(todo.ID, "B", 0)
+	subtaskRepo.Create(a)
+	subtaskRepo.Create(b)
+
+	tests := []struct {
+		name           string
+		ids            []string
+		expectedStatus int
+	}{
+		{name: "missing id", ids: []string{b.ID}, expectedStatus: http.StatusBadRequest},
+		{name: "duplicate id", ids: []string{b.ID, b.ID}, expectedStatus: http.StatusBadRequest},
+		{name: "valid", ids: []string{b.ID, a.ID}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(ReorderSubtasksRequest{IDs: tt.ids})
+			req := httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/subtasks/reorder", bytes.NewBuffer(body))
+			rec := serveWithClaims(router, req, claims)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+
+	subtasks, _ := subtaskRepo.GetByTodoID(todo.ID)
+	if subtasks[0].ID != b.ID {
+		t.Errorf("Expected B first after reorder, got %s", subtasks[0].Title)
+	}
+}
+
+func TestSubtaskDelete_WrongTodo(t *testing.T) {
+	router, userRepo, todoRepo, subtaskRepo := setupSubtaskTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	otherTodo := createTestTodo(t, todoRepo, user.ID)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	subtask := domain.NewSubtask(otherTodo.ID, "Elsewhere", 0)
+	subtaskRepo.Create(subtask)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/todos/"+todo.ID+"/subtasks/"+subtask.ID, nil)
+	rec := serveWithClaims(router, req, claims)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TagHandler struct {
+	tagService *service.TagService
+	logger     *slog.Logger
+}
+
+func NewTagHandler(tagService *service.TagService, logger *slog.Logger) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+		logger:     logger,
+	}
+}
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+type TagResponse struct {
+	Tag domain.Tag `json:"tag"`
+}
+
+type TagsResponse struct {
+	Tags []*domain.Tag `json:"tags"`
+}
+
+func (h *TagHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.tagService.Create(r.Context(), claims.UserID, req.Name)
+	if err != nil {
+		h.writeError(w, err, "Failed to create tag", "")
+		return
+	}
+
+	h.logger.Info("Tag created", "tag_id", tag.ID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusCreated, TagResponse{Tag: *tag}, h.logger)
+}
+
+func (h *TagHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tags, err := h.tagService.List(claims.UserID)
+	if err != nil {
+		h.logger.Error("Failed to list tags", "error", err, "user_id", claims.UserID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, TagsResponse{Tags: tags}, h.logger)
+}
+
+func (h *TagHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tagID := chi.URLParam(r, "id")
+	if tagID == "" {
+		http.Error(w, "Tag ID required", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.tagService.GetByID(tagID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeError(w, err, "Failed to get tag", tagID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, TagResponse{Tag: *tag}, h.logger)
+}
+
+func (h *TagHandler) Update(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tagID := chi.URLParam(r, "id")
+	if tagID == "" {
+		http.Error(w, "Tag ID required", http.StatusBadRequest)
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.tagService.Update(r.Context(), tagID, claims.UserID, claims.Role, req.Name)
+	if err != nil {
+		h.writeError(w, err, "Failed to update tag", tagID)
+		return
+	}
+
+	h.logger.Info("Tag updated", "tag_id", tagID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusOK, TagResponse{Tag: *tag}, h.logger)
+}
+
+func (h *TagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tagID := chi.URLParam(r, "id")
+	if tagID == "" {
+		http.Error(w, "Tag ID required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.tagService.Delete(r.Context(), tagID, claims.UserID, claims.Role); err != nil {
+		h.writeError(w, err, "Failed to delete tag", tagID)
+		return
+	}
+
+	h.logger.Info("Tag deleted", "tag_id", tagID, "user_id", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TagHandler) writeError(w http.ResponseWriter, err error, msg, tagID string) {
+	switch {
+	case errors.Is(err, domain.ErrTagNotFound):
+		http.Error(w, "Tag not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, domain.ErrInvalidTag):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrTagExists):
+		http.Error(w, "Tag already exists", http.StatusConflict)
+	default:
+		h.logger.Error(msg, "error", err, "tag_id", tagID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
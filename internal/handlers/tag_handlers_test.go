@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/service"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func setupTagTestHandler(t *testing.T) (*TagHandler, *store.UserRepo, *store.TagRepo) {
+	t.Helper()
+
+	db := testutil.SetupTestDB(t)
+	userRepo := store.NewUserRepo(db)
+	tagRepo := store.NewTagRepo(db)
+	tagService := service.NewTagService(tagRepo, store.NewAuditRepo(db), store.NewTransactor(db))
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	return NewTagHandler(tagService, logger), userRepo, tagRepo
+}
+
+func TestTagCreate_Success(t *testing.T) {
+	handler, userRepo, _ := setupTagTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	body, _ := json.Marshal(TagRequest{Name: "  Work "})
+	req := httptest.NewRequest(http.MethodPost, "/api/tags", bytes.NewBuffer(body))
+	req = requestWithClaims(req, claims)
+	rec := httptest.NewRecorder()
+
+	handler.Create(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, rec.Code)
+	}
+
+	var resp TagResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.Tag.Name != "work" {
+		t.Errorf("Expected normalized name %q, got %q", "work", resp.Tag.Name)
+	}
+}
+
+func TestTagCreate_Failures(t *testing.T) {
+	handler, userRepo, tagRepo := setupTagTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	tagRepo.Create(domain.NewTag(user.ID, "work"))
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	tests := []struct {
+		name           string
+		body           TagRequest
+		expectedStatus int
+	}{
+		{name: "empty name", body: TagRequest{Name: " "}, expectedStatus: http.StatusBadRequest},
+		{name: "comma", body: TagRequest{Name: "a,b"}, expectedStatus: http.StatusBadRequest},
+		{name: "duplicate", body: TagRequest{Name: "Work"}, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/api/tags", bytes.NewBuffer(body))
+			req = requestWithClaims(req, claims)
+			rec := httptest.NewRecorder()
+
+			handler.Create(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestTagList_OnlyOwnTags(t *testing.T) {
+	handler, userRepo, tagRepo := setupTagTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	tagRepo.Create(domain.NewTag(user.ID, "mine"))
+	tagRepo.Create(domain.NewTag(other.ID, "theirs"))
+
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+	req := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+	req = requestWithClaims(req, claims)
+	rec := httptest.NewRecorder()
+
+	handler.List(rec, req)
+
+	var resp TagsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(resp.Tags) != 1 || resp.Tags[0].Name != "mine" {
+		t.Errorf("Expected only own tag, got %v", resp.Tags)
+	}
+}
+
+func TestTagUpdate_Forbidden(t *testing.T) {
+	handler, userRepo, tagRepo := setupTagTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	tag := domain.NewTag(other.ID, "theirs")
+	tagRepo.Create(tag)
+
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+	body, _ := json.Marshal(TagRequest{Name: "stolen"})
+	req := httptest.NewRequest(http.MethodPatch, "/api/tags/"+tag.ID, bytes.NewBuffer(body))
+	req = requestWithClaimsAndID(req, claims, "id", tag.ID)
+	rec := httptest.NewRecorder()
+
+	handler.Update(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, rec.Code)
+	}
+}
+
+func TestTagDelete_Success(t *testing.T) {
+	handler, userRepo, tagRepo := setupTagTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	tag := domain.NewTag(user.ID, "work")
+	tagRepo.Create(tag)
+
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+	req := httptest.NewRequest(http.MethodDelete, "/api/tags/"+tag.ID, nil)
+	req = requestWithClaimsAndID(req, claims, "id", tag.ID)
+	rec := httptest.NewRecorder()
+
+	handler.Delete(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+
+	if _, err := tagRepo.GetByID(tag.ID); err != domain.ErrTagNotFound {
+		t.Errorf("Expected tag to be deleted, got %v", err)
+	}
+}
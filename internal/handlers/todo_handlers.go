@@ -3,11 +3,15 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"godo/internal/auth"
 	"godo/internal/domain"
 	"godo/internal/service"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -25,22 +29,80 @@ func NewTodoHandler(todoService *service.TodoService, logger *slog.Logger) *Todo
 }
 
 type CreateTodoRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	ProjectID   string   `json:"project_id,omitempty"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Priority    string   `json:"priority,omitempty"`
+	DueDate     string   `json:"due_date,omitempty"`
+	DueTime     string   `json:"due_time,omitempty"`
+	DueTimezone string   `json:"due_timezone,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Recurrence  string   `json:"recurrence,omitempty"`
 }
 
 type UpdateTodoRequest struct {
-	Title       *string `json:"title,omitempty"`
-	Description *string `json:"description,omitempty"`
-	Completed   *bool   `json:"completed,omitempty"`
+	ProjectID   *string   `json:"project_id,omitempty"`
+	Title       *string   `json:"title,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Completed   *bool     `json:"completed,omitempty"`
+	Priority    *string   `json:"priority,omitempty"`
+	DueDate     *string   `json:"due_date,omitempty"`
+	DueTime     *string   `json:"due_time,omitempty"`
+	DueTimezone *string   `json:"due_timezone,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Recurrence  *string   `json:"recurrence,omitempty"`
 }
 
+// MoveTodoRequest positions a todo after AfterID and before BeforeID. One of
+// them may be left out.
+type MoveTodoRequest struct {
+	AfterID  string `json:"after_id,omitempty"`
+	BeforeID string `json:"before_id,omitempty"`
+}
+
+// AssignTodoRequest names the user to assign; an empty AssigneeID unassigns.
+type AssignTodoRequest struct {
+	AssigneeID string `json:"assignee_id"`
+}
+
+type AddBlockerRequest struct {
+	BlockerID string `json:"blocker_id"`
+}
+
+// BulkTodosRequest applies Action to the todos in IDs. Without IDs it applies
+// to every todo matching the list filters in the query string.
+type BulkTodosRequest struct {
+	Action    string   `json:"action"`
+	IDs       []string `json:"ids,omitempty"`
+	ProjectID string   `json:"project_id,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+type BulkTodosResponse struct {
+	Results []service.BulkResult `json:"results"`
+}
+
+// TodoHistoryResponse lists a todo's revisions newest first.
+type TodoHistoryResponse struct {
+	Revisions []*domain.TodoRevision `json:"revisions"`
+}
+
+type SearchResultsResponse struct {
+	Results []*domain.SearchResult `json:"results"`
+}
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
 type TodoResponse struct {
 	Todo domain.Todo `json:"todo"`
 }
 
 type TodosResponse struct {
-	Todos []*domain.Todo `json:"todos"`
+	Todos      []*domain.Todo `json:"todos"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 func (h *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -62,8 +124,22 @@ func (h *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.todoService.Create(claims.UserID, req.Title, req.Description)
+	todo, err := h.todoService.Create(r.Context(), claims.UserID, service.CreateTodoParams{
+		ProjectID:   req.ProjectID,
+		Title:       req.Title,
+		Description: req.Description,
+		Priority:    req.Priority,
+		DueDate:     req.DueDate,
+		DueTime:     req.DueTime,
+		DueTimezone: req.DueTimezone,
+		Tags:        req.Tags,
+		Recurrence:  req.Recurrence,
+	})
 	if err != nil {
+		if isInvalidTodoInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		h.logger.Error("Failed to create todo", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -81,8 +157,28 @@ func (h *TodoHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todos, err := h.todoService.List(claims.UserID, claims.Role)
+	filter, err := parseTodoFilter(r, claims.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := parsePageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	todos, next, err := h.todoService.List(claims.UserID, claims.Role, filter, page)
 	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		h.logger.Error("Failed to get todos", "error", err, "user_id", claims.UserID)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -90,7 +186,48 @@ func (h *TodoHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.Info("Todos listed", "user_id", claims.UserID, "count", len(todos))
 
-	writeJsonResponse(w, http.StatusOK, TodosResponse{Todos: todos}, h.logger)
+	writeJsonResponse(w, http.StatusOK, TodosResponse{Todos: todos, NextCursor: next}, h.logger)
+}
+
+// Search handles GET /api/todos/search?q=...&limit=N.
+func (h *TodoHandler) Search(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := parseSearchLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.todoService.Search(claims.UserID, claims.Role, query, limit)
+	if err != nil {
+		h.logger.Error("Failed to search todos", "error", err, "user_id", claims.UserID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, SearchResultsResponse{Results: results}, h.logger)
+}
+
+func parseSearchLimit(v string) (int, error) {
+	if v == "" {
+		return defaultSearchLimit, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > maxSearchLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxSearchLimit)
+	}
+	return limit, nil
 }
 
 func (h *TodoHandler) GetByID(w http.ResponseWriter, r *http.Request) {
@@ -144,12 +281,31 @@ func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.todoService.Update(todoID, claims.UserID, claims.Role, req.Title, req.Description, req.Completed)
+	todo, err := h.todoService.Update(r.Context(), todoID, claims.UserID, claims.Role, service.UpdateTodoParams{
+		ProjectID:   req.ProjectID,
+		Title:       req.Title,
+		Description: req.Description,
+		Completed:   req.Completed,
+		Priority:    req.Priority,
+		DueDate:     req.DueDate,
+		DueTime:     req.DueTime,
+		DueTimezone: req.DueTimezone,
+		Tags:        req.Tags,
+		Recurrence:  req.Recurrence,
+	})
 	if err != nil {
 		if errors.Is(err, domain.ErrTodoNotFound) {
 			http.Error(w, "Todo not found", http.StatusNotFound)
 			return
 		}
+		if isInvalidTodoInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domain.ErrTodoBlocked) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		if errors.Is(err, service.ErrForbidden) {
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
@@ -164,6 +320,239 @@ func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
 	writeJsonResponse(w, http.StatusOK, TodoResponse{Todo: *todo}, h.logger)
 }
 
+func (h *TodoHandler) Move(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	if todoID == "" {
+		http.Error(w, "Todo ID required", http.StatusBadRequest)
+		return
+	}
+
+	var req MoveTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	todo, err := h.todoService.Move(r.Context(), todoID, claims.UserID, claims.Role, req.AfterID, req.BeforeID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidPosition) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h.logger.Error("Failed to move todo", "error", err, "todo_id", todoID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, TodoResponse{Todo: *todo}, h.logger)
+}
+
+func (h *TodoHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	if todoID == "" {
+		http.Error(w, "Todo ID required", http.StatusBadRequest)
+		return
+	}
+
+	var req AssignTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	todo, err := h.todoService.Assign(r.Context(), todoID, claims.UserID, claims.Role, req.AssigneeID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrUserNotFound) {
+			http.Error(w, "Assignee not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h.logger.Error("Failed to assign todo", "error", err, "todo_id", todoID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Todo assigned", "todo_id", todoID, "assignee_id", req.AssigneeID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusOK, TodoResponse{Todo: *todo}, h.logger)
+}
+
+// Blockers lists the todos that must be done before this one.
+func (h *TodoHandler) Blockers(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	blockers, err := h.todoService.Blockers(todoID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeDependencyError(w, err, "Failed to list blockers", todoID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, TodosResponse{Todos: blockers}, h.logger)
+}
+
+func (h *TodoHandler) AddBlocker(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	var req AddBlockerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	todo, err := h.todoService.AddBlocker(r.Context(), todoID, req.BlockerID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeDependencyError(w, err, "Failed to add blocker", todoID)
+		return
+	}
+
+	h.logger.Info("Blocker added", "todo_id", todoID, "blocker_id", req.BlockerID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusOK, TodoResponse{Todo: *todo}, h.logger)
+}
+
+func (h *TodoHandler) RemoveBlocker(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	blockerID := chi.URLParam(r, "blockerID")
+
+	if err := h.todoService.RemoveBlocker(r.Context(), todoID, blockerID, claims.UserID, claims.Role); err != nil {
+		h.writeDependencyError(w, err, "Failed to remove blocker", todoID)
+		return
+	}
+
+	h.logger.Info("Blocker removed", "todo_id", todoID, "blocker_id", blockerID, "user_id", claims.UserID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// History lists how a todo changed, one revision per update.
+func (h *TodoHandler) History(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+
+	revisions, err := h.todoService.History(todoID, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeRevisionError(w, err, "Failed to get todo history", todoID)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, TodoHistoryResponse{Revisions: revisions}, h.logger)
+}
+
+// Revert restores a todo to the state it had at revision {rev}.
+func (h *TodoHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	rev, err := strconv.Atoi(chi.URLParam(r, "rev"))
+	if err != nil || rev < 1 {
+		http.Error(w, "Revision must be a positive number", http.StatusBadRequest)
+		return
+	}
+
+	todo, err := h.todoService.Revert(r.Context(), todoID, rev, claims.UserID, claims.Role)
+	if err != nil {
+		h.writeRevisionError(w, err, "Failed to revert todo", todoID)
+		return
+	}
+
+	h.logger.Info("Todo reverted", "todo_id", todoID, "revision", rev, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusOK, TodoResponse{Todo: *todo}, h.logger)
+}
+
+func (h *TodoHandler) writeRevisionError(w http.ResponseWriter, err error, msg, todoID string) {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound):
+		http.Error(w, "Todo not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrRevisionNotFound):
+		http.Error(w, "Revision not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case isInvalidTodoInput(err):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrTodoBlocked):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.Error(msg, "error", err, "todo_id", todoID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func (h *TodoHandler) writeDependencyError(w http.ResponseWriter, err error, msg, todoID string) {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound):
+		http.Error(w, "Todo not found", http.StatusNotFound)
+	case errors.Is(err, domain.ErrDependencyNotFound):
+		http.Error(w, "Blocker not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, domain.ErrInvalidDependency):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrDependencyCycle):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.Error(msg, "error", err, "todo_id", todoID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
 func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	claims, ok := auth.GetClaims(r.Context())
 	if !ok {
@@ -177,7 +566,17 @@ func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.todoService.Delete(todoID, claims.UserID, claims.Role)
+	// ?hard=true deletes permanently instead of moving the todo to the trash
+	hard := false
+	if v := r.URL.Query().Get("hard"); v != "" {
+		var err error
+		if hard, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "hard must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := h.todoService.Delete(r.Context(), todoID, claims.UserID, claims.Role, hard)
 	if err != nil {
 		if errors.Is(err, domain.ErrTodoNotFound) {
 			http.Error(w, "Todo not found", http.StatusNotFound)
@@ -192,7 +591,342 @@ func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.logger.Info("Todo deleted", "todo_id", todoID, "user_id", claims.UserID)
+	h.logger.Info("Todo deleted", "todo_id", todoID, "user_id", claims.UserID, "hard", hard)
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Trash handles GET /api/todos/trash, listing deleted todos newest first.
+func (h *TodoHandler) Trash(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	page, err := parsePageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	todos, next, err := h.todoService.Trash(claims.UserID, claims.Role, page)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to get trash", "error", err, "user_id", claims.UserID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, TodosResponse{Todos: todos, NextCursor: next}, h.logger)
+}
+
+// Restore handles POST /api/todos/{id}/restore.
+func (h *TodoHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	if todoID == "" {
+		http.Error(w, "Todo ID required", http.StatusBadRequest)
+		return
+	}
+
+	todo, err := h.todoService.Restore(r.Context(), todoID, claims.UserID, claims.Role)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found in trash", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h.logger.Error("Failed to restore todo", "error", err, "todo_id", todoID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Todo restored", "todo_id", todoID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusOK, TodoResponse{Todo: *todo}, h.logger)
+}
+
+// Archive handles GET /api/todos/archive.
+func (h *TodoHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	page, err := parsePageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	todos, next, err := h.todoService.Archive(claims.UserID, claims.Role, page)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to get archive", "error", err, "user_id", claims.UserID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	writeJsonResponse(w, http.StatusOK, TodosResponse{Todos: todos, NextCursor: next}, h.logger)
+}
+
+// Unarchive handles POST /api/todos/{id}/unarchive.
+func (h *TodoHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	if todoID == "" {
+		http.Error(w, "Todo ID required", http.StatusBadRequest)
+		return
+	}
+
+	todo, err := h.todoService.Unarchive(r.Context(), todoID, claims.UserID, claims.Role)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found in archive", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h.logger.Error("Failed to unarchive todo", "error", err, "todo_id", todoID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Todo unarchived", "todo_id", todoID, "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusOK, TodoResponse{Todo: *todo}, h.logger)
+}
+
+// Bulk handles POST /api/todos/bulk.
+func (h *TodoHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req BulkTodosRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("Invalid request body", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	params := service.BulkParams{
+		Action:    service.BulkAction(req.Action),
+		IDs:       req.IDs,
+		ProjectID: req.ProjectID,
+		Tags:      req.Tags,
+	}
+	if len(req.IDs) == 0 {
+		// An empty filter would sweep up every todo, so one must be given
+		if r.URL.RawQuery == "" {
+			http.Error(w, "ids or a filter is required", http.StatusBadRequest)
+			return
+		}
+		filter, err := parseTodoFilter(r, claims.UserID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		params.Filter = &filter
+	}
+
+	results, err := h.todoService.Bulk(r.Context(), claims.UserID, claims.Role, params)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) || errors.Is(err, domain.ErrInvalidTag) || errors.Is(err, domain.ErrInvalidProject) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h.logger.Error("Failed to apply bulk action", "error", err, "action", req.Action, "user_id", claims.UserID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Bulk action applied", "action", req.Action, "count", len(results), "user_id", claims.UserID)
+
+	writeJsonResponse(w, http.StatusOK, BulkTodosResponse{Results: results}, h.logger)
+}
+
+// parseTodoFilter reads the list filters from the query string:
+//
+//	project             todos in one project
+//	user_id             todos of one user (admins only)
+//	created_by=me       todos the caller owns
+//	assignee            todos assigned to a user; "me" for the caller
+//	completed=true|false
+//	title               todos whose title contains the text, ignoring case
+//	due=overdue|today   open todos past due, or todos due today
+//	due_before, due_after           RFC 3339 timestamps or YYYY-MM-DD dates
+//	created_before, created_after   likewise
+//	updated_before, updated_after   likewise
+//	tz                  IANA timezone used for "today" and bare dates (default UTC)
+//	tag                 repeatable; todos carrying any of the tags
+//	tag_mode=any|all    require all of the tags instead
+//	include_archived=true           list archived todos too
+//	shared=true         todos other users shared with the caller instead of their own
+//	sort=manual|created|priority|due, or fields such as -updated_at,title
+func parseTodoFilter(r *http.Request, requestingUserID string) (domain.TodoFilter, error) {
+	var filter domain.TodoFilter
+	q := r.URL.Query()
+
+	filter.ProjectID = q.Get("project")
+	filter.UserID = q.Get("user_id")
+	filter.AssigneeID = q.Get("assignee")
+	if filter.AssigneeID == "me" {
+		filter.AssigneeID = requestingUserID
+	}
+	filter.TitleContains = strings.TrimSpace(q.Get("title"))
+
+	if v := q.Get("created_by"); v != "" {
+		if v != "me" {
+			return filter, fmt.Errorf("created_by must be me; use user_id for other users")
+		}
+		if filter.UserID != "" && filter.UserID != requestingUserID {
+			return filter, fmt.Errorf("created_by and user_id conflict")
+		}
+		filter.UserID = requestingUserID
+	}
+
+	if v := q.Get("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("completed must be true or false")
+		}
+		filter.Completed = &completed
+	}
+
+	if v := q.Get("include_archived"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("include_archived must be true or false")
+		}
+		filter.IncludeArchived = include
+	}
+
+	if v := q.Get("shared"); v != "" {
+		shared, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("shared must be true or false")
+		}
+		filter.Shared = shared
+	}
+
+	// The named orderings win; anything else is read as a field list
+	if sort, err := domain.ParseTodoSort(q.Get("sort")); err == nil {
+		filter.Sort = sort
+	} else {
+		fields, err := domain.ParseTodoSortFields(q.Get("sort"))
+		if err != nil {
+			return filter, err
+		}
+		filter.SortFields = fields
+	}
+
+	loc := time.UTC
+	if tz := q.Get("tz"); tz != "" {
+		var err error
+		loc, err = time.LoadLocation(tz)
+		if err != nil {
+			return filter, fmt.Errorf("unknown timezone %q", tz)
+		}
+	}
+
+	switch q.Get("due") {
+	case "":
+	case "overdue":
+		filter.Overdue = true
+	case "today":
+		filter.DueOn = time.Now().In(loc).Format(domain.DueDateLayout)
+	default:
+		return filter, fmt.Errorf("due must be one of: overdue, today")
+	}
+
+	for _, tag := range q["tag"] {
+		name, err := domain.NormalizeTagName(tag)
+		if err != nil {
+			return filter, err
+		}
+		filter.Tags = append(filter.Tags, name)
+	}
+	switch q.Get("tag_mode") {
+	case "", "any":
+	case "all":
+		filter.MatchAllTags = true
+	default:
+		return filter, fmt.Errorf("tag_mode must be one of: any, all")
+	}
+
+	bounds := []struct {
+		param string
+		dest  **time.Time
+	}{
+		{"due_before", &filter.DueBefore},
+		{"due_after", &filter.DueAfter},
+		{"created_before", &filter.CreatedBefore},
+		{"created_after", &filter.CreatedAfter},
+		{"updated_before", &filter.UpdatedBefore},
+		{"updated_after", &filter.UpdatedAfter},
+	}
+	for _, bound := range bounds {
+		if v := q.Get(bound.param); v != "" {
+			t, err := parseTimeParam(v, loc)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: %w", bound.param, err)
+			}
+			*bound.dest = &t
+		}
+	}
+
+	return filter, nil
+}
+
+// isInvalidTodoInput reports whether err stems from a rejected todo field
+// rather than a server-side failure.
+func isInvalidTodoInput(err error) bool {
+	return errors.Is(err, domain.ErrInvalidDueDate) ||
+		errors.Is(err, domain.ErrInvalidPriority) ||
+		errors.Is(err, domain.ErrInvalidTag) ||
+		errors.Is(err, domain.ErrInvalidProject) ||
+		errors.Is(err, domain.ErrInvalidRecurrence)
+}
+
+// parseTimeParam accepts an RFC 3339 timestamp or a YYYY-MM-DD date, which is
+// taken as midnight in loc.
+func parseTimeParam(v string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(domain.DueDateLayout, v, loc)
+	if err != nil {
+		return time.Time{}, errors.New("expected RFC 3339 timestamp or YYYY-MM-DD")
+	}
+	return t, nil
+}
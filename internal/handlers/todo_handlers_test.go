@@ -3,7 +3,9 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"godo/internal/auth"
 	"godo/internal/domain"
 	"godo/internal/service"
@@ -14,6 +16,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -24,7 +27,7 @@ func setupTodoTestHandler(t *testing.T) (*TodoHandler, *store.UserRepo, *store.T
 	db := testutil.SetupTestDB(t)
 	userRepo := store.NewUserRepo(db)
 	todoRepo := store.NewTodoRepo(db)
-	todoService := service.NewTodoService(todoRepo)
+	todoService := newTestTodoService(db)
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
@@ -33,6 +36,21 @@ func setupTodoTestHandler(t *testing.T) (*TodoHandler, *store.UserRepo, *store.T
 	return handler, userRepo, todoRepo
 }
 
+func newTestTodoService(db *sql.DB) *service.TodoService {
+	repos := domain.Repos{
+		Todos:        store.NewTodoRepo(db),
+		Tags:         store.NewTagRepo(db),
+		Subtasks:     store.NewSubtaskRepo(db),
+		Projects:     store.NewProjectRepo(db),
+		Shares:       store.NewShareRepo(db),
+		Users:        store.NewUserRepo(db),
+		Dependencies: store.NewDependencyRepo(db),
+		Audit:        store.NewAuditRepo(db),
+		Revisions:    store.NewRevisionRepo(db),
+	}
+	return service.NewTodoService(repos, store.NewTransactor(db), service.DefaultDeletePolicy(), service.CompletionPolicy{})
+}
+
 func createTestUser(t *testing.T, userRepo *store.UserRepo, role string) *domain.User {
 	t.Helper()
 	user := &domain.User{
@@ -259,6 +277,136 @@ func TestList_Success_Admin(t *testing.T) {
 	}
 }
 
+func TestList_Paginates(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	for i := 0; i < 3; i++ {
+		createTestTodo(t, todoRepo, user.ID)
+	}
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	seen := make(map[string]bool)
+	cursor := ""
+	for _, expected := range []int{2, 1} {
+		req := httptest.NewRequest(http.MethodGet, "/api/todos?limit=2&cursor="+cursor, nil)
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+
+		var resp TodosResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if len(resp.Todos) != expected {
+			t.Errorf("Expected %d todos, got %d", expected, len(resp.Todos))
+		}
+		for _, todo := range resp.Todos {
+			if seen[todo.ID] {
+				t.Errorf("Todo %s returned on two pages", todo.ID)
+			}
+			seen[todo.ID] = true
+		}
+		cursor = resp.NextCursor
+	}
+
+	if cursor != "" {
+		t.Errorf("Expected no next_cursor on the last page, got %q", cursor)
+	}
+}
+
+func TestList_InvalidPage(t *testing.T) {
+	handler, userRepo, _ := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	for _, target := range []string{"/api/todos?cursor=bogus", "/api/todos?limit=0", "/api/todos?limit=1000"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", target, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
+
+func TestList_QueryFilters(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	admin := createTestUser(t, userRepo, domain.RoleAdmin)
+
+	groceries := domain.NewTodo(user.ID, "Buy groceries", "")
+	groceries.Completed = true
+	todoRepo.Create(groceries)
+	todoRepo.Create(domain.NewTodo(user.ID, "Call mom", ""))
+	todoRepo.Create(domain.NewTodo(admin.ID, "Buy stamps", ""))
+
+	tests := []struct {
+		name     string
+		role     string
+		target   string
+		status   int
+		expected int
+	}{
+		{"completed", domain.RoleUser, "/api/todos?completed=true", http.StatusOK, 1},
+		{"title", domain.RoleAdmin, "/api/todos?title=buy", http.StatusOK, 2},
+		{"admin by user", domain.RoleAdmin, "/api/todos?user_id=" + user.ID + "&sort=-title", http.StatusOK, 2},
+		{"user by other user", domain.RoleUser, "/api/todos?user_id=" + admin.ID, http.StatusForbidden, 0},
+		{"bad completed", domain.RoleUser, "/api/todos?completed=maybe", http.StatusBadRequest, 0},
+		{"bad sort field", domain.RoleUser, "/api/todos?sort=-password", http.StatusBadRequest, 0},
+		{"bad date", domain.RoleUser, "/api/todos?created_after=yesterday", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: tt.role}
+		if tt.role == domain.RoleAdmin {
+			claims = &auth.Claims{UserID: admin.ID, Email: admin.Email, Role: tt.role}
+		}
+
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rec.Code)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+
+		var resp TodosResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(resp.Todos) != tt.expected {
+			t.Errorf("%s: expected %d todos, got %d", tt.name, tt.expected, len(resp.Todos))
+		}
+	}
+}
+
 func TestList_Unauthorized(t *testing.T) {
 	handler, _, _ := setupTodoTestHandler(t)
 
@@ -581,7 +729,7 @@ func TestDelete_Success_Admin(t *testing.T) {
 	}
 }
 
-func TestDelete_Forbidden_User(t *testing.T) {
+func TestDelete_Success_Owner(t *testing.T) {
 	handler, userRepo, todoRepo := setupTodoTestHandler(t)
 
 	user := createTestUser(t, userRepo, domain.RoleUser)
@@ -599,6 +747,34 @@ func TestDelete_Forbidden_User(t *testing.T) {
 
 	handler.Delete(rec, req)
 
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+
+	if _, err := todoRepo.GetTrashedByID(todo.ID); err != nil {
+		t.Errorf("Expected todo to be in the trash, got error: %v", err)
+	}
+}
+
+func TestDelete_Forbidden_OtherUser(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	owner := createTestUser(t, userRepo, domain.RoleUser)
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, owner.ID)
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/todos/"+todo.ID, nil)
+	req = requestWithClaimsAndID(req, claims, "id", todo.ID)
+	rec := httptest.NewRecorder()
+
+	handler.Delete(rec, req)
+
 	if rec.Code != http.StatusForbidden {
 		t.Errorf("Expected status %d, got %d", http.StatusForbidden, rec.Code)
 	}
@@ -609,6 +785,41 @@ func TestDelete_Forbidden_User(t *testing.T) {
 	}
 }
 
+func TestDelete_Hard(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	admin := createTestUser(t, userRepo, domain.RoleAdmin)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	todoRepo.Delete(todo.ID)
+
+	tests := []struct {
+		name   string
+		claims *auth.Claims
+		status int
+	}{
+		// By default only admins may delete permanently
+		{"owner", &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}, http.StatusForbidden},
+		{"admin", &auth.Claims{UserID: admin.ID, Email: admin.Email, Role: domain.RoleAdmin}, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodDelete, "/api/todos/"+todo.ID+"?hard=true", nil)
+		req = requestWithClaimsAndID(req, tt.claims, "id", todo.ID)
+		rec := httptest.NewRecorder()
+
+		handler.Delete(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rec.Code)
+		}
+	}
+
+	if _, err := todoRepo.GetTrashedByID(todo.ID); err != domain.ErrTodoNotFound {
+		t.Errorf("Expected todo to be gone from the trash, got error: %v", err)
+	}
+}
+
 func TestDelete_NotFound(t *testing.T) {
 	handler, userRepo, _ := setupTodoTestHandler(t)
 
@@ -631,6 +842,69 @@ func TestDelete_NotFound(t *testing.T) {
 	}
 }
 
+func TestTrashAndRestore(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	todoRepo.Delete(todo.ID)
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/todos/trash", nil)
+	req = requestWithClaims(req, claims)
+	rec := httptest.NewRecorder()
+
+	handler.Trash(rec, req)
+
+	var resp TodosResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Todos) != 1 || resp.Todos[0].ID != todo.ID {
+		t.Fatalf("Expected the deleted todo in the trash, got %d todos", len(resp.Todos))
+	}
+
+	otherClaims := &auth.Claims{UserID: other.ID, Email: other.Email, Role: domain.RoleUser}
+	req = httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/restore", nil)
+	req = requestWithClaimsAndID(req, otherClaims, "id", todo.ID)
+	rec = httptest.NewRecorder()
+
+	handler.Restore(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for another user, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/restore", nil)
+	req = requestWithClaimsAndID(req, claims, "id", todo.ID)
+	rec = httptest.NewRecorder()
+
+	handler.Restore(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if _, err := todoRepo.GetByID(todo.ID); err != nil {
+		t.Errorf("Expected todo to be restored, got error: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/restore", nil)
+	req = requestWithClaimsAndID(req, claims, "id", todo.ID)
+	rec = httptest.NewRecorder()
+
+	handler.Restore(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d restoring twice, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
 func TestDelete_Unauthorized(t *testing.T) {
 	handler, _, _ := setupTodoTestHandler(t)
 
@@ -643,3 +917,774 @@ func TestDelete_Unauthorized(t *testing.T) {
 		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, rec.Code)
 	}
 }
+
+func TestCreate_WithDueDate(t *testing.T) {
+	handler, userRepo, _ := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	reqBody := CreateTodoRequest{
+		Title:       "Dentist",
+		DueDate:     "2030-03-01",
+		DueTime:     "15:00",
+		DueTimezone: "Europe/Berlin",
+	}
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/todos", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = requestWithClaims(req, claims)
+	rec := httptest.NewRecorder()
+
+	handler.Create(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var resp TodoResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if resp.Todo.Due == nil {
+		t.Fatal("Expected due date in response")
+	}
+
+	// 15:00 in Berlin (CET, UTC+1) is 14:00 UTC
+	if got := resp.Todo.Due.At.UTC().Format(time.RFC3339); got != "2030-03-01T14:00:00Z" {
+		t.Errorf("Expected due instant 2030-03-01T14:00:00Z, got %s", got)
+	}
+}
+
+func TestCreate_InvalidDueDate(t *testing.T) {
+	handler, userRepo, _ := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	for _, reqBody := range []CreateTodoRequest{
+		{Title: "Bad date", DueDate: "03/01/2030"},
+		{Title: "Bad time", DueDate: "2030-03-01", DueTime: "3pm"},
+		{Title: "Bad zone", DueDate: "2030-03-01", DueTimezone: "Mars/Olympus"},
+	} {
+		body, _ := json.Marshal(reqBody)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/todos", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", reqBody.Title, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
+
+func TestUpdate_ClearDueDate(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	todo := domain.NewTodo(user.ID, "Scheduled", "")
+	todo.Due, _ = domain.NewDue("2030-01-01", "10:00", "UTC")
+	if err := todoRepo.Create(todo); err != nil {
+		t.Fatalf("Failed to create test todo: %v", err)
+	}
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	body := []byte(`{"due_date": ""}`)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/todos/"+todo.ID, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = requestWithClaimsAndID(req, claims, "id", todo.ID)
+	rec := httptest.NewRecorder()
+
+	handler.Update(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	saved, _ := todoRepo.GetByID(todo.ID)
+	if saved.Due != nil {
+		t.Errorf("Expected due date to be cleared, got %+v", saved.Due)
+	}
+}
+
+func TestList_DueFilters(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+
+	overdue := domain.NewTodo(user.ID, "Overdue", "")
+	overdue.Due, _ = domain.NewDue(time.Now().UTC().AddDate(0, 0, -2).Format(domain.DueDateLayout), "", "")
+	today := domain.NewTodo(user.ID, "Today", "")
+	today.Due, _ = domain.NewDue(time.Now().UTC().Format(domain.DueDateLayout), "", "")
+	todoRepo.Create(overdue)
+	todoRepo.Create(today)
+	createTestTodo(t, todoRepo, user.ID)
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	tests := []struct {
+		query          string
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{query: "due=overdue", expectedStatus: http.StatusOK, expectedIDs: []string{overdue.ID}},
+		{query: "due=today", expectedStatus: http.StatusOK, expectedIDs: []string{today.ID}},
+		{query: "due_after=" + time.Now().UTC().AddDate(0, 0, -1).Format(domain.DueDateLayout), expectedStatus: http.StatusOK, expectedIDs: []string{today.ID}},
+		{query: "due=someday", expectedStatus: http.StatusBadRequest},
+		{query: "due_before=tomorrow", expectedStatus: http.StatusBadRequest},
+		{query: "due=today&tz=Nowhere/Special", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/todos?"+tt.query, nil)
+			req = requestWithClaims(req, claims)
+			rec := httptest.NewRecorder()
+
+			handler.List(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var resp TodosResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if len(resp.Todos) != len(tt.expectedIDs) {
+				t.Fatalf("Expected %d todos, got %d", len(tt.expectedIDs), len(resp.Todos))
+			}
+			for i, id := range tt.expectedIDs {
+				if resp.Todos[i].ID != id {
+					t.Errorf("Expected todo %s, got %s", id, resp.Todos[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestCreate_WithPriority(t *testing.T) {
+	handler, userRepo, _ := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	tests := []struct {
+		priority       string
+		expectedStatus int
+	}{
+		{priority: "high", expectedStatus: http.StatusCreated},
+		{priority: "", expectedStatus: http.StatusCreated},
+		{priority: "critical", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		body, _ := json.Marshal(CreateTodoRequest{Title: "Prioritised", Priority: tt.priority})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/todos", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		if rec.Code != tt.expectedStatus {
+			t.Errorf("priority %q: expected status %d, got %d", tt.priority, tt.expectedStatus, rec.Code)
+			continue
+		}
+		if rec.Code != http.StatusCreated {
+			continue
+		}
+
+		var raw map[string]map[string]any
+		if err := json.NewDecoder(rec.Body).Decode(&raw); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		want := tt.priority
+		if want == "" {
+			want = "none"
+		}
+		if raw["todo"]["priority"] != want {
+			t.Errorf("Expected priority %q, got %v", want, raw["todo"]["priority"])
+		}
+	}
+}
+
+func TestList_InvalidSort(t *testing.T) {
+	handler, userRepo, _ := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/todos?sort=alphabetical", nil)
+	req = requestWithClaims(req, claims)
+	rec := httptest.NewRecorder()
+
+	handler.List(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestCreate_WithTags_AndFilter(t *testing.T) {
+	handler, userRepo, _ := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	for _, reqBody := range []CreateTodoRequest{
+		{Title: "Report", Tags: []string{"Work", "urgent", "work"}},
+		{Title: "Groceries", Tags: []string{"home"}},
+	} {
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest(http.MethodPost, "/api/todos", bytes.NewBuffer(body))
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, rec.Code)
+		}
+
+		var resp TodoResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if reqBody.Title == "Report" && (len(resp.Todo.Tags) != 2 || resp.Todo.Tags[0] != "urgent" || resp.Todo.Tags[1] != "work") {
+			t.Errorf("Expected tags [urgent work], got %v", resp.Todo.Tags)
+		}
+	}
+
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{query: "tag=work", expected: 1},
+		{query: "tag=work&tag=home", expected: 2},
+		{query: "tag=work&tag=home&tag_mode=all", expected: 0},
+		{query: "tag=work&tag=urgent&tag_mode=all", expected: 1},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/todos?"+tt.query, nil)
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		var resp TodosResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.query, err)
+		}
+		if len(resp.Todos) != tt.expected {
+			t.Errorf("%s: expected %d todos, got %d", tt.query, tt.expected, len(resp.Todos))
+		}
+	}
+}
+
+func TestRecurrence_CreateAndComplete(t *testing.T) {
+	handler, userRepo, _ := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	for _, rule := range []string{"FREQ=HOURLY", "FREQ=MONTHLY;BYDAY=MO", "FREQ=DAILY;COUNT=2;UNTIL=20300101", "INTERVAL=2"} {
+		body, _ := json.Marshal(CreateTodoRequest{Title: "Bad rule", Recurrence: rule})
+		req := httptest.NewRequest(http.MethodPost, "/api/todos", bytes.NewBuffer(body))
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+
+		handler.Create(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", rule, http.StatusBadRequest, rec.Code)
+		}
+	}
+
+	body, _ := json.Marshal(CreateTodoRequest{Title: "Trash day", DueDate: "2030-01-07", Recurrence: "FREQ=WEEKLY"})
+	req := httptest.NewRequest(http.MethodPost, "/api/todos", bytes.NewBuffer(body))
+	req = requestWithClaims(req, claims)
+	rec := httptest.NewRecorder()
+
+	handler.Create(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, rec.Code)
+	}
+
+	var created struct {
+		Todo struct {
+			ID         string `json:"id"`
+			Recurrence string `json:"recurrence"`
+		} `json:"todo"`
+	}
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.Todo.Recurrence != "FREQ=WEEKLY" {
+		t.Errorf("Expected recurrence FREQ=WEEKLY, got %q", created.Todo.Recurrence)
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/api/todos/"+created.Todo.ID, bytes.NewBufferString(`{"completed": true}`))
+	req = requestWithClaimsAndID(req, claims, "id", created.Todo.ID)
+	rec = httptest.NewRecorder()
+
+	handler.Update(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var resp TodoResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Todo.NextOccurrence == nil || resp.Todo.NextOccurrence.Due.Date != "2030-01-14" {
+		t.Errorf("Expected next occurrence due 2030-01-14, got %+v", resp.Todo.NextOccurrence)
+	}
+}
+
+func TestMove(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	first := createTestTodo(t, todoRepo, user.ID)
+	second := createTestTodo(t, todoRepo, user.ID)
+
+	tests := []struct {
+		name           string
+		userID         string
+		body           MoveTodoRequest
+		expectedStatus int
+	}{
+		{name: "missing neighbours", userID: user.ID, body: MoveTodoRequest{}, expectedStatus: http.StatusBadRequest},
+		{name: "not the owner", userID: other.ID, body: MoveTodoRequest{BeforeID: second.ID}, expectedStatus: http.StatusForbidden},
+		{name: "to the top", userID: user.ID, body: MoveTodoRequest{BeforeID: second.ID}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &auth.Claims{UserID: tt.userID, Role: domain.RoleUser}
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/api/todos/"+first.ID+"/move", bytes.NewBuffer(body))
+			req = requestWithClaimsAndID(req, claims, "id", first.ID)
+			rec := httptest.NewRecorder()
+
+			handler.Move(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+
+	todos, _, _ := todoRepo.GetByUserID(user.ID, domain.TodoFilter{}, domain.PageRequest{})
+	if todos[0].ID != first.ID {
+		t.Errorf("Expected the moved todo to be listed first")
+	}
+}
+
+func TestSearch_Visibility(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	admin := createTestUser(t, userRepo, domain.RoleAdmin)
+	todoRepo.Create(domain.NewTodo(user.ID, "Quarterly report", ""))
+	todoRepo.Create(domain.NewTodo(other.ID, "Annual report", ""))
+
+	tests := []struct {
+		name           string
+		user           *domain.User
+		query          string
+		expectedStatus int
+		expected       int
+	}{
+		{name: "own todos only", user: user, query: "q=report", expectedStatus: http.StatusOK, expected: 1},
+		{name: "admin sees all", user: admin, query: "q=report", expectedStatus: http.StatusOK, expected: 2},
+		{name: "missing query", user: user, query: "q=+", expectedStatus: http.StatusBadRequest},
+		{name: "bad limit", user: user, query: "q=report&limit=0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &auth.Claims{UserID: tt.user.ID, Email: tt.user.Email, Role: tt.user.Role}
+			req := httptest.NewRequest(http.MethodGet, "/api/todos/search?"+tt.query, nil)
+			req = requestWithClaims(req, claims)
+			rec := httptest.NewRecorder()
+
+			handler.Search(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var resp SearchResultsResponse
+			json.NewDecoder(rec.Body).Decode(&resp)
+			if len(resp.Results) != tt.expected {
+				t.Errorf("Expected %d results, got %d", tt.expected, len(resp.Results))
+			}
+		})
+	}
+}
+
+func TestArchiveAndUnarchive(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	user.AutoArchiveDays = 1
+	userRepo.Update(user)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	completedAt := time.Now().Add(-48 * time.Hour)
+	todo.Completed = true
+	todo.CompletedAt = &completedAt
+	todo.UpdatedAt = completedAt
+	todoRepo.Update(todo)
+	todoRepo.ArchiveCompleted(time.Now())
+
+	claims := &auth.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   domain.RoleUser,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/todos/archive", nil)
+	req = requestWithClaims(req, claims)
+	rec := httptest.NewRecorder()
+
+	handler.Archive(rec, req)
+
+	var resp TodosResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Todos) != 1 || resp.Todos[0].ID != todo.ID {
+		t.Fatalf("Expected the completed todo in the archive, got %d todos", len(resp.Todos))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/todos", nil)
+	req = requestWithClaims(req, claims)
+	rec = httptest.NewRecorder()
+
+	handler.List(rec, req)
+
+	resp = TodosResponse{}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Todos) != 0 {
+		t.Errorf("Expected archived todos to be left out of the list, got %d todos", len(resp.Todos))
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/unarchive", nil)
+	req = requestWithClaimsAndID(req, claims, "id", todo.ID)
+	rec = httptest.NewRecorder()
+
+	handler.Unarchive(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/unarchive", nil)
+	req = requestWithClaimsAndID(req, claims, "id", todo.ID)
+	rec = httptest.NewRecorder()
+
+	handler.Unarchive(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d unarchiving twice, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestAssign(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	owner := createTestUser(t, userRepo, domain.RoleUser)
+	assignee := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, owner.ID)
+	createTestTodo(t, todoRepo, owner.ID)
+
+	ownerClaims := &auth.Claims{UserID: owner.ID, Email: owner.Email, Role: domain.RoleUser}
+	assigneeClaims := &auth.Claims{UserID: assignee.ID, Email: assignee.Email, Role: domain.RoleUser}
+
+	assign := func(assigneeID string, claims *auth.Claims) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(AssignTodoRequest{AssigneeID: assigneeID})
+		req := httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/assign", bytes.NewBuffer(body))
+		req = requestWithClaimsAndID(req, claims, "id", todo.ID)
+		rec := httptest.NewRecorder()
+		handler.Assign(rec, req)
+		return rec
+	}
+
+	if rec := assign("missing-user", ownerClaims); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown assignee, got %d", http.StatusNotFound, rec.Code)
+	}
+	if rec := assign(assignee.ID, assigneeClaims); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d assigning someone else's todo, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	rec := assign(assignee.ID, ownerClaims)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp TodoResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Todo.AssigneeID != assignee.ID || resp.Todo.AssigneeEmail != assignee.Email {
+		t.Errorf("Expected the todo assigned to %s, got %q (%q)", assignee.Email, resp.Todo.AssigneeID, resp.Todo.AssigneeEmail)
+	}
+
+	// Assignees see the todo under assignee=me but not under created_by=me
+	for query, want := range map[string]int{"assignee=me": 1, "created_by=me": 0} {
+		req := httptest.NewRequest(http.MethodGet, "/api/todos?"+query, nil)
+		req = requestWithClaims(req, assigneeClaims)
+		rec := httptest.NewRecorder()
+
+		handler.List(rec, req)
+
+		var list TodosResponse
+		json.NewDecoder(rec.Body).Decode(&list)
+		if len(list.Todos) != want {
+			t.Errorf("Expected %d todos for %s, got %d", want, query, len(list.Todos))
+		}
+	}
+
+	// Assignees may complete the todo but change nothing else
+	update := func(body string) int {
+		req := httptest.NewRequest(http.MethodPatch, "/api/todos/"+todo.ID, bytes.NewBufferString(body))
+		req = requestWithClaimsAndID(req, assigneeClaims, "id", todo.ID)
+		rec := httptest.NewRecorder()
+		handler.Update(rec, req)
+		return rec.Code
+	}
+	if code := update(`{"completed": true}`); code != http.StatusOK {
+		t.Errorf("Expected status %d completing an assigned todo, got %d", http.StatusOK, code)
+	}
+	if code := update(`{"title": "Mine now"}`); code != http.StatusForbidden {
+		t.Errorf("Expected status %d renaming an assigned todo, got %d", http.StatusForbidden, code)
+	}
+}
+
+func TestBlockers(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, user.ID)
+	blocker := createTestTodo(t, todoRepo, user.ID)
+	private := createTestTodo(t, todoRepo, other.ID)
+
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	addBlocker := func(todoID, blockerID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(AddBlockerRequest{BlockerID: blockerID})
+		req := httptest.NewRequest(http.MethodPost, "/api/todos/"+todoID+"/blockers", bytes.NewBuffer(body))
+		req = requestWithClaimsAndID(req, claims, "id", todoID)
+		rec := httptest.NewRecorder()
+		handler.AddBlocker(rec, req)
+		return rec
+	}
+
+	rec := addBlocker(todo.ID, blocker.ID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp TodoResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if !resp.Todo.Blocked {
+		t.Errorf("Expected the todo to be blocked")
+	}
+
+	if rec := addBlocker(blocker.ID, todo.ID); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status %d for a cycle, got %d", http.StatusConflict, rec.Code)
+	}
+	if rec := addBlocker(todo.ID, private.ID); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for someone else's blocker, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/todos/"+todo.ID+"/blockers", nil)
+	req = requestWithClaimsAndID(req, claims, "id", todo.ID)
+	rec = httptest.NewRecorder()
+	handler.Blockers(rec, req)
+
+	var list TodosResponse
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Todos) != 1 || list.Todos[0].ID != blocker.ID {
+		t.Errorf("Expected the blocker in the list, got %d todos", len(list.Todos))
+	}
+
+	ctx := chi.NewRouteContext()
+	ctx.URLParams.Add("id", todo.ID)
+	ctx.URLParams.Add("blockerID", blocker.ID)
+	req = httptest.NewRequest(http.MethodDelete, "/api/todos/"+todo.ID+"/blockers/"+blocker.ID, nil)
+	req = requestWithClaims(req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx)), claims)
+	rec = httptest.NewRecorder()
+	handler.RemoveBlocker(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+}
+
+func TestBulk(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	mine := createTestTodo(t, todoRepo, user.ID)
+	theirs := createTestTodo(t, todoRepo, other.ID)
+
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	bulk := func(query string, body BulkTodosRequest) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/todos/bulk"+query, bytes.NewBuffer(reqBody))
+		req = requestWithClaims(req, claims)
+		rec := httptest.NewRecorder()
+		handler.Bulk(rec, req)
+		return rec
+	}
+
+	rec := bulk("", BulkTodosRequest{Action: "complete", IDs: []string{mine.ID, theirs.ID}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp BulkTodosResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Results) != 2 || resp.Results[0].Status != service.BulkOK || resp.Results[1].Status != service.BulkForbidden {
+		t.Errorf("Expected ok then forbidden, got %+v", resp.Results)
+	}
+
+	// The filter picks up only the caller's own completed todos
+	rec = bulk("?completed=true", BulkTodosRequest{Action: "delete"})
+	resp = BulkTodosResponse{}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Results) != 1 || resp.Results[0].ID != mine.ID || resp.Results[0].Status != service.BulkOK {
+		t.Errorf("Expected the completed todo to be deleted, got %+v", resp.Results)
+	}
+	if _, err := todoRepo.GetByID(mine.ID); !errors.Is(err, domain.ErrTodoNotFound) {
+		t.Errorf("Expected the todo in the trash, got %v", err)
+	}
+
+	for name, body := range map[string]BulkTodosRequest{
+		"unknown action": {Action: "archive", IDs: []string{mine.ID}},
+		"no selection":   {Action: "complete"},
+	} {
+		if rec := bulk("", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", name, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
+
+func TestHistoryAndRevert(t *testing.T) {
+	handler, userRepo, todoRepo := setupTodoTestHandler(t)
+
+	user := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, todoRepo, user.ID)
+
+	claims := &auth.Claims{UserID: user.ID, Email: user.Email, Role: domain.RoleUser}
+
+	newTitle := "Renamed"
+	body, _ := json.Marshal(UpdateTodoRequest{Title: &newTitle})
+	req := httptest.NewRequest(http.MethodPatch, "/api/todos/"+todo.ID, bytes.NewBuffer(body))
+	req = requestWithClaimsAndID(req, claims, "id", todo.ID)
+	rec := httptest.NewRecorder()
+	handler.Update(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/todos/"+todo.ID+"/history", nil)
+	req = requestWithClaimsAndID(req, claims, "id", todo.ID)
+	rec = httptest.NewRecorder()
+	handler.History(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var history TodoHistoryResponse
+	json.NewDecoder(rec.Body).Decode(&history)
+	if len(history.Revisions) != 2 || history.Revisions[0].Changes["title"].After != "Renamed" {
+		t.Fatalf("Expected the title change in the history, got %+v", history.Revisions)
+	}
+
+	revert := func(c *auth.Claims, rev string) *httptest.ResponseRecorder {
+		ctx := chi.NewRouteContext()
+		ctx.URLParams.Add("id", todo.ID)
+		ctx.URLParams.Add("rev", rev)
+		req := httptest.NewRequest(http.MethodPost, "/api/todos/"+todo.ID+"/revert/"+rev, nil)
+		req = requestWithClaims(req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, ctx)), c)
+		rec := httptest.NewRecorder()
+		handler.Revert(rec, req)
+		return rec
+	}
+
+	if rec := revert(claims, "abc"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a malformed revision, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if rec := revert(claims, "7"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing revision, got %d", http.StatusNotFound, rec.Code)
+	}
+	otherClaims := &auth.Claims{UserID: other.ID, Email: other.Email, Role: domain.RoleUser}
+	if rec := revert(otherClaims, "1"); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d for another user, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	rec = revert(claims, "1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp TodoResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Todo.Title != todo.Title {
+		t.Errorf("Expected title %q back, got %q", todo.Title, resp.Todo.Title)
+	}
+}
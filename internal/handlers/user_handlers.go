@@ -25,9 +25,10 @@ func NewUserHandler(userService *service.UserService, logger *slog.Logger) *User
 }
 
 type UpdateUserRequest struct {
-	Email    *string `json:"email,omitempty"`
-	Password *string `json:"password,omitempty"`
-	Role     *string `json:"role,omitempty"`
+	Email           *string `json:"email,omitempty"`
+	Password        *string `json:"password,omitempty"`
+	Role            *string `json:"role,omitempty"`
+	AutoArchiveDays *int    `json:"auto_archive_days,omitempty"`
 }
 
 type UserResponse struct {
@@ -35,7 +36,8 @@ type UserResponse struct {
 }
 
 type UsersResponse struct {
-	Users []*domain.User `json:"users"`
+	Users      []*domain.User `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -45,12 +47,22 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	users, err := h.userService.List(claims.Role)
+	page, err := parsePageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	users, next, err := h.userService.List(claims.Role, page)
 	if err != nil {
 		if errors.Is(err, service.ErrForbidden) {
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		h.logger.Error("Failed to list users", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -58,7 +70,7 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	h.logger.Info("Users listed", "user_id", claims.UserID, "count", len(users))
 
-	writeJsonResponse(w, http.StatusOK, UsersResponse{Users: users}, h.logger)
+	writeJsonResponse(w, http.StatusOK, UsersResponse{Users: users, NextCursor: next}, h.logger)
 }
 
 func (h *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
@@ -112,7 +124,7 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.Update(userID, claims.UserID, claims.Role, req.Email, req.Password, req.Role)
+	user, err := h.userService.Update(r.Context(), userID, claims.UserID, claims.Role, req.Email, req.Password, req.Role, req.AutoArchiveDays)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
 			http.Error(w, "User not found", http.StatusNotFound)
@@ -126,6 +138,10 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Cannot demote the last admin", http.StatusForbidden)
 			return
 		}
+		if errors.Is(err, domain.ErrInvalidSetting) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		h.logger.Error("Failed to update user", "error", err, "user_id", userID)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
@@ -149,7 +165,7 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.userService.Delete(userID, claims.UserID, claims.Role)
+	err := h.userService.Delete(r.Context(), userID, claims.UserID, claims.Role)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
 			http.Error(w, "User not found", http.StatusNotFound)
@@ -20,7 +20,7 @@ func setupUserTestHandler(t *testing.T) (*UserHandler, *store.UserRepo) {
 
 	db := testutil.SetupTestDB(t)
 	userRepo := store.NewUserRepo(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, store.NewAuditRepo(db), store.NewTransactor(db))
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
@@ -74,6 +74,54 @@ func TestUserList_Success_Admin(t *testing.T) {
 	}
 }
 
+// TestUserList_Paginates verifies admins can page through users with next_cursor
+func TestUserList_Paginates(t *testing.T) {
+	handler, userRepo := setupUserTestHandler(t)
+
+	admin := createTestUser(t, userRepo, domain.RoleAdmin)
+	createTestUser(t, userRepo, domain.RoleUser)
+	createTestUser(t, userRepo, domain.RoleUser)
+
+	claims := &auth.Claims{
+		UserID: admin.ID,
+		Email:  admin.Email,
+		Role:   domain.RoleAdmin,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users?limit=2", nil)
+	req = requestWithClaims(req, claims)
+	rec := httptest.NewRecorder()
+
+	handler.List(rec, req)
+
+	var first UsersResponse
+	if err := json.NewDecoder(rec.Body).Decode(&first); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(first.Users) != 2 || first.NextCursor == "" {
+		t.Fatalf("Expected 2 users and a next_cursor, got %d users and %q", len(first.Users), first.NextCursor)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/users?limit=2&cursor="+first.NextCursor, nil)
+	req = requestWithClaims(req, claims)
+	rec = httptest.NewRecorder()
+
+	handler.List(rec, req)
+
+	var second UsersResponse
+	if err := json.NewDecoder(rec.Body).Decode(&second); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(second.Users) != 1 || second.NextCursor != "" {
+		t.Errorf("Expected the last user without a next_cursor, got %d users and %q", len(second.Users), second.NextCursor)
+	}
+	for _, u := range first.Users {
+		if u.ID == second.Users[0].ID {
+			t.Errorf("User %s returned on both pages", u.ID)
+		}
+	}
+}
+
 // TestList_Forbidden_User verifies regular users cannot list users
 func TestUserList_Forbidden_User(t *testing.T) {
 	handler, userRepo := setupUserTestHandler(t)
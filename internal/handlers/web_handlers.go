@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"time"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"godo/internal/auth"
+	"godo/internal/domain"
 	"godo/internal/service"
 	"godo/web/templates/components"
 	"godo/web/templates/pages"
@@ -13,16 +17,20 @@ import (
 )
 
 type WebHandler struct {
-	authService *service.AuthService
-	todoService *service.TodoService
-	jwtSecret   string
+	authService          *service.AuthService
+	passwordResetService *service.PasswordResetService
+	todoService          *service.TodoService
+	projectService       *service.ProjectService
+	commentService       *service.CommentService
 }
 
-func NewWebHandler(authService *service.AuthService, todoService *service.TodoService, jwtSecret string) *WebHandler {
+func NewWebHandler(authService *service.AuthService, passwordResetService *service.PasswordResetService, todoService *service.TodoService, projectService *service.ProjectService, commentService *service.CommentService) *WebHandler {
 	return &WebHandler{
-		authService: authService,
-		todoService: todoService,
-		jwtSecret:   jwtSecret,
+		authService:          authService,
+		passwordResetService: passwordResetService,
+		todoService:          todoService,
+		projectService:       projectService,
+		commentService:       commentService,
 	}
 }
 
@@ -46,26 +54,164 @@ func (h *WebHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Email, user.Role, h.jwtSecret, 24*time.Hour)
+	session, err := h.authService.StartSession(r.Context(), user)
 	if err != nil {
 		w.Header().Set("Content-Type", "text/html")
 		w.Write([]byte("Something went wrong"))
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "auth_token",
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		MaxAge:   86400, // 24 hours
-	})
+	auth.SetSessionCookies(w, session)
 
 	w.Header().Set("HX-Redirect", "/todos")
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h *WebHandler) ForgotPasswordPage(w http.ResponseWriter, r *http.Request) {
+	pages.ForgotPassword().Render(r.Context(), w)
+}
+
+// ForgotPassword mails a reset link. The answer is the same whether or not
+// an account uses the email.
+func (h *WebHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+
+	err := h.passwordResetService.RequestReset(r.Context(), r.FormValue("email"))
+	if errors.Is(err, service.ErrInvalidInput) {
+		w.Write([]byte("Enter your email"))
+		return
+	}
+	if err != nil {
+		w.Write([]byte("Something went wrong"))
+		return
+	}
+
+	w.Write([]byte("If an account uses that email, a reset link is on its way."))
+}
+
+func (h *WebHandler) ResetPasswordPage(w http.ResponseWriter, r *http.Request) {
+	pages.ResetPassword(r.URL.Query().Get("token")).Render(r.Context(), w)
+}
+
+// ResetPassword sets the new password and sends the browser to log in with it.
+func (h *WebHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	err := h.passwordResetService.ResetPassword(r.Context(), r.FormValue("token"), r.FormValue("password"))
+	if err != nil {
+		w.Header().Set("Content-Type", "text/html")
+		switch {
+		case errors.Is(err, service.ErrPasswordTooShort):
+			w.Write([]byte("Password must be at least 8 characters"))
+		case errors.Is(err, service.ErrInvalidResetToken):
+			w.Write([]byte("This reset link is invalid or has expired"))
+		default:
+			w.Write([]byte("Something went wrong"))
+		}
+		return
+	}
+
+	auth.ClearSessionCookies(w)
+	w.Header().Set("HX-Redirect", "/login")
+	w.WriteHeader(http.StatusOK)
+}
+
+// Logout ends the browser's session and sends it to the login page.
+func (h *WebHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var refreshToken string
+	if cookie, err := r.Cookie(auth.RefreshTokenCookie); err == nil {
+		refreshToken = cookie.Value
+	}
+
+	if err := h.authService.Logout(r.Context(), claims, refreshToken); err != nil {
+		http.Error(w, "Failed to log out", http.StatusInternalServerError)
+		return
+	}
+
+	auth.ClearSessionCookies(w)
+	w.Header().Set("HX-Redirect", "/login")
+	w.WriteHeader(http.StatusOK)
+}
+
+// LogoutEverywhere ends all of the user's sessions, this one included.
+func (h *WebHandler) LogoutEverywhere(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authService.LogoutEverywhere(r.Context(), claims.UserID); err != nil {
+		http.Error(w, "Failed to log out", http.StatusInternalServerError)
+		return
+	}
+
+	auth.ClearSessionCookies(w)
+	w.Header().Set("HX-Redirect", "/login")
+	w.WriteHeader(http.StatusOK)
+}
+
+// SessionsPage lists the devices the user is logged in on.
+func (h *WebHandler) SessionsPage(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(claims.UserID, claims.UserID, claims.Role)
+	if err != nil {
+		http.Error(w, "Failed to load sessions", http.StatusInternalServerError)
+		return
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == claims.SessionID
+	}
+
+	pages.Sessions(sessions).Render(r.Context(), w)
+}
+
+// RevokeSession logs one of the user's devices out. When that is this
+// browser, it is sent back to the login page.
+func (h *WebHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "id")
+	err := h.authService.RevokeSession(r.Context(), claims.UserID, sessionID, claims.UserID, claims.Role)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+
+	if sessionID == claims.SessionID {
+		auth.ClearSessionCookies(w)
+		w.Header().Set("HX-Redirect", "/login")
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 func (h *WebHandler) TodosPage(w http.ResponseWriter, r *http.Request) {
 	claims, ok := auth.GetClaims(r.Context())
 	if !ok {
@@ -73,13 +219,77 @@ func (h *WebHandler) TodosPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todos, err := h.todoService.List(claims.UserID, claims.Role)
+	filter, err := parseTodoFilter(r, claims.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Later pages are requested by the infinite scroll sentinel and only
+	// need the rows
+	page := domain.PageRequest{Limit: defaultPageLimit, Cursor: r.URL.Query().Get("cursor")}
+	todos, next, err := h.todoService.List(claims.UserID, claims.Role, filter, page)
+	if errors.Is(err, service.ErrForbidden) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to load todos", http.StatusInternalServerError)
 		return
 	}
 
-	pages.Todos(todos).Render(r.Context(), w)
+	var moreURL string
+	if next != "" {
+		q := r.URL.Query()
+		q.Set("cursor", next)
+		moreURL = "/todos?" + q.Encode()
+	}
+
+	if page.Cursor != "" {
+		pages.TodoRows(todos, moreURL).Render(r.Context(), w)
+		return
+	}
+
+	projects, err := h.projectService.List(claims.UserID, false)
+	if err != nil {
+		http.Error(w, "Failed to load projects", http.StatusInternalServerError)
+		return
+	}
+
+	sharedProjects, err := h.projectService.ListShared(claims.UserID)
+	if err != nil {
+		http.Error(w, "Failed to load projects", http.StatusInternalServerError)
+		return
+	}
+
+	pages.Todos(todos, projects, sharedProjects, filter, moreURL).Render(r.Context(), w)
+}
+
+// SearchTodos renders the live search results under the search box. An
+// empty query clears them.
+func (h *WebHandler) SearchTodos(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		return
+	}
+
+	results, err := h.todoService.Search(claims.UserID, claims.Role, query, defaultSearchLimit)
+	if err != nil {
+		http.Error(w, "Failed to search todos", http.StatusInternalServerError)
+		return
+	}
+
+	components.SearchResults(results).Render(r.Context(), w)
 }
 
 func (h *WebHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
@@ -100,8 +310,20 @@ func (h *WebHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.todoService.Create(claims.UserID, title, "")
+	todo, err := h.todoService.Create(r.Context(), claims.UserID, service.CreateTodoParams{
+		ProjectID:  r.FormValue("project_id"),
+		Title:      title,
+		Priority:   r.FormValue("priority"),
+		DueDate:    r.FormValue("due_date"),
+		DueTime:    r.FormValue("due_time"),
+		Tags:       splitTags(r.FormValue("tags")),
+		Recurrence: r.FormValue("recurrence"),
+	})
 	if err != nil {
+		if isInvalidTodoInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to create todo", http.StatusInternalServerError)
 		return
 	}
@@ -130,11 +352,397 @@ func (h *WebHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 	completedStr := r.FormValue("completed")
 	completed := completedStr == "true"
 
-	todo, err := h.todoService.Update(todoID, claims.UserID, claims.Role, nil, nil, &completed)
+	todo, err := h.todoService.Update(r.Context(), todoID, claims.UserID, claims.Role, service.UpdateTodoParams{Completed: &completed})
 	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrTodoBlocked) {
+			http.Error(w, "Finish the todos blocking this one first", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
 		http.Error(w, "Failed to update todo", http.StatusInternalServerError)
 		return
 	}
 
 	components.TodoItem(todo).Render(r.Context(), w)
+	if todo.NextOccurrence != nil {
+		components.NewTodoItem(todo.NextOccurrence).Render(r.Context(), w)
+	}
+}
+
+// BulkTodos applies the bulk bar's action to the selected todos. When every
+// todo was changed the page reloads; otherwise it says how many were skipped.
+func (h *WebHandler) BulkTodos(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	ids := r.Form["ids"]
+	if len(ids) == 0 {
+		http.Error(w, "Select at least one todo", http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.todoService.Bulk(r.Context(), claims.UserID, claims.Role, service.BulkParams{
+		Action:    service.BulkAction(r.FormValue("action")),
+		IDs:       ids,
+		ProjectID: r.FormValue("project_id"),
+		Tags:      splitTags(r.FormValue("tags")),
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) || errors.Is(err, domain.ErrInvalidTag) || errors.Is(err, domain.ErrInvalidProject) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to update todos", http.StatusInternalServerError)
+		return
+	}
+
+	applied := 0
+	for _, result := range results {
+		if result.Status == service.BulkOK {
+			applied++
+		}
+	}
+	if applied == len(results) {
+		w.Header().Set("HX-Refresh", "true")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	components.BulkSummary(applied, len(results)-applied).Render(r.Context(), w)
+}
+
+// DeleteTodo moves a todo to the trash; the empty response removes its row.
+func (h *WebHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.todoService.Delete(r.Context(), chi.URLParam(r, "id"), claims.UserID, claims.Role, false)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Failed to delete todo", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// MoveTodo saves a drag-and-drop reorder. The list is already rearranged in
+// the browser, so there is nothing to render.
+func (h *WebHandler) MoveTodo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	_, err := h.todoService.Move(r.Context(), chi.URLParam(r, "id"), claims.UserID, claims.Role, r.FormValue("after_id"), r.FormValue("before_id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidPosition) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Failed to move todo", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TrashPage lists the deleted todos the user can still restore. Like the
+// todo list it scrolls in further pages as the sentinel comes into view.
+func (h *WebHandler) TrashPage(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	page := domain.PageRequest{Limit: defaultPageLimit, Cursor: r.URL.Query().Get("cursor")}
+	todos, next, err := h.todoService.Trash(claims.UserID, claims.Role, page)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load trash", http.StatusInternalServerError)
+		return
+	}
+
+	var moreURL string
+	if next != "" {
+		moreURL = "/trash?" + url.Values{"cursor": {next}}.Encode()
+	}
+
+	if page.Cursor != "" {
+		pages.TrashRows(todos, moreURL).Render(r.Context(), w)
+		return
+	}
+
+	pages.Trash(todos, moreURL).Render(r.Context(), w)
+}
+
+// RestoreTodo takes a todo out of the trash; the empty response removes its
+// row from the trash list.
+func (h *WebHandler) RestoreTodo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	_, err := h.todoService.Restore(r.Context(), chi.URLParam(r, "id"), claims.UserID, claims.Role)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found in trash", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Failed to restore todo", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// UnarchiveTodo brings an archived todo back and re-renders its row.
+func (h *WebHandler) UnarchiveTodo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	todo, err := h.todoService.Unarchive(r.Context(), chi.URLParam(r, "id"), claims.UserID, claims.Role)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found in archive", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Failed to unarchive todo", http.StatusInternalServerError)
+		return
+	}
+
+	components.TodoItem(todo).Render(r.Context(), w)
+}
+
+// TodoPage shows a single todo along with its comment thread.
+func (h *WebHandler) TodoPage(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	todoID := chi.URLParam(r, "id")
+	todo, err := h.todoService.GetByID(todoID, claims.UserID, claims.Role)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Failed to load todo", http.StatusInternalServerError)
+		return
+	}
+
+	comments, err := h.commentService.List(todoID, claims.UserID, claims.Role)
+	if err != nil {
+		http.Error(w, "Failed to load comments", http.StatusInternalServerError)
+		return
+	}
+
+	revisions, err := h.todoService.History(todoID, claims.UserID, claims.Role)
+	if err != nil {
+		http.Error(w, "Failed to load history", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = h.todoService.GetForEdit(todoID, claims.UserID, claims.Role)
+	canEdit := err == nil
+
+	pages.TodoDetail(todo, comments, revisions, claims.UserID, claims.Role == domain.RoleAdmin, canEdit).Render(r.Context(), w)
+}
+
+// RevertTodo restores a todo to an earlier revision and reloads its page.
+func (h *WebHandler) RevertTodo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rev, err := strconv.Atoi(chi.URLParam(r, "rev"))
+	if err != nil || rev < 1 {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	_, err = h.todoService.Revert(r.Context(), chi.URLParam(r, "id"), rev, claims.UserID, claims.Role)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrRevisionNotFound) {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if isInvalidTodoInput(err) || errors.Is(err, domain.ErrTodoBlocked) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to revert todo", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusOK)
+}
+
+// CreateComment posts a comment and renders it for the end of the thread.
+func (h *WebHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.commentService.Create(r.Context(), chi.URLParam(r, "id"), claims.UserID, claims.Role, r.FormValue("body"))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidComment) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			http.Error(w, "Todo not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Failed to create comment", http.StatusInternalServerError)
+		return
+	}
+
+	components.CommentItem(comment, true).Render(r.Context(), w)
+}
+
+// DeleteComment removes a comment; the empty response removes it from the thread.
+func (h *WebHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.commentService.Delete(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "commentID"), claims.UserID, claims.Role)
+	if err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) || errors.Is(err, domain.ErrCommentNotFound) {
+			http.Error(w, "Comment not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Failed to delete comment", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	claims, ok := auth.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.projectService.Create(r.Context(), claims.UserID, r.FormValue("name"), "")
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidProject) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to create project", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/todos?project="+url.QueryEscape(project.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// splitTags turns a comma-separated form field into tag names, dropping blanks.
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
 }
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
 
+	"godo/internal/auth"
+	"godo/internal/domain"
 	"godo/internal/service"
 	"godo/internal/store"
 	"godo/internal/testutil"
@@ -16,13 +19,14 @@ func setupWebTestHandler(t *testing.T) *WebHandler {
 	t.Helper()
 
 	db := testutil.SetupTestDB(t)
-	userRepo := store.NewUserRepo(db)
-	authService := service.NewAuthService(userRepo)
+	authService := newTestAuthService(db)
+
+	todoService := newTestTodoService(db)
 
-	todoRepo := store.NewTodoRepo(db)
-	todoService := service.NewTodoService(todoRepo)
+	projectService := service.NewProjectService(store.NewProjectRepo(db), store.NewAuditRepo(db), store.NewTransactor(db))
+	commentService := service.NewCommentService(store.NewCommentRepo(db), store.NewAuditRepo(db), todoService, store.NewTransactor(db))
 
-	return NewWebHandler(authService, todoService, "test-jwt-secret")
+	return NewWebHandler(authService, nil, todoService, projectService, commentService)
 }
 
 func TestWebLoginPage_Renders(t *testing.T) {
@@ -48,15 +52,15 @@ func TestWebLoginPage_Renders(t *testing.T) {
 
 func TestWebLogin_Success(t *testing.T) {
 	db := testutil.SetupTestDB(t)
-	userRepo := store.NewUserRepo(db)
-	authService := service.NewAuthService(userRepo)
-	todoRepo := store.NewTodoRepo(db)
-	todoService := service.NewTodoService(todoRepo)
-	handler := NewWebHandler(authService, todoService, "test-jwt-secret")
+	authService := newTestAuthService(db)
+	todoService := newTestTodoService(db)
+	projectService := service.NewProjectService(store.NewProjectRepo(db), store.NewAuditRepo(db), store.NewTransactor(db))
+	commentService := service.NewCommentService(store.NewCommentRepo(db), store.NewAuditRepo(db), todoService, store.NewTransactor(db))
+	handler := NewWebHandler(authService, nil, todoService, projectService, commentService)
 
 	// Create a user
 	password := "password123"
-	_, err := authService.Register("test@example.com", password)
+	_, err := authService.Register(context.Background(), "test@example.com", password)
 	if err != nil {
 		t.Fatalf("Failed to create test user: %v", err)
 	}
@@ -94,6 +98,128 @@ func TestWebLogin_Success(t *testing.T) {
 	if !authCookie.HttpOnly {
 		t.Error("Expected cookie to be HttpOnly")
 	}
+
+	var refreshCookie *http.Cookie
+	for _, c := range cookies {
+		if c.Name == "refresh_token" {
+			refreshCookie = c
+		}
+	}
+	if refreshCookie == nil || !refreshCookie.HttpOnly || refreshCookie.MaxAge <= authCookie.MaxAge {
+		t.Error("Expected an HttpOnly refresh_token cookie outliving the auth_token cookie")
+	}
+}
+
+func TestWebLogout_ClearsCookies(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	authService := newTestAuthService(db)
+	todoService := newTestTodoService(db)
+	projectService := service.NewProjectService(store.NewProjectRepo(db), store.NewAuditRepo(db), store.NewTransactor(db))
+	commentService := service.NewCommentService(store.NewCommentRepo(db), store.NewAuditRepo(db), todoService, store.NewTransactor(db))
+	handler := NewWebHandler(authService, nil, todoService, projectService, commentService)
+
+	user, _ := authService.Register(context.Background(), "logout@example.com", "password123")
+	session, _ := authService.StartSession(context.Background(), user)
+	claims, _ := auth.ValidateToken(session.AccessToken, "test-jwt-secret")
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.AddCookie(&http.Cookie{Name: auth.RefreshTokenCookie, Value: session.RefreshToken})
+	rec := httptest.NewRecorder()
+	handler.Logout(rec, requestWithClaims(req, claims))
+
+	if rec.Header().Get("HX-Redirect") != "/login" {
+		t.Errorf("Expected HX-Redirect to /login, got %q", rec.Header().Get("HX-Redirect"))
+	}
+	cleared := 0
+	for _, c := range rec.Result().Cookies() {
+		if c.MaxAge < 0 {
+			cleared++
+		}
+	}
+	if cleared != 2 {
+		t.Errorf("Expected both session cookies to be cleared, got %d", cleared)
+	}
+	if _, err := authService.Refresh(context.Background(), session.RefreshToken); err == nil {
+		t.Error("Expected the refresh token to stop working")
+	}
+}
+
+func TestWebRevokeSession_CurrentLogsOut(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	authService := newTestAuthService(db)
+	todoService := newTestTodoService(db)
+	projectService := service.NewProjectService(store.NewProjectRepo(db), store.NewAuditRepo(db), store.NewTransactor(db))
+	commentService := service.NewCommentService(store.NewCommentRepo(db), store.NewAuditRepo(db), todoService, store.NewTransactor(db))
+	handler := NewWebHandler(authService, nil, todoService, projectService, commentService)
+
+	user, _ := authService.Register(context.Background(), "sessions@example.com", "password123")
+	current, _ := authService.StartSession(context.Background(), user)
+	other, _ := authService.StartSession(context.Background(), user)
+	claims, _ := auth.ValidateToken(current.AccessToken, "test-jwt-secret")
+	otherClaims, _ := auth.ValidateToken(other.AccessToken, "test-jwt-secret")
+
+	rec := httptest.NewRecorder()
+	handler.SessionsPage(rec, requestWithClaims(httptest.NewRequest(http.MethodGet, "/sessions", nil), claims))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "this device") {
+		t.Fatalf("Expected the sessions page to mark this device, got status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.RevokeSession(rec, requestWithClaimsAndID(httptest.NewRequest(http.MethodDelete, "/sessions/"+otherClaims.SessionID, nil), claims, "id", otherClaims.SessionID))
+	if rec.Code != http.StatusOK || rec.Header().Get("HX-Redirect") != "" {
+		t.Errorf("Expected revoking another session to stay on the page, got status %d and redirect %q", rec.Code, rec.Header().Get("HX-Redirect"))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.RevokeSession(rec, requestWithClaimsAndID(httptest.NewRequest(http.MethodDelete, "/sessions/"+claims.SessionID, nil), claims, "id", claims.SessionID))
+	if rec.Header().Get("HX-Redirect") != "/login" {
+		t.Errorf("Expected HX-Redirect to /login, got %q", rec.Header().Get("HX-Redirect"))
+	}
+	if len(rec.Result().Cookies()) != 2 {
+		t.Errorf("Expected both session cookies to be cleared, got %d", len(rec.Result().Cookies()))
+	}
+}
+
+func TestWebPasswordReset(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	authService := newTestAuthService(db)
+	mailer := newRecordingMailer()
+	handler := NewWebHandler(authService, newTestPasswordResetService(db, authService, mailer), nil, nil, nil)
+
+	authService.Register(context.Background(), "web-reset@example.com", "old-password")
+
+	form := url.Values{"email": {"web-reset@example.com"}}
+	req := httptest.NewRequest(http.MethodPost, "/forgot-password", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ForgotPassword(rec, req)
+	if !strings.Contains(rec.Body.String(), "reset link is on its way") {
+		t.Errorf("Expected a confirmation, got %q", rec.Body.String())
+	}
+	token := mailer.nextResetToken(t)
+
+	rec = httptest.NewRecorder()
+	handler.ResetPasswordPage(rec, httptest.NewRequest(http.MethodGet, "/reset-password?token="+url.QueryEscape(token), nil))
+	if !strings.Contains(rec.Body.String(), `value="`+token+`"`) {
+		t.Error("Expected the reset form to carry the token")
+	}
+
+	form = url.Values{"token": {token}, "password": {"new-password"}}
+	req = httptest.NewRequest(http.MethodPost, "/reset-password", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ResetPassword(rec, req)
+	if rec.Header().Get("HX-Redirect") != "/login" {
+		t.Errorf("Expected HX-Redirect to /login, got %q: %s", rec.Header().Get("HX-Redirect"), rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/reset-password", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ResetPassword(rec, req)
+	if !strings.Contains(rec.Body.String(), "invalid or has expired") {
+		t.Errorf("Expected a used link to be refused, got %q", rec.Body.String())
+	}
 }
 
 func TestWebLogin_InvalidCredentials(t *testing.T) {
@@ -132,3 +258,73 @@ func TestWebLogin_InvalidCredentials(t *testing.T) {
 		t.Error("Should not redirect on failed login")
 	}
 }
+
+func TestWebMoveTodo_Errors(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	todoService := newTestTodoService(db)
+	handler := NewWebHandler(newTestAuthService(db), nil, todoService, nil, nil)
+
+	userRepo := store.NewUserRepo(db)
+	owner := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, store.NewTodoRepo(db), owner.ID)
+	neighbor := createTestTodo(t, store.NewTodoRepo(db), owner.ID)
+
+	tests := []struct {
+		name     string
+		todoID   string
+		userID   string
+		expected int
+	}{
+		{"missing todo", "missing", owner.ID, http.StatusNotFound},
+		{"someone else's todo", todo.ID, other.ID, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		form := url.Values{"after_id": {neighbor.ID}}
+		req := httptest.NewRequest(http.MethodPost, "/todos/"+tt.todoID+"/move", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		claims := &auth.Claims{UserID: tt.userID, Role: domain.RoleUser}
+		rec := httptest.NewRecorder()
+
+		handler.MoveTodo(rec, requestWithClaimsAndID(req, claims, "id", tt.todoID))
+
+		if rec.Code != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, rec.Code)
+		}
+	}
+}
+
+func TestWebUpdateTodo_Errors(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	handler := NewWebHandler(newTestAuthService(db), nil, newTestTodoService(db), nil, nil)
+
+	userRepo := store.NewUserRepo(db)
+	owner := createTestUser(t, userRepo, domain.RoleUser)
+	other := createTestUser(t, userRepo, domain.RoleUser)
+	todo := createTestTodo(t, store.NewTodoRepo(db), owner.ID)
+
+	tests := []struct {
+		name     string
+		todoID   string
+		userID   string
+		expected int
+	}{
+		{"missing todo", "missing", owner.ID, http.StatusNotFound},
+		{"someone else's todo", todo.ID, other.ID, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		form := url.Values{"completed": {"true"}}
+		req := httptest.NewRequest(http.MethodPut, "/todos/"+tt.todoID, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		claims := &auth.Claims{UserID: tt.userID, Role: domain.RoleUser}
+		rec := httptest.NewRecorder()
+
+		handler.UpdateTodo(rec, requestWithClaimsAndID(req, claims, "id", tt.todoID))
+
+		if rec.Code != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, rec.Code)
+		}
+	}
+}
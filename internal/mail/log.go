@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"fmt"
+	"godo/internal/domain"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogMailer writes email to w instead of sending it, for development: reset
+// links and the like can be read from the log or file it writes to.
+type LogMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+	now  func() time.Time
+}
+
+func NewLogMailer(w io.Writer, from string) *LogMailer {
+	return &LogMailer{w: w, from: from, now: time.Now}
+}
+
+func (m *LogMailer) Send(email *domain.Email) error {
+	msg, err := message(m.from, email, m.now())
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Messages are written with plain newlines rather than SMTP's CRLF,
+	// separated by a blank line.
+	text := strings.ReplaceAll(string(msg), "\r\n", "\n")
+	if _, err := fmt.Fprintf(m.w, "%s\n", text); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	return nil
+}
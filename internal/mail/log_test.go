@@ -0,0 +1,30 @@
+package mail
+
+import (
+	"bytes"
+	"godo/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestLogMailer_WritesMessages(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewLogMailer(&buf, "godo@example.com")
+
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		if err := mailer.Send(&domain.Email{To: to, Subject: "Hello", Body: "Line one\nLine two"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "\r") {
+		t.Error("expected plain newlines")
+	}
+	if strings.Count(out, "Subject: Hello\n") != 2 || !strings.Contains(out, "To: b@example.com\n") {
+		t.Errorf("expected both messages to be written, got:\n%s", out)
+	}
+	if !strings.Contains(out, "\n\nLine one\nLine two\n\n") {
+		t.Errorf("expected the body followed by a blank line, got:\n%s", out)
+	}
+}
@@ -0,0 +1,99 @@
+// Package mail provides the Mailer implementations: an SMTP relay for
+// delivering email and a log for development, where email is only read.
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"godo/internal/domain"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig points an SMTPMailer at a relay. Username and Password are only
+// sent when set; net/smtp refuses to send them unencrypted to hosts other
+// than localhost, so remote relays must offer STARTTLS.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer delivers email through an SMTP relay, one connection per email.
+type SMTPMailer struct {
+	cfg  SMTPConfig
+	addr string
+	now  func() time.Time
+}
+
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP host is required")
+	}
+	if cfg.Port <= 0 {
+		cfg.Port = 587
+	}
+	if cfg.From == "" {
+		return nil, fmt.Errorf("SMTP sender address is required")
+	}
+
+	return &SMTPMailer{
+		cfg:  cfg,
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		now:  time.Now,
+	}, nil
+}
+
+func (m *SMTPMailer) Send(email *domain.Email) error {
+	msg, err := message(m.cfg.From, email, m.now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	if err := smtp.SendMail(m.addr, auth, m.cfg.From, []string{email.To}, msg); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// message renders the email as an RFC 5322 message. Header values are checked
+// for line breaks so that no headers can be smuggled in through them.
+func message(from string, email *domain.Email, date time.Time) ([]byte, error) {
+	for _, value := range []string{from, email.To, email.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("invalid email header %q", value)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", email.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+
+	// SMTP wants CRLF line endings, and a line holding a single dot would
+	// end the message early; net/smtp takes care of the latter.
+	body := strings.ReplaceAll(email.Body, "\r\n", "\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	if !strings.HasSuffix(body, "\n") {
+		buf.WriteString("\r\n")
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,166 @@
+package mail
+
+import (
+	"bufio"
+	"godo/internal/domain"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a local stand-in for a relay. It speaks just enough SMTP for
+// net/smtp and records the messages it receives.
+type fakeSMTP struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	from     string
+	to       []string
+	messages []string
+}
+
+func startFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	f := &fakeSMTP{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+
+	return f
+}
+
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+
+	text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			text.PrintfLine("250 localhost")
+		case "MAIL":
+			f.mu.Lock()
+			f.from = strings.TrimSuffix(strings.TrimPrefix(line, "MAIL FROM:<"), ">")
+			f.mu.Unlock()
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			f.mu.Lock()
+			f.to = append(f.to, strings.TrimSuffix(strings.TrimPrefix(line, "RCPT TO:<"), ">"))
+			f.mu.Unlock()
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.messages = append(f.messages, string(data))
+			f.mu.Unlock()
+			text.PrintfLine("250 OK")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	server := startFakeSMTP(t)
+
+	mailer, err := NewSMTPMailer(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "godo@example.com"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	mailer.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	err = mailer.Send(&domain.Email{
+		To:      "user@example.com",
+		Subject: "Reset your password",
+		Body:    "Follow the link:\nhttp://localhost/reset\n.\nThanks",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if server.from != "godo@example.com" || len(server.to) != 1 || server.to[0] != "user@example.com" {
+		t.Errorf("expected an email from godo@example.com to user@example.com, got %q to %v", server.from, server.to)
+	}
+	if len(server.messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(server.messages))
+	}
+
+	msg, err := textproto.NewReader(bufio.NewReader(strings.NewReader(server.messages[0]))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("failed to parse message headers: %v", err)
+	}
+	if msg.Get("Subject") != "Reset your password" || msg.Get("To") != "user@example.com" {
+		t.Errorf("unexpected headers: %v", msg)
+	}
+	if msg.Get("Date") != "Tue, 02 Jan 2024 03:04:05 +0000" {
+		t.Errorf("unexpected date %q", msg.Get("Date"))
+	}
+	if !strings.HasSuffix(server.messages[0], "\nhttp://localhost/reset\n.\nThanks\n") {
+		t.Errorf("expected the body to survive intact, got %q", server.messages[0])
+	}
+}
+
+func TestSMTPMailer_RejectsHeaderInjection(t *testing.T) {
+	server := startFakeSMTP(t)
+	mailer, _ := NewSMTPMailer(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "godo@example.com"})
+
+	err := mailer.Send(&domain.Email{To: "user@example.com", Subject: "Hi\r\nBcc: victim@example.com", Body: "Hello"})
+	if err == nil {
+		t.Fatal("expected an error for a subject with a line break")
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.messages) != 0 {
+		t.Errorf("expected nothing to be sent, got %d messages", len(server.messages))
+	}
+}
+
+func TestNewSMTPMailer_Validates(t *testing.T) {
+	if _, err := NewSMTPMailer(SMTPConfig{From: "godo@example.com"}); err == nil {
+		t.Error("expected an error without a host")
+	}
+	if _, err := NewSMTPMailer(SMTPConfig{Host: "localhost"}); err == nil {
+		t.Error("expected an error without a sender")
+	}
+	mailer, err := NewSMTPMailer(SMTPConfig{Host: "localhost", From: "godo@example.com"})
+	if err != nil || mailer.addr != "localhost:587" {
+		t.Errorf("expected the submission port by default, got %v, %v", mailer, err)
+	}
+}
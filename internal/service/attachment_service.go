@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"godo/internal/domain"
+	"io"
+	"net/http"
+)
+
+// blobCleanupBatch is how many queued blob deletions are handled per query.
+const blobCleanupBatch = 100
+
+// AttachmentService manages files uploaded to todos. Access follows the
+// parent todo: whoever may see the todo may download its attachments, and
+// whoever may edit it may upload and delete them.
+type AttachmentService struct {
+	repo        domain.AttachmentRepository
+	blobs       domain.BlobStore
+	audit       auditLog
+	todoService *TodoService
+	maxSize     int64
+	transactor  domain.Transactor
+}
+
+func NewAttachmentService(repo domain.AttachmentRepository, blobs domain.BlobStore, auditRepo domain.AuditRepository, todoService *TodoService, maxSize int64, transactor domain.Transactor) *AttachmentService {
+	return &AttachmentService{repo: repo, blobs: blobs, audit: auditLog{repo: auditRepo}, todoService: todoService, maxSize: maxSize, transactor: transactor}
+}
+
+// inTx runs fn on a copy of the service bound to one transaction, so a change
+// is saved along with its audit event or not at all.
+func (s *AttachmentService) inTx(fn func(tx *AttachmentService) error) error {
+	return s.transactor.InTx(func(repos domain.Repos) error {
+		return fn(&AttachmentService{
+			repo:        repos.Attachments,
+			blobs:       s.blobs,
+			audit:       auditLog{repo: repos.Audit},
+			todoService: s.todoService,
+			maxSize:     s.maxSize,
+			transactor:  joinedTx{repos: repos},
+		})
+	})
+}
+
+// MaxSize is the largest upload accepted, in bytes.
+func (s *AttachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+func (s *AttachmentService) List(todoID, requestingUserID, requestingUserRole string) ([]*domain.Attachment, error) {
+	if _, err := s.todoService.GetByID(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByTodoID(todoID)
+}
+
+// Upload stores a file of the given size on the todo. The content type is
+// sniffed from the file itself rather than trusted from the client, and must
+// be one of domain.AttachmentTypes.
+func (s *AttachmentService) Upload(ctx context.Context, todoID, requestingUserID, requestingUserRole, filename string, size int64, content io.Reader) (*domain.Attachment, error) {
+	if _, err := s.todoService.GetForEdit(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	if size > s.maxSize {
+		return nil, fmt.Errorf("%w: files may be at most %d bytes", domain.ErrAttachmentTooLarge, s.maxSize)
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("%w: file is empty", domain.ErrInvalidAttachment)
+	}
+	filename, err := domain.NormalizeAttachmentFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	contentType := http.DetectContentType(head[:n])
+	if !domain.AttachmentTypeAllowed(contentType) {
+		return nil, fmt.Errorf("%w: %s files are not allowed", domain.ErrInvalidAttachment, contentType)
+	}
+
+	attachment := domain.NewAttachment(todoID, requestingUserID, filename, contentType, size)
+	if err := s.blobs.Put(attachment.StorageKey, io.MultiReader(bytes.NewReader(head[:n]), content), size, contentType); err != nil {
+		return nil, err
+	}
+
+	err = s.inTx(func(tx *AttachmentService) error {
+		if err := tx.repo.Create(attachment); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "upload", "attachment", attachment.ID, nil, attachment)
+	})
+	if err != nil {
+		s.blobs.Delete(attachment.StorageKey)
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Open returns an attachment along with a reader for its contents, which the
+// caller must close.
+func (s *AttachmentService) Open(todoID, attachmentID, requestingUserID, requestingUserRole string) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.get(todoID, attachmentID, requestingUserID, requestingUserRole, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.blobs.Get(attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// Delete removes an attachment. Its blob is deleted right away when possible
+// and otherwise left queued for CleanupBlobs.
+func (s *AttachmentService) Delete(ctx context.Context, todoID, attachmentID, requestingUserID, requestingUserRole string) error {
+	attachment, err := s.get(todoID, attachmentID, requestingUserID, requestingUserRole, true)
+	if err != nil {
+		return err
+	}
+
+	err = s.inTx(func(tx *AttachmentService) error {
+		if err := tx.repo.Delete(attachment.ID); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "delete", "attachment", attachment.ID, attachment, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.blobs.Delete(attachment.StorageKey) == nil {
+		return s.repo.ClearBlobDeletion(attachment.StorageKey)
+	}
+	return nil
+}
+
+// CleanupBlobs deletes the blobs of attachments that were removed, including
+// those that went with their todo, and reports how many were deleted. Blobs
+// that fail to delete stay queued for the next run.
+func (s *AttachmentService) CleanupBlobs() (int, error) {
+	var deleted int
+	for {
+		keys, err := s.repo.PendingBlobDeletions(blobCleanupBatch)
+		if err != nil {
+			return deleted, err
+		}
+
+		progress := false
+		for _, key := range keys {
+			if err := s.blobs.Delete(key); err != nil {
+				continue
+			}
+			if err := s.repo.ClearBlobDeletion(key); err != nil {
+				return deleted, err
+			}
+			deleted++
+			progress = true
+		}
+
+		if len(keys) < blobCleanupBatch || !progress {
+			return deleted, nil
+		}
+	}
+}
+
+// get loads an attachment after checking access to its todo, for editing
+// when edit is set. An attachment requested under the wrong todo is reported
+// as not found.
+func (s *AttachmentService) get(todoID, attachmentID, requestingUserID, requestingUserRole string, edit bool) (*domain.Attachment, error) {
+	load := s.todoService.GetByID
+	if edit {
+		load = s.todoService.GetForEdit
+	}
+	if _, err := load(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.repo.GetByID(attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.TodoID != todoID {
+		return nil, domain.ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"godo/internal/domain"
+)
+
+// RequestInfo identifies the request a change was made in, and the client
+// that sent it.
+type RequestInfo struct {
+	RequestID string
+	IP        string
+	UserAgent string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo attaches info to ctx, so changes made with ctx are recorded
+// in the audit log against the request.
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+func requestInfoFrom(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
+
+// auditLog records the changes a service makes. A change whose event can't
+// be recorded is reported as failed, so nothing goes unaudited silently.
+type auditLog struct {
+	repo domain.AuditRepository
+}
+
+// record logs that actorID performed action on the target, keeping the
+// fields that differ between before and after.
+func (l auditLog) record(ctx context.Context, actorID, action, targetType, targetID string, before, after any) error {
+	changes, err := domain.AuditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	info := requestInfoFrom(ctx)
+	event := domain.NewAuditEvent(actorID, action, targetType, targetID)
+	event.Changes = changes
+	event.RequestID = info.RequestID
+	event.IP = info.IP
+
+	return l.repo.Record(event)
+}
+
+// snapshot captures v as it is now, as the before side of a change to a
+// record that is about to be modified in place.
+func snapshot(v any) json.RawMessage {
+	// Domain types always encode, so the error can't happen
+	data, _ := json.Marshal(v)
+	return data
+}
+
+type AuditService struct {
+	repo domain.AuditRepository
+}
+
+func NewAuditService(repo domain.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// List returns one page of audit events along with the cursor of the next
+// page, which is "" on the last page. Only admins may read the audit log.
+func (s *AuditService) List(requestingUserRole string, filter domain.AuditFilter, page domain.PageRequest) ([]*domain.AuditEvent, string, error) {
+	if requestingUserRole != domain.RoleAdmin {
+		return nil, "", ErrForbidden
+	}
+
+	return s.repo.List(filter, page)
+}
@@ -1,8 +1,12 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"godo/internal/auth"
 	"godo/internal/domain"
+	"time"
 )
 
 var (
@@ -10,17 +14,49 @@ var (
 	ErrEmailExists        = errors.New("email already exists")
 	ErrInvalidInput       = errors.New("invalid input")
 	ErrPasswordTooShort   = errors.New("password must be at least 8 characters")
+	// ErrInvalidRefreshToken covers unknown, expired, used and revoked
+	// refresh tokens alike. It wraps auth.ErrInvalidToken so the cookie
+	// middleware turns the browser away.
+	ErrInvalidRefreshToken = fmt.Errorf("invalid refresh token: %w", auth.ErrInvalidToken)
+	// ErrRefreshTokenReused reports that a used refresh token was presented
+	// again, which revoked every token of its login.
+	ErrRefreshTokenReused = fmt.Errorf("refresh token reused: %w", auth.ErrRevokedToken)
 )
 
+// refreshReuseGrace is how long a used refresh token still refreshes its
+// session. Browsers send several requests at once, all with the same expired
+// session, and each of them needs new tokens; after the grace period a reused
+// token revokes its family.
+const refreshReuseGrace = 10 * time.Second
+
+// TokenConfig is how sessions are signed and how long their tokens last.
+type TokenConfig struct {
+	Secret     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
 type AuthService struct {
-	repo domain.UserRepository
+	repo          domain.UserRepository
+	sessions      domain.SessionRepository
+	refreshTokens domain.RefreshTokenRepository
+	revocations   *TokenRevocations
+	audit         auditLog
+	tokens        TokenConfig
 }
 
-func NewAuthService(repo domain.UserRepository) *AuthService {
-	return &AuthService{repo: repo}
+func NewAuthService(repo domain.UserRepository, sessions domain.SessionRepository, refreshTokens domain.RefreshTokenRepository, revocations *TokenRevocations, auditRepo domain.AuditRepository, tokens TokenConfig) *AuthService {
+	return &AuthService{
+		repo:          repo,
+		sessions:      sessions,
+		refreshTokens: refreshTokens,
+		revocations:   revocations,
+		audit:         auditLog{repo: auditRepo},
+		tokens:        tokens,
+	}
 }
 
-func (s *AuthService) Register(email, password string) (*domain.User, error) {
+func (s *AuthService) Register(ctx context.Context, email, password string) (*domain.User, error) {
 	if email == "" || password == "" {
 		return nil, ErrInvalidInput
 	}
@@ -45,6 +81,10 @@ func (s *AuthService) Register(email, password string) (*domain.User, error) {
 		return nil, err
 	}
 
+	if err := s.audit.record(ctx, user.ID, "register", "user", user.ID, nil, user); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
@@ -60,3 +100,158 @@ func (s *AuthService) Authenticate(email, password string) (*domain.User, error)
 
 	return user, nil
 }
+
+// StartSession logs the user in, starting a new session with its own family
+// of refresh tokens. The session remembers the client of the request in ctx.
+func (s *AuthService) StartSession(ctx context.Context, user *domain.User) (*auth.Session, error) {
+	info := requestInfoFrom(ctx)
+	session := domain.NewSession(user.ID, info.UserAgent, info.IP, time.Now().Add(s.tokens.RefreshTTL))
+	if err := s.sessions.Create(session); err != nil {
+		return nil, err
+	}
+
+	return s.issueSession(user, session.ID)
+}
+
+// Refresh trades a refresh token in for a new session in the same family.
+// Each token works once, give or take refreshReuseGrace: presenting a used
+// token again later revokes its family, logging out both the thief and the
+// user, and returns ErrRefreshTokenReused.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*auth.Session, error) {
+	token, err := s.refreshTokens.GetByHash(domain.HashRefreshToken(refreshToken))
+	if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || now.After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if token.UsedAt != nil && now.Sub(*token.UsedAt) >= refreshReuseGrace {
+		return nil, s.reused(ctx, token, now)
+	}
+
+	// A concurrent refresh may have used the token since it was read, which
+	// is let through like any use within the grace period. A revoked token
+	// fails below, as its session has ended.
+	if token.UsedAt == nil {
+		if err := s.refreshTokens.MarkUsed(token.ID, now); err != nil && !errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			return nil, err
+		}
+	}
+
+	user, err := s.repo.GetByID(token.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info := requestInfoFrom(ctx)
+	err = s.sessions.Touch(token.FamilyID, info.UserAgent, info.IP, now, now.Add(s.tokens.RefreshTTL))
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueSession(user, token.FamilyID)
+}
+
+// reused handles a used refresh token presented again after the grace
+// period: the token's family is revoked.
+func (s *AuthService) reused(ctx context.Context, token *domain.RefreshToken, now time.Time) error {
+	if err := s.endSession(token.FamilyID, token.UserID, now); err != nil {
+		return err
+	}
+
+	family := map[string]string{"family_id": token.FamilyID}
+	if err := s.audit.record(ctx, "", "revoke_refresh_tokens", "user", token.UserID, nil, family); err != nil {
+		return err
+	}
+
+	return ErrRefreshTokenReused
+}
+
+// Logout ends the session the access token belongs to. The token is revoked
+// along with its session; a token from before sessions were tracked names
+// its session by refreshToken instead, if given and the user's.
+func (s *AuthService) Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error {
+	if err := s.revocations.Revoke(claims); err != nil {
+		return err
+	}
+
+	sessionID := claims.SessionID
+	if sessionID == "" && refreshToken != "" {
+		token, err := s.refreshTokens.GetByHash(domain.HashRefreshToken(refreshToken))
+		if err != nil && !errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			return err
+		}
+		if err == nil && token.UserID == claims.UserID {
+			sessionID = token.FamilyID
+		}
+	}
+	if sessionID != "" {
+		if err := s.endSession(sessionID, claims.UserID, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return s.audit.record(ctx, claims.UserID, "logout", "user", claims.UserID, nil, nil)
+}
+
+// LogoutEverywhere ends every session of the user, on all devices.
+func (s *AuthService) LogoutEverywhere(ctx context.Context, userID string) error {
+	if err := s.revocations.RevokeUser(userID); err != nil {
+		return err
+	}
+	if err := s.refreshTokens.RevokeUser(userID, time.Now()); err != nil {
+		return err
+	}
+	if err := s.sessions.RevokeUser(userID, time.Now()); err != nil {
+		return err
+	}
+
+	return s.audit.record(ctx, userID, "logout_everywhere", "user", userID, nil, nil)
+}
+
+// endSession revokes a session along with its refresh tokens and the access
+// tokens issued for it. A session that already ended is not an error.
+func (s *AuthService) endSession(sessionID, userID string, now time.Time) error {
+	if err := s.refreshTokens.RevokeFamily(sessionID, now); err != nil {
+		return err
+	}
+	if err := s.sessions.Revoke(sessionID, now); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return err
+	}
+	return s.revocations.RevokeSession(sessionID, userID, now.Add(s.tokens.AccessTTL))
+}
+
+// issueSession hands out a new pair of tokens for the session sessionID.
+func (s *AuthService) issueSession(user *domain.User, sessionID string) (*auth.Session, error) {
+	now := time.Now()
+	accessToken, err := auth.GenerateToken(user.ID, user.Email, user.Role, user.TokenVersion, sessionID, s.tokens.Secret, s.tokens.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	token, refreshToken, err := domain.NewRefreshToken(user.ID, sessionID, s.tokens.RefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshTokens.Create(token); err != nil {
+		return nil, err
+	}
+
+	return &auth.Session{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  now.Add(s.tokens.AccessTTL),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: token.ExpiresAt,
+	}, nil
+}
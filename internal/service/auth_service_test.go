@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"testing"
+	"time"
+)
+
+type authTestDeps struct {
+	db            *sql.DB
+	sessions      *store.SessionRepo
+	refreshTokens *store.RefreshTokenRepo
+	audit         *store.AuditRepo
+	revocations   *TokenRevocations
+}
+
+func setupTestAuthService(t *testing.T) (*AuthService, authTestDeps) {
+	t.Helper()
+
+	db := testutil.SetupTestDB(t)
+	userRepo := store.NewUserRepo(db)
+	deps := authTestDeps{
+		db:            db,
+		sessions:      store.NewSessionRepo(db),
+		refreshTokens: store.NewRefreshTokenRepo(db),
+		audit:         store.NewAuditRepo(db),
+		revocations:   NewTokenRevocations(store.NewRevokedTokenRepo(db), userRepo),
+	}
+
+	authService := NewAuthService(userRepo, deps.sessions, deps.refreshTokens, deps.revocations, deps.audit, TokenConfig{
+		Secret:     "test-secret",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+	return authService, deps
+}
+
+func TestAuthServiceRefresh_RotatesAndDetectsReuse(t *testing.T) {
+	authService, deps := setupTestAuthService(t)
+	db, refreshTokens, auditRepo := deps.db, deps.refreshTokens, deps.audit
+
+	user, err := authService.Register(context.Background(), "refresh@example.com", "password123")
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	first, err := authService.StartSession(context.Background(), user)
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if claims, err := auth.ValidateToken(first.AccessToken, "test-secret"); err != nil || claims.UserID != user.ID {
+		t.Fatalf("Expected an access token for the user, got %v", err)
+	}
+
+	second, err := authService.Refresh(context.Background(), first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("Expected the refresh token to rotate")
+	}
+
+	// Reuse right after rotation, as by parallel browser requests, refreshes
+	// the same session
+	parallel, err := authService.Refresh(context.Background(), first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Expected reuse within the grace period to refresh, got %v", err)
+	}
+	firstClaims, _ := auth.ValidateToken(first.AccessToken, "test-secret")
+	if claims, err := auth.ValidateToken(parallel.AccessToken, "test-secret"); err != nil || claims.SessionID != firstClaims.SessionID {
+		t.Errorf("Expected an access token for the same session, got %v", err)
+	}
+	if _, err := authService.Refresh(context.Background(), "not-a-token"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken for an unknown token, got %v", err)
+	}
+
+	// Later reuse means the token leaked and revokes the whole family
+	used, _ := refreshTokens.GetByHash(domain.HashRefreshToken(first.RefreshToken))
+	if _, err := db.Exec(`UPDATE refresh_tokens SET used_at = ? WHERE id = ?`, used.UsedAt.Add(-time.Minute), used.ID); err != nil {
+		t.Fatalf("Failed to age the used token: %v", err)
+	}
+	if _, err := authService.Refresh(context.Background(), first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Expected ErrRefreshTokenReused, got %v", err)
+	}
+	if _, err := authService.Refresh(context.Background(), second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected the latest token of the family to be revoked, got %v", err)
+	}
+	if _, err := authService.Refresh(context.Background(), parallel.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected the token handed out within the grace period to be revoked, got %v", err)
+	}
+
+	events, _, _ := auditRepo.List(domain.AuditFilter{Action: "revoke_refresh_tokens"}, domain.PageRequest{})
+	if len(events) != 1 || events[0].TargetID != user.ID {
+		t.Errorf("Expected the revocation to be audited, got %d events", len(events))
+	}
+}
+
+func TestAuthServiceLogout(t *testing.T) {
+	authService, deps := setupTestAuthService(t)
+
+	user, _ := authService.Register(context.Background(), "logout@example.com", "password123")
+	session, _ := authService.StartSession(context.Background(), user)
+	other, _ := authService.StartSession(context.Background(), user)
+
+	claims, _ := auth.ValidateToken(session.AccessToken, "test-secret")
+	if err := authService.Logout(context.Background(), claims, session.RefreshToken); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+
+	if revoked, _ := deps.revocations.IsRevoked(claims); !revoked {
+		t.Error("Expected the access token to be revoked")
+	}
+	if _, err := authService.Refresh(context.Background(), session.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected the session's refresh token to be revoked, got %v", err)
+	}
+
+	otherClaims, _ := auth.ValidateToken(other.AccessToken, "test-secret")
+	if revoked, _ := deps.revocations.IsRevoked(otherClaims); revoked {
+		t.Error("Expected the user's other session to stay valid")
+	}
+}
+
+func TestAuthServiceLogoutEverywhere(t *testing.T) {
+	authService, deps := setupTestAuthService(t)
+
+	user, _ := authService.Register(context.Background(), "everywhere@example.com", "password123")
+	first, _ := authService.StartSession(context.Background(), user)
+	second, _ := authService.StartSession(context.Background(), user)
+
+	if err := authService.LogoutEverywhere(context.Background(), user.ID); err != nil {
+		t.Fatalf("LogoutEverywhere failed: %v", err)
+	}
+
+	for _, session := range []*auth.Session{first, second} {
+		claims, _ := auth.ValidateToken(session.AccessToken, "test-secret")
+		if revoked, _ := deps.revocations.IsRevoked(claims); !revoked {
+			t.Error("Expected every access token to be revoked")
+		}
+		if _, err := authService.Refresh(context.Background(), session.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("Expected every refresh token to be revoked, got %v", err)
+		}
+	}
+
+	// Logging in again issues tokens with the new version
+	user, _ = authService.Authenticate("everywhere@example.com", "password123")
+	fresh, _ := authService.StartSession(context.Background(), user)
+	claims, _ := auth.ValidateToken(fresh.AccessToken, "test-secret")
+	if revoked, _ := deps.revocations.IsRevoked(claims); revoked {
+		t.Error("Expected a new login to be valid")
+	}
+}
+
+func TestAuthServiceSessions_ListAndRevoke(t *testing.T) {
+	authService, deps := setupTestAuthService(t)
+
+	ctx := WithRequestInfo(context.Background(), RequestInfo{IP: "10.0.0.1", UserAgent: "Firefox"})
+	user, _ := authService.Register(ctx, "sessions@example.com", "password123")
+	other, _ := authService.Register(ctx, "other-sessions@example.com", "password123")
+	first, _ := authService.StartSession(ctx, user)
+	second, _ := authService.StartSession(ctx, user)
+
+	sessions, err := authService.ListSessions(user.ID, user.ID, domain.RoleUser)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].UserAgent != "Firefox" || sessions[0].IP != "10.0.0.1" {
+		t.Fatalf("Expected 2 sessions remembering the client, got %+v", sessions)
+	}
+	if _, err := authService.ListSessions(user.ID, other.ID, domain.RoleUser); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected another user to be forbidden, got %v", err)
+	}
+	if _, err := authService.ListSessions(user.ID, other.ID, domain.RoleAdmin); err != nil {
+		t.Errorf("Expected an admin to list anyone's sessions, got %v", err)
+	}
+
+	claims, _ := auth.ValidateToken(first.AccessToken, "test-secret")
+	if err := authService.RevokeSession(ctx, other.ID, claims.SessionID, other.ID, domain.RoleUser); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Expected another user's session not to be found, got %v", err)
+	}
+	if err := authService.RevokeSession(ctx, user.ID, claims.SessionID, user.ID, domain.RoleUser); err != nil {
+		t.Fatalf("RevokeSession failed: %v", err)
+	}
+
+	if revoked, _ := deps.revocations.IsRevoked(claims); !revoked {
+		t.Error("Expected the session's access token to be revoked")
+	}
+	if _, err := authService.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected the session's refresh token to be revoked, got %v", err)
+	}
+	secondClaims, _ := auth.ValidateToken(second.AccessToken, "test-secret")
+	if revoked, _ := deps.revocations.IsRevoked(secondClaims); revoked {
+		t.Error("Expected the other session to stay valid")
+	}
+	if sessions, _ := authService.ListSessions(user.ID, user.ID, domain.RoleUser); len(sessions) != 1 {
+		t.Errorf("Expected 1 session left, got %d", len(sessions))
+	}
+
+	events, _, _ := deps.audit.List(domain.AuditFilter{Action: "revoke_session"}, domain.PageRequest{})
+	if len(events) != 1 || events[0].TargetID != claims.SessionID {
+		t.Errorf("Expected the revocation to be audited, got %d events", len(events))
+	}
+}
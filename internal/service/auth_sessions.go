@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"godo/internal/domain"
+	"time"
+)
+
+// ListSessions returns the live sessions of a user, most recently seen first.
+// Users see their own sessions; admins see anyone's.
+func (s *AuthService) ListSessions(userID, requestingUserID, requestingUserRole string) ([]*domain.Session, error) {
+	if requestingUserRole != domain.RoleAdmin && userID != requestingUserID {
+		return nil, ErrForbidden
+	}
+
+	if userID != requestingUserID {
+		if _, err := s.repo.GetByID(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.sessions.GetByUserID(userID)
+}
+
+// RevokeSession ends one session of a user, logging that device out. Users
+// revoke their own sessions; admins revoke anyone's.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID, requestingUserID, requestingUserRole string) error {
+	if requestingUserRole != domain.RoleAdmin && userID != requestingUserID {
+		return ErrForbidden
+	}
+
+	session, err := s.sessions.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return domain.ErrSessionNotFound
+	}
+
+	if err := s.endSession(session.ID, session.UserID, time.Now()); err != nil {
+		return err
+	}
+
+	return s.audit.record(ctx, requestingUserID, "revoke_session", "session", session.ID, session, nil)
+}
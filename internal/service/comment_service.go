@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"godo/internal/domain"
+	"time"
+)
+
+// CommentService manages the discussion thread on a todo. Access follows the
+// parent todo: whoever may see the todo may read the thread, and whoever may
+// edit it may post. Comments can only be edited by their author; admins may
+// also delete them.
+type CommentService struct {
+	repo        domain.CommentRepository
+	audit       auditLog
+	todoService *TodoService
+	transactor  domain.Transactor
+}
+
+func NewCommentService(repo domain.CommentRepository, auditRepo domain.AuditRepository, todoService *TodoService, transactor domain.Transactor) *CommentService {
+	return &CommentService{repo: repo, audit: auditLog{repo: auditRepo}, todoService: todoService, transactor: transactor}
+}
+
+// inTx runs fn on a copy of the service bound to one transaction, so a change
+// is saved along with its audit event or not at all.
+func (s *CommentService) inTx(fn func(tx *CommentService) error) error {
+	return s.transactor.InTx(func(repos domain.Repos) error {
+		return fn(&CommentService{repo: repos.Comments, audit: auditLog{repo: repos.Audit}, todoService: s.todoService, transactor: joinedTx{repos: repos}})
+	})
+}
+
+func (s *CommentService) List(todoID, requestingUserID, requestingUserRole string) ([]*domain.Comment, error) {
+	if _, err := s.todoService.GetByID(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByTodoID(todoID)
+}
+
+func (s *CommentService) Create(ctx context.Context, todoID, requestingUserID, requestingUserRole, body string) (*domain.Comment, error) {
+	body, err := domain.NormalizeCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.todoService.GetForEdit(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	comment := domain.NewComment(todoID, requestingUserID, body)
+	err = s.inTx(func(tx *CommentService) error {
+		if err := tx.repo.Create(comment); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "create", "comment", comment.ID, nil, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(comment.ID)
+}
+
+func (s *CommentService) Update(ctx context.Context, todoID, commentID, requestingUserID, requestingUserRole, body string) (*domain.Comment, error) {
+	body, err := domain.NormalizeCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.get(todoID, commentID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.UserID != requestingUserID {
+		return nil, ErrForbidden
+	}
+
+	before := snapshot(comment)
+	comment.Body = body
+	comment.UpdatedAt = time.Now()
+
+	err = s.inTx(func(tx *CommentService) error {
+		if err := tx.repo.Update(comment); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "update", "comment", comment.ID, before, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+func (s *CommentService) Delete(ctx context.Context, todoID, commentID, requestingUserID, requestingUserRole string) error {
+	comment, err := s.get(todoID, commentID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return err
+	}
+
+	if requestingUserRole != domain.RoleAdmin && comment.UserID != requestingUserID {
+		return ErrForbidden
+	}
+
+	return s.inTx(func(tx *CommentService) error {
+		if err := tx.repo.Delete(commentID); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "delete", "comment", commentID, comment, nil)
+	})
+}
+
+// get loads a comment after checking access to its todo. A comment requested
+// under the wrong todo is reported as not found.
+func (s *CommentService) get(todoID, commentID, requestingUserID, requestingUserRole string) (*domain.Comment, error) {
+	if _, err := s.todoService.GetByID(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.repo.GetByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.TodoID != todoID {
+		return nil, domain.ErrCommentNotFound
+	}
+
+	return comment, nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"godo/internal/domain"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidResetToken covers unknown, expired and used reset tokens alike.
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+// A user gets at most resetRequestLimit reset emails per resetRequestWindow;
+// further requests are accepted but send nothing, so the address can't be
+// flooded with mail.
+const (
+	resetRequestLimit  = 3
+	resetRequestWindow = time.Hour
+)
+
+// PasswordResetConfig is how long reset links last and where they point:
+// BaseURL is where the web UI is served.
+type PasswordResetConfig struct {
+	TTL     time.Duration
+	BaseURL string
+}
+
+// PasswordResetService lets users who forgot their password set a new one
+// through a link mailed to them. Mail goes out in the background; failures
+// are logged to logger.
+type PasswordResetService struct {
+	users       domain.UserRepository
+	resets      domain.PasswordResetRepository
+	mailer      domain.Mailer
+	authService *AuthService
+	audit       auditLog
+	cfg         PasswordResetConfig
+	logger      *slog.Logger
+	transactor  domain.Transactor
+	sending     sync.WaitGroup
+}
+
+func NewPasswordResetService(users domain.UserRepository, resets domain.PasswordResetRepository, mailer domain.Mailer, authService *AuthService, auditRepo domain.AuditRepository, cfg PasswordResetConfig, logger *slog.Logger, transactor domain.Transactor) *PasswordResetService {
+	return &PasswordResetService{
+		users:       users,
+		resets:      resets,
+		mailer:      mailer,
+		authService: authService,
+		audit:       auditLog{repo: auditRepo},
+		cfg:         cfg,
+		logger:      logger,
+		transactor:  transactor,
+	}
+}
+
+// inTx runs fn on a copy of the service bound to one transaction, so a change
+// is saved along with its audit event or not at all.
+func (s *PasswordResetService) inTx(fn func(tx *PasswordResetService) error) error {
+	return s.transactor.InTx(func(repos domain.Repos) error {
+		return fn(&PasswordResetService{
+			users:       repos.Users,
+			resets:      repos.PasswordResets,
+			mailer:      s.mailer,
+			authService: s.authService,
+			audit:       auditLog{repo: repos.Audit},
+			cfg:         s.cfg,
+			logger:      s.logger,
+			transactor:  joinedTx{repos: repos},
+		})
+	})
+}
+
+// RequestReset mails a reset link to the user with the given email. Unknown
+// addresses are not an error, so the answer doesn't tell who has an account.
+// Neither does how long it takes nor whether the mail could be sent, since
+// the link is sent after returning.
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string) error {
+	if email == "" {
+		return ErrInvalidInput
+	}
+
+	user, err := s.users.GetByEmail(email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	count, err := s.resets.CountSince(user.ID, now.Add(-resetRequestWindow))
+	if err != nil {
+		return err
+	}
+	if count >= resetRequestLimit {
+		return nil
+	}
+
+	token, value, err := domain.NewPasswordResetToken(user.ID, s.cfg.TTL)
+	if err != nil {
+		return err
+	}
+	err = s.inTx(func(tx *PasswordResetService) error {
+		if err := tx.resets.Create(token); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, user.ID, "request_password_reset", "user", user.ID, nil, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.send(s.resetEmail(user, value))
+	return nil
+}
+
+// send mails email in the background.
+func (s *PasswordResetService) send(email *domain.Email) {
+	s.sending.Add(1)
+	go func() {
+		defer s.sending.Done()
+		if err := s.mailer.Send(email); err != nil {
+			s.logger.Error("Failed to send password reset email", "error", err)
+		}
+	}()
+}
+
+// ResetPassword sets a new password for the owner of the reset token. Every
+// session of the user ends, since whoever knew the old password may be
+// logged in somewhere.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, tokenValue, newPassword string) error {
+	if len(newPassword) < 8 {
+		return ErrPasswordTooShort
+	}
+
+	token, err := s.resets.GetByHash(domain.HashPasswordResetToken(tokenValue))
+	if errors.Is(err, domain.ErrPasswordResetTokenNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if token.UsedAt != nil || now.After(token.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.users.GetByID(token.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := domain.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = hashedPassword
+
+	// The token is used up only along with the new password, so a failed
+	// update leaves the link working.
+	err = s.inTx(func(tx *PasswordResetService) error {
+		// Two requests with the same token may both get here; only one of
+		// them marks it used.
+		if err := tx.resets.MarkUsed(token.ID, now); err != nil {
+			if errors.Is(err, domain.ErrPasswordResetTokenNotFound) {
+				return ErrInvalidResetToken
+			}
+			return err
+		}
+
+		if err := tx.users.Update(user); err != nil {
+			return err
+		}
+
+		// Other links mailed before this one must not reset the new password.
+		if err := tx.resets.MarkUserUsed(user.ID, now); err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, user.ID, "reset_password", "user", user.ID, nil, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.authService.LogoutEverywhere(ctx, user.ID)
+}
+
+func (s *PasswordResetService) resetEmail(user *domain.User, token string) *domain.Email {
+	link := strings.TrimSuffix(s.cfg.BaseURL, "/") + "/reset-password?" + url.Values{"token": {token}}.Encode()
+
+	return &domain.Email{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your account.\n\n"+
+			"To choose a new password, open this link within %.0f minutes:\n\n%s\n\n"+
+			"If it wasn't you, ignore this email; your password stays the same.\n",
+			s.cfg.TTL.Minutes(), link),
+	}
+}
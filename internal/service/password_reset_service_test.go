@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"godo/internal/store"
+	"io"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingMailer keeps the email it is asked to send, failing with err if
+// set.
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []*domain.Email
+	err  error
+}
+
+func (m *recordingMailer) Send(email *domain.Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, email)
+	return m.err
+}
+
+var resetLinkPattern = regexp.MustCompile(`https://godo\.example\.com/reset-password\?\S+`)
+
+// resetTokenFrom pulls the token out of the link in a reset email.
+func resetTokenFrom(t *testing.T, email *domain.Email) string {
+	t.Helper()
+
+	link, err := url.Parse(resetLinkPattern.FindString(email.Body))
+	if err != nil || link.Query().Get("token") == "" {
+		t.Fatalf("Expected a reset link in the email, got %q", email.Body)
+	}
+	return link.Query().Get("token")
+}
+
+func setupTestPasswordResetService(t *testing.T) (*PasswordResetService, *AuthService, *recordingMailer, authTestDeps) {
+	t.Helper()
+
+	authService, deps := setupTestAuthService(t)
+	mailer := &recordingMailer{}
+	resetService := NewPasswordResetService(store.NewUserRepo(deps.db), store.NewPasswordResetRepo(deps.db), mailer, authService, deps.audit, PasswordResetConfig{
+		TTL:     time.Hour,
+		BaseURL: "https://godo.example.com/",
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)), store.NewTransactor(deps.db))
+	return resetService, authService, mailer, deps
+}
+
+func TestPasswordResetService_ResetsOnce(t *testing.T) {
+	resetService, authService, mailer, deps := setupTestPasswordResetService(t)
+
+	user, _ := authService.Register(context.Background(), "forgetful@example.com", "old-password")
+	session, _ := authService.StartSession(context.Background(), user)
+
+	if err := resetService.RequestReset(context.Background(), "forgetful@example.com"); err != nil {
+		t.Fatalf("RequestReset failed: %v", err)
+	}
+	resetService.sending.Wait()
+	if len(mailer.sent) != 1 || mailer.sent[0].To != "forgetful@example.com" {
+		t.Fatalf("Expected a reset email to the user, got %+v", mailer.sent)
+	}
+	token := resetTokenFrom(t, mailer.sent[0])
+
+	if err := resetService.ResetPassword(context.Background(), token, "short"); !errors.Is(err, ErrPasswordTooShort) {
+		t.Errorf("Expected ErrPasswordTooShort, got %v", err)
+	}
+	if err := resetService.ResetPassword(context.Background(), token, "new-password"); err != nil {
+		t.Fatalf("ResetPassword failed: %v", err)
+	}
+
+	if _, err := authService.Authenticate("forgetful@example.com", "old-password"); err == nil {
+		t.Error("Expected the old password to stop working")
+	}
+	if _, err := authService.Authenticate("forgetful@example.com", "new-password"); err != nil {
+		t.Errorf("Expected the new password to work, got %v", err)
+	}
+
+	claims, _ := auth.ValidateToken(session.AccessToken, "test-secret")
+	if revoked, _ := deps.revocations.IsRevoked(claims); !revoked {
+		t.Error("Expected existing sessions to end")
+	}
+
+	if err := resetService.ResetPassword(context.Background(), token, "another-password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("Expected a used token to be rejected, got %v", err)
+	}
+	if err := resetService.ResetPassword(context.Background(), "made-up", "another-password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("Expected an unknown token to be rejected, got %v", err)
+	}
+
+	events, _, _ := deps.audit.List(domain.AuditFilter{Action: "reset_password"}, domain.PageRequest{})
+	if len(events) != 1 || events[0].TargetID != user.ID {
+		t.Errorf("Expected the reset to be audited, got %d events", len(events))
+	}
+}
+
+func TestPasswordResetService_ExpiredToken(t *testing.T) {
+	resetService, authService, _, deps := setupTestPasswordResetService(t)
+
+	user, _ := authService.Register(context.Background(), "late@example.com", "old-password")
+	token, value, _ := domain.NewPasswordResetToken(user.ID, -time.Minute)
+	store.NewPasswordResetRepo(deps.db).Create(token)
+
+	if err := resetService.ResetPassword(context.Background(), value, "new-password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("Expected an expired token to be rejected, got %v", err)
+	}
+}
+
+func TestPasswordResetService_RequestLimits(t *testing.T) {
+	resetService, authService, mailer, _ := setupTestPasswordResetService(t)
+
+	authService.Register(context.Background(), "popular@example.com", "password123")
+
+	if err := resetService.RequestReset(context.Background(), "nobody@example.com"); err != nil {
+		t.Errorf("Expected an unknown email not to be an error, got %v", err)
+	}
+	resetService.sending.Wait()
+	if len(mailer.sent) != 0 {
+		t.Errorf("Expected no email for an unknown address, got %d", len(mailer.sent))
+	}
+
+	for i := 0; i < resetRequestLimit+2; i++ {
+		if err := resetService.RequestReset(context.Background(), "popular@example.com"); err != nil {
+			t.Fatalf("RequestReset failed: %v", err)
+		}
+	}
+	resetService.sending.Wait()
+	if len(mailer.sent) != resetRequestLimit {
+		t.Errorf("Expected %d emails within the window, got %d", resetRequestLimit, len(mailer.sent))
+	}
+}
+
+func TestPasswordResetService_MailFailureIsNotAnError(t *testing.T) {
+	resetService, authService, mailer, _ := setupTestPasswordResetService(t)
+	mailer.err = errors.New("mail server unavailable")
+
+	authService.Register(context.Background(), "unlucky@example.com", "password123")
+
+	// Failing only for known addresses would tell who has an account
+	if err := resetService.RequestReset(context.Background(), "unlucky@example.com"); err != nil {
+		t.Errorf("Expected a failed email not to be an error, got %v", err)
+	}
+	resetService.sending.Wait()
+	if len(mailer.sent) != 1 {
+		t.Errorf("Expected the email to be tried, got %d", len(mailer.sent))
+	}
+}
+
+// failingUserUpdateTransactor hands out transactions in which updating a
+// user fails.
+type failingUserUpdateTransactor struct {
+	domain.Transactor
+}
+
+type failingUserUpdates struct {
+	domain.UserRepository
+}
+
+func (failingUserUpdates) Update(user *domain.User) error {
+	return errors.New("disk full")
+}
+
+func (t failingUserUpdateTransactor) InTx(fn func(repos domain.Repos) error) error {
+	return t.Transactor.InTx(func(repos domain.Repos) error {
+		repos.Users = failingUserUpdates{repos.Users}
+		return fn(repos)
+	})
+}
+
+func TestPasswordResetService_FailedResetKeepsToken(t *testing.T) {
+	resetService, authService, _, deps := setupTestPasswordResetService(t)
+
+	user, _ := authService.Register(context.Background(), "unlucky@example.com", "old-password")
+	token, value, _ := domain.NewPasswordResetToken(user.ID, time.Hour)
+	store.NewPasswordResetRepo(deps.db).Create(token)
+
+	transactor := resetService.transactor
+	resetService.transactor = failingUserUpdateTransactor{transactor}
+	if err := resetService.ResetPassword(context.Background(), value, "new-password"); err == nil {
+		t.Fatal("Expected the reset to fail when the password can't be saved")
+	}
+
+	resetService.transactor = transactor
+	if err := resetService.ResetPassword(context.Background(), value, "new-password"); err != nil {
+		t.Errorf("Expected the token to still work after a failed reset, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"godo/internal/domain"
+	"time"
+)
+
+type ProjectService struct {
+	repo       domain.ProjectRepository
+	audit      auditLog
+	transactor domain.Transactor
+}
+
+func NewProjectService(repo domain.ProjectRepository, auditRepo domain.AuditRepository, transactor domain.Transactor) *ProjectService {
+	return &ProjectService{repo: repo, audit: auditLog{repo: auditRepo}, transactor: transactor}
+}
+
+// inTx runs fn on a copy of the service bound to one transaction, so a change
+// is saved along with its audit event or not at all.
+func (s *ProjectService) inTx(fn func(tx *ProjectService) error) error {
+	return s.transactor.InTx(func(repos domain.Repos) error {
+		return fn(&ProjectService{repo: repos.Projects, audit: auditLog{repo: repos.Audit}, transactor: joinedTx{repos: repos}})
+	})
+}
+
+// UpdateProjectParams holds a partial update; nil fields are left unchanged.
+type UpdateProjectParams struct {
+	Name        *string
+	Description *string
+	Archived    *bool
+}
+
+func (s *ProjectService) Create(ctx context.Context, userID, name, description string) (*domain.Project, error) {
+	name, err := domain.NormalizeProjectName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	project := domain.NewProject(userID, name, description)
+	err = s.inTx(func(tx *ProjectService) error {
+		if err := tx.repo.Create(project); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, userID, "create", "project", project.ID, nil, project)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (s *ProjectService) GetByID(projectID, requestingUserID, requestingUserRole string) (*domain.Project, error) {
+	project, err := s.repo.GetByID(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if requestingUserRole != domain.RoleAdmin && project.UserID != requestingUserID {
+		return nil, ErrForbidden
+	}
+
+	return project, nil
+}
+
+// List returns the requesting user's own projects. Like tags, projects are
+// personal, so admins see only theirs too.
+func (s *ProjectService) List(requestingUserID string, includeArchived bool) ([]*domain.Project, error) {
+	return s.repo.GetByUserID(requestingUserID, includeArchived)
+}
+
+// ListShared returns the unarchived projects other users shared with the
+// requesting user.
+func (s *ProjectService) ListShared(requestingUserID string) ([]*domain.Project, error) {
+	return s.repo.GetSharedWith(requestingUserID)
+}
+
+func (s *ProjectService) Update(ctx context.Context, projectID, requestingUserID, requestingUserRole string, params UpdateProjectParams) (*domain.Project, error) {
+	project, err := s.GetByID(projectID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(project)
+
+	if params.Name != nil {
+		name, err := domain.NormalizeProjectName(*params.Name)
+		if err != nil {
+			return nil, err
+		}
+		project.Name = name
+	}
+	if params.Description != nil {
+		project.Description = *params.Description
+	}
+	if params.Archived != nil {
+		project.Archived = *params.Archived
+	}
+	project.UpdatedAt = time.Now()
+
+	err = s.inTx(func(tx *ProjectService) error {
+		if err := tx.repo.Update(project); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "update", "project", project.ID, before, project)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// Delete removes a project; its todos are kept and become unassigned.
+func (s *ProjectService) Delete(ctx context.Context, projectID, requestingUserID, requestingUserRole string) error {
+	project, err := s.GetByID(projectID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return err
+	}
+
+	return s.inTx(func(tx *ProjectService) error {
+		if err := tx.repo.Delete(projectID); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "delete", "project", projectID, project, nil)
+	})
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"godo/internal/domain"
+	"strings"
+)
+
+// ShareService grants other users access to a todo or a whole project. Only
+// the owner or an admin may list and grant shares; a user may also revoke a
+// share that was granted to them.
+type ShareService struct {
+	repo           domain.ShareRepository
+	userRepo       domain.UserRepository
+	audit          auditLog
+	todoService    *TodoService
+	projectService *ProjectService
+	transactor     domain.Transactor
+}
+
+func NewShareService(repo domain.ShareRepository, userRepo domain.UserRepository, auditRepo domain.AuditRepository, todoService *TodoService, projectService *ProjectService, transactor domain.Transactor) *ShareService {
+	return &ShareService{
+		repo:           repo,
+		userRepo:       userRepo,
+		audit:          auditLog{repo: auditRepo},
+		todoService:    todoService,
+		projectService: projectService,
+		transactor:     transactor,
+	}
+}
+
+// inTx runs fn on a copy of the service bound to one transaction, so a change
+// is saved along with its audit event or not at all.
+func (s *ShareService) inTx(fn func(tx *ShareService) error) error {
+	return s.transactor.InTx(func(repos domain.Repos) error {
+		return fn(&ShareService{
+			repo:           repos.Shares,
+			userRepo:       repos.Users,
+			audit:          auditLog{repo: repos.Audit},
+			todoService:    s.todoService,
+			projectService: s.projectService,
+			transactor:     joinedTx{repos: repos},
+		})
+	})
+}
+
+func (s *ShareService) TodoShares(todoID, requestingUserID, requestingUserRole string) ([]*domain.Share, error) {
+	if _, err := s.todoService.get(todoID, requestingUserID, requestingUserRole, domain.AccessOwner); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByTodoID(todoID)
+}
+
+// ShareTodo grants the user with the given email access to a todo. Sharing
+// with someone who already has a share changes its role.
+func (s *ShareService) ShareTodo(ctx context.Context, todoID, requestingUserID, requestingUserRole, email, role string) (*domain.Share, error) {
+	todo, err := s.todoService.get(todoID, requestingUserID, requestingUserRole, domain.AccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	user, shareRole, err := s.grantee(todo.UserID, email, role)
+	if err != nil {
+		return nil, err
+	}
+
+	share := domain.NewTodoShare(todoID, user.ID, shareRole)
+	return s.save(ctx, requestingUserID, share)
+}
+
+func (s *ShareService) RevokeTodoShare(ctx context.Context, todoID, shareID, requestingUserID, requestingUserRole string) error {
+	share, err := s.repo.GetByID(shareID)
+	if err != nil {
+		return err
+	}
+	if share.TodoID != todoID {
+		return domain.ErrShareNotFound
+	}
+
+	if share.UserID != requestingUserID {
+		if _, err := s.todoService.get(todoID, requestingUserID, requestingUserRole, domain.AccessOwner); err != nil {
+			return err
+		}
+	}
+
+	return s.delete(ctx, requestingUserID, share)
+}
+
+func (s *ShareService) ProjectShares(projectID, requestingUserID, requestingUserRole string) ([]*domain.Share, error) {
+	if _, err := s.projectService.GetByID(projectID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByProjectID(projectID)
+}
+
+// ShareProject grants the user with the given email access to every todo in
+// a project, including ones added later.
+func (s *ShareService) ShareProject(ctx context.Context, projectID, requestingUserID, requestingUserRole, email, role string) (*domain.Share, error) {
+	project, err := s.projectService.GetByID(projectID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return nil, err
+	}
+
+	user, shareRole, err := s.grantee(project.UserID, email, role)
+	if err != nil {
+		return nil, err
+	}
+
+	share := domain.NewProjectShare(projectID, user.ID, shareRole)
+	return s.save(ctx, requestingUserID, share)
+}
+
+func (s *ShareService) RevokeProjectShare(ctx context.Context, projectID, shareID, requestingUserID, requestingUserRole string) error {
+	share, err := s.repo.GetByID(shareID)
+	if err != nil {
+		return err
+	}
+	if share.ProjectID != projectID {
+		return domain.ErrShareNotFound
+	}
+
+	if share.UserID != requestingUserID {
+		if _, err := s.projectService.GetByID(projectID, requestingUserID, requestingUserRole); err != nil {
+			return err
+		}
+	}
+
+	return s.delete(ctx, requestingUserID, share)
+}
+
+func (s *ShareService) save(ctx context.Context, requestingUserID string, share *domain.Share) (*domain.Share, error) {
+	var saved *domain.Share
+	err := s.inTx(func(tx *ShareService) error {
+		if err := tx.repo.Save(share); err != nil {
+			return err
+		}
+
+		var err error
+		saved, err = tx.repo.GetByID(share.ID)
+		if err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, requestingUserID, "share", "share", saved.ID, nil, saved)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (s *ShareService) delete(ctx context.Context, requestingUserID string, share *domain.Share) error {
+	return s.inTx(func(tx *ShareService) error {
+		if err := tx.repo.Delete(share.ID); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "revoke", "share", share.ID, share, nil)
+	})
+}
+
+// grantee looks up the user a share is for and validates the role. Owners
+// already have full access, so sharing with them is rejected.
+func (s *ShareService) grantee(ownerID, email, role string) (*domain.User, domain.ShareRole, error) {
+	shareRole, err := domain.ParseShareRole(role)
+	if err != nil {
+		return nil, "", err
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, "", fmt.Errorf("%w: email is required", domain.ErrInvalidShare)
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		return nil, "", err
+	}
+	if user.ID == ownerID {
+		return nil, "", fmt.Errorf("%w: the owner already has access", domain.ErrInvalidShare)
+	}
+
+	return user, shareRole, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"godo/internal/domain"
+	"strings"
+	"time"
+)
+
+// SubtaskService manages a todo's checklist. Access follows the parent todo:
+// whoever may see the todo may read its subtasks, and whoever may edit it may
+// manage them.
+type SubtaskService struct {
+	repo        domain.SubtaskRepository
+	audit       auditLog
+	todoService *TodoService
+	transactor  domain.Transactor
+}
+
+func NewSubtaskService(repo domain.SubtaskRepository, auditRepo domain.AuditRepository, todoService *TodoService, transactor domain.Transactor) *SubtaskService {
+	return &SubtaskService{repo: repo, audit: auditLog{repo: auditRepo}, todoService: todoService, transactor: transactor}
+}
+
+// inTx runs fn on a copy of the service bound to one transaction, so a change
+// is saved along with its audit event or not at all.
+func (s *SubtaskService) inTx(fn func(tx *SubtaskService) error) error {
+	return s.transactor.InTx(func(repos domain.Repos) error {
+		return fn(&SubtaskService{repo: repos.Subtasks, audit: auditLog{repo: repos.Audit}, todoService: s.todoService, transactor: joinedTx{repos: repos}})
+	})
+}
+
+func (s *SubtaskService) List(todoID, requestingUserID, requestingUserRole string) ([]*domain.Subtask, error) {
+	if _, err := s.todoService.GetByID(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByTodoID(todoID)
+}
+
+func (s *SubtaskService) Create(ctx context.Context, todoID, requestingUserID, requestingUserRole, title string) (*domain.Subtask, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrInvalidInput
+	}
+
+	if _, err := s.todoService.GetForEdit(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	subtask := domain.NewSubtask(todoID, title, 0)
+	err := s.inTx(func(tx *SubtaskService) error {
+		if err := tx.repo.Create(subtask); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "create", "subtask", subtask.ID, nil, subtask)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subtask, nil
+}
+
+// Update applies a partial update; nil fields are left unchanged.
+func (s *SubtaskService) Update(ctx context.Context, todoID, subtaskID, requestingUserID, requestingUserRole string, title *string, completed *bool) (*domain.Subtask, error) {
+	subtask, err := s.get(todoID, subtaskID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(subtask)
+
+	if title != nil {
+		trimmed := strings.TrimSpace(*title)
+		if trimmed == "" {
+			return nil, ErrInvalidInput
+		}
+		subtask.Title = trimmed
+	}
+	if completed != nil {
+		subtask.Completed = *completed
+	}
+	subtask.UpdatedAt = time.Now()
+
+	err = s.inTx(func(tx *SubtaskService) error {
+		if err := tx.repo.Update(subtask); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "update", "subtask", subtask.ID, before, subtask)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subtask, nil
+}
+
+func (s *SubtaskService) Toggle(ctx context.Context, todoID, subtaskID, requestingUserID, requestingUserRole string) (*domain.Subtask, error) {
+	subtask, err := s.get(todoID, subtaskID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := !subtask.Completed
+	return s.Update(ctx, todoID, subtaskID, requestingUserID, requestingUserRole, nil, &completed)
+}
+
+func (s *SubtaskService) Delete(ctx context.Context, todoID, subtaskID, requestingUserID, requestingUserRole string) error {
+	subtask, err := s.get(todoID, subtaskID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return err
+	}
+
+	return s.inTx(func(tx *SubtaskService) error {
+		if err := tx.repo.Delete(subtaskID); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "delete", "subtask", subtaskID, subtask, nil)
+	})
+}
+
+// Reorder sets the checklist order. ids must list every subtask of the todo exactly once.
+func (s *SubtaskService) Reorder(ctx context.Context, todoID, requestingUserID, requestingUserRole string, ids []string) ([]*domain.Subtask, error) {
+	if _, err := s.todoService.GetForEdit(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetByTodoID(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) != len(current) {
+		return nil, ErrInvalidInput
+	}
+	remaining := make(map[string]bool, len(current))
+	order := make([]string, len(current))
+	for i, subtask := range current {
+		remaining[subtask.ID] = true
+		order[i] = subtask.ID
+	}
+	for _, id := range ids {
+		if !remaining[id] {
+			return nil, ErrInvalidInput
+		}
+		delete(remaining, id)
+	}
+
+	before, after := map[string][]string{"subtask_ids": order}, map[string][]string{"subtask_ids": ids}
+	err = s.inTx(func(tx *SubtaskService) error {
+		if err := tx.repo.Reorder(todoID, ids); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "reorder_subtasks", "todo", todoID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByTodoID(todoID)
+}
+
+// get loads a subtask after checking the requesting user may edit its todo. A
+// subtask requested under the wrong todo is reported as not found.
+func (s *SubtaskService) get(todoID, subtaskID, requestingUserID, requestingUserRole string) (*domain.Subtask, error) {
+	if _, err := s.todoService.GetForEdit(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	subtask, err := s.repo.GetByID(subtaskID)
+	if err != nil {
+		return nil, err
+	}
+	if subtask.TodoID != todoID {
+		return nil, domain.ErrSubtaskNotFound
+	}
+
+	return subtask, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"godo/internal/domain"
+)
+
+var ErrTagExists = errors.New("tag already exists")
+
+type TagService struct {
+	repo       domain.TagRepository
+	audit      auditLog
+	transactor domain.Transactor
+}
+
+func NewTagService(repo domain.TagRepository, auditRepo domain.AuditRepository, transactor domain.Transactor) *TagService {
+	return &TagService{repo: repo, audit: auditLog{repo: auditRepo}, transactor: transactor}
+}
+
+// inTx runs fn on a copy of the service bound to one transaction, so a change
+// is saved along with its audit event or not at all.
+func (s *TagService) inTx(fn func(tx *TagService) error) error {
+	return s.transactor.InTx(func(repos domain.Repos) error {
+		return fn(&TagService{repo: repos.Tags, audit: auditLog{repo: repos.Audit}, transactor: joinedTx{repos: repos}})
+	})
+}
+
+func (s *TagService) Create(ctx context.Context, userID, name string) (*domain.Tag, error) {
+	name, err := domain.NormalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureNameFree(userID, name); err != nil {
+		return nil, err
+	}
+
+	tag := domain.NewTag(userID, name)
+	err = s.inTx(func(tx *TagService) error {
+		if err := tx.repo.Create(tag); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, userID, "create", "tag", tag.ID, nil, tag)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (s *TagService) GetByID(tagID, requestingUserID, requestingUserRole string) (*domain.Tag, error) {
+	tag, err := s.repo.GetByID(tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	if requestingUserRole != domain.RoleAdmin && tag.UserID != requestingUserID {
+		return nil, ErrForbidden
+	}
+
+	return tag, nil
+}
+
+// List returns the requesting user's own tags. Tags are per user, so admins
+// see only theirs too.
+func (s *TagService) List(requestingUserID string) ([]*domain.Tag, error) {
+	return s.repo.GetByUserID(requestingUserID)
+}
+
+func (s *TagService) Update(ctx context.Context, tagID, requestingUserID, requestingUserRole, name string) (*domain.Tag, error) {
+	tag, err := s.GetByID(tagID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err = domain.NormalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != tag.Name {
+		if err := s.ensureNameFree(tag.UserID, name); err != nil {
+			return nil, err
+		}
+	}
+
+	before := snapshot(tag)
+	tag.Name = name
+	err = s.inTx(func(tx *TagService) error {
+		if err := tx.repo.Update(tag); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "update", "tag", tag.ID, before, tag)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (s *TagService) Delete(ctx context.Context, tagID, requestingUserID, requestingUserRole string) error {
+	tag, err := s.GetByID(tagID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return err
+	}
+
+	return s.inTx(func(tx *TagService) error {
+		if err := tx.repo.Delete(tagID); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "delete", "tag", tagID, tag, nil)
+	})
+}
+
+func (s *TagService) ensureNameFree(userID, name string) error {
+	_, err := s.repo.GetByName(userID, name)
+	if err == nil {
+		return ErrTagExists
+	}
+	if !errors.Is(err, domain.ErrTagNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"godo/internal/domain"
+)
+
+// MaxBulkTodos caps how many todos one bulk request may change.
+const MaxBulkTodos = 500
+
+// BulkAction is a change applied to many todos at once.
+type BulkAction string
+
+const (
+	BulkComplete   BulkAction = "complete"
+	BulkUncomplete BulkAction = "uncomplete"
+	// BulkDelete moves the todos to the trash.
+	BulkDelete BulkAction = "delete"
+	// BulkMove files the todos under BulkParams.ProjectID, or takes them out
+	// of their project when it is empty.
+	BulkMove BulkAction = "move"
+	// BulkTag adds BulkParams.Tags to the todos' existing tags.
+	BulkTag BulkAction = "tag"
+)
+
+// BulkParams selects the todos to change, either by ID or, when IDs is
+// empty, every todo the requesting user could list with Filter.
+type BulkParams struct {
+	Action    BulkAction
+	IDs       []string
+	Filter    *domain.TodoFilter
+	ProjectID string
+	Tags      []string
+}
+
+// Per-todo outcomes of a bulk change.
+const (
+	BulkOK        = "ok"
+	BulkNotFound  = "not_found"
+	BulkForbidden = "forbidden"
+	BulkInvalid   = "invalid"
+	BulkBlocked   = "blocked"
+)
+
+// BulkResult reports what happened to one todo of a bulk change.
+type BulkResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Bulk applies one action to many todos in a single transaction. Each todo
+// goes through the same authorization and rules as changing it on its own,
+// and a todo that may not be changed is reported in its result without
+// stopping the others. Any other failure rolls the whole change back.
+func (s *TodoService) Bulk(ctx context.Context, requestingUserID, requestingUserRole string, params BulkParams) ([]BulkResult, error) {
+	switch params.Action {
+	case BulkComplete, BulkUncomplete, BulkDelete, BulkMove:
+	case BulkTag:
+		if len(params.Tags) == 0 {
+			return nil, fmt.Errorf("%w: tags are required", ErrInvalidInput)
+		}
+		for _, name := range params.Tags {
+			if _, err := domain.NormalizeTagName(name); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: action must be one of complete, uncomplete, delete, move, tag", ErrInvalidInput)
+	}
+	if len(params.IDs) == 0 && params.Filter == nil {
+		return nil, fmt.Errorf("%w: ids or a filter is required", ErrInvalidInput)
+	}
+
+	var results []BulkResult
+	err := s.inTx(func(tx *TodoService) error {
+		// Users move todos into their own projects only. Admins may move
+		// anyone's todos, so whether the project fits is checked per todo.
+		if params.Action == BulkMove && params.ProjectID != "" && requestingUserRole != domain.RoleAdmin {
+			if err := tx.checkProject(requestingUserID, params.ProjectID); err != nil {
+				return err
+			}
+		}
+
+		ids, err := tx.bulkIDs(requestingUserID, requestingUserRole, params)
+		if err != nil {
+			return err
+		}
+
+		results = make([]BulkResult, 0, len(ids))
+		for _, id := range ids {
+			result := BulkResult{ID: id, Status: BulkOK}
+			if err := tx.bulkApply(ctx, id, requestingUserID, requestingUserRole, params); err != nil {
+				result.Status = bulkStatus(err)
+				if result.Status == "" {
+					return err
+				}
+				result.Error = err.Error()
+			}
+			results = append(results, result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// bulkIDs resolves the todos a bulk change applies to, without duplicates.
+func (s *TodoService) bulkIDs(requestingUserID, requestingUserRole string, params BulkParams) ([]string, error) {
+	if len(params.IDs) == 0 {
+		todos, _, err := s.List(requestingUserID, requestingUserRole, *params.Filter, domain.PageRequest{Limit: MaxBulkTodos + 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(todos) > MaxBulkTodos {
+			return nil, fmt.Errorf("%w: the filter matches more than %d todos", ErrInvalidInput, MaxBulkTodos)
+		}
+
+		ids := make([]string, len(todos))
+		for i, todo := range todos {
+			ids[i] = todo.ID
+		}
+		return ids, nil
+	}
+
+	seen := make(map[string]bool, len(params.IDs))
+	ids := make([]string, 0, len(params.IDs))
+	for _, id := range params.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > MaxBulkTodos {
+		return nil, fmt.Errorf("%w: at most %d todos may be changed at once", ErrInvalidInput, MaxBulkTodos)
+	}
+
+	return ids, nil
+}
+
+func (s *TodoService) bulkApply(ctx context.Context, todoID, requestingUserID, requestingUserRole string, params BulkParams) error {
+	switch params.Action {
+	case BulkComplete, BulkUncomplete:
+		completed := params.Action == BulkComplete
+		_, err := s.Update(ctx, todoID, requestingUserID, requestingUserRole, UpdateTodoParams{Completed: &completed})
+		return err
+	case BulkDelete:
+		return s.Delete(ctx, todoID, requestingUserID, requestingUserRole, false)
+	case BulkMove:
+		_, err := s.Update(ctx, todoID, requestingUserID, requestingUserRole, UpdateTodoParams{ProjectID: &params.ProjectID})
+		return err
+	case BulkTag:
+		todo, err := s.GetForEdit(todoID, requestingUserID, requestingUserRole)
+		if err != nil {
+			return err
+		}
+		tags := append(append([]string{}, todo.Tags...), params.Tags...)
+		_, err = s.Update(ctx, todoID, requestingUserID, requestingUserRole, UpdateTodoParams{Tags: &tags})
+		return err
+	}
+	return nil
+}
+
+// bulkStatus classifies an error that only concerns one todo of a bulk
+// change. It returns "" for failures that should abort the whole change.
+// The errors it classifies are all raised before the todo is written, so a
+// todo reported as not changed leaves nothing behind in the transaction.
+func bulkStatus(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrTodoNotFound):
+		return BulkNotFound
+	case errors.Is(err, ErrForbidden):
+		return BulkForbidden
+	case errors.Is(err, domain.ErrTodoBlocked):
+		return BulkBlocked
+	case errors.Is(err, domain.ErrInvalidProject), errors.Is(err, domain.ErrInvalidTag):
+		return BulkInvalid
+	}
+	return ""
+}
@@ -1,79 +1,1049 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"godo/internal/domain"
+	"sort"
 	"time"
 )
 
 type TodoService struct {
-	repo domain.TodoRepository
+	repo             domain.TodoRepository
+	tagRepo          domain.TagRepository
+	subtaskRepo      domain.SubtaskRepository
+	projectRepo      domain.ProjectRepository
+	shareRepo        domain.ShareRepository
+	userRepo         domain.UserRepository
+	dependencyRepo   domain.DependencyRepository
+	revisionRepo     domain.RevisionRepository
+	audit            auditLog
+	transactor       domain.Transactor
+	deletePolicy     DeletePolicy
+	completionPolicy CompletionPolicy
 }
 
-func NewTodoService(repo domain.TodoRepository) *TodoService {
-	return &TodoService{repo: repo}
+// NewTodoService builds the service on repos. Changes spanning several rows run
+// through transactor, against the same repositories bound to one transaction.
+func NewTodoService(repos domain.Repos, transactor domain.Transactor, deletePolicy DeletePolicy, completionPolicy CompletionPolicy) *TodoService {
+	s := &TodoService{transactor: transactor, deletePolicy: deletePolicy, completionPolicy: completionPolicy}
+	return s.withRepos(repos)
 }
 
-func (s *TodoService) Create(userID, title, description string) (*domain.Todo, error) {
-	todo := domain.NewTodo(userID, title, description)
-	if err := s.repo.Create(todo); err != nil {
+// withRepos returns a copy of the service that works on repos, e.g. ones
+// bound to a transaction.
+func (s *TodoService) withRepos(repos domain.Repos) *TodoService {
+	bound := *s
+	bound.repo = repos.Todos
+	bound.tagRepo = repos.Tags
+	bound.subtaskRepo = repos.Subtasks
+	bound.projectRepo = repos.Projects
+	bound.shareRepo = repos.Shares
+	bound.userRepo = repos.Users
+	bound.dependencyRepo = repos.Dependencies
+	bound.audit = auditLog{repo: repos.Audit}
+	bound.revisionRepo = repos.Revisions
+	return &bound
+}
+
+// inTx runs fn on a copy of the service bound to one transaction, so that
+// everything fn changes is saved together or not at all. A service that is
+// already bound to a transaction runs fn in that same transaction.
+func (s *TodoService) inTx(fn func(tx *TodoService) error) error {
+	return s.transactor.InTx(func(repos domain.Repos) error {
+		tx := s.withRepos(repos)
+		tx.transactor = joinedTx{repos: repos}
+		return fn(tx)
+	})
+}
+
+// joinedTx is the transactor of a service bound to a transaction: further
+// work joins the transaction instead of opening another one.
+type joinedTx struct {
+	repos domain.Repos
+}
+
+func (t joinedTx) InTx(fn func(repos domain.Repos) error) error {
+	return fn(t.repos)
+}
+
+// CompletionPolicy decides when a todo may be marked done.
+type CompletionPolicy struct {
+	// RequireBlockersDone refuses to complete a todo while any todo it
+	// depends on is still open.
+	RequireBlockersDone bool
+}
+
+// DeletePolicy decides what owners may delete. Admins may always delete any
+// todo, to the trash or permanently.
+type DeletePolicy struct {
+	// OwnersCanDelete lets owners move their own todos to the trash.
+	OwnersCanDelete bool
+	// OwnersCanHardDelete lets owners also delete their own todos
+	// permanently, skipping or emptying the trash.
+	OwnersCanHardDelete bool
+}
+
+// DefaultDeletePolicy lets owners trash their own todos but leaves permanent
+// deletes to admins.
+func DefaultDeletePolicy() DeletePolicy {
+	return DeletePolicy{OwnersCanDelete: true}
+}
+
+func (p DeletePolicy) allows(todo *domain.Todo, requestingUserID, requestingUserRole string, hard bool) bool {
+	if requestingUserRole == domain.RoleAdmin {
+		return true
+	}
+	if todo.UserID != requestingUserID {
+		return false
+	}
+	if hard {
+		return p.OwnersCanHardDelete
+	}
+	return p.OwnersCanDelete
+}
+
+type CreateTodoParams struct {
+	ProjectID   string
+	Title       string
+	Description string
+	Priority    string
+	DueDate     string
+	DueTime     string
+	DueTimezone string
+	Tags        []string
+	Recurrence  string
+}
+
+// UpdateTodoParams holds a partial update; nil fields are left unchanged.
+// Setting ProjectID, DueDate or Recurrence to "" clears it; a non-nil Tags
+// replaces every tag.
+type UpdateTodoParams struct {
+	ProjectID   *string
+	Title       *string
+	Description *string
+	Completed   *bool
+	Priority    *string
+	DueDate     *string
+	DueTime     *string
+	DueTimezone *string
+	Tags        *[]string
+	Recurrence  *string
+}
+
+// onlyCompletion reports whether the update just marks the todo done or open,
+// which is all an assignee may do.
+func (p UpdateTodoParams) onlyCompletion() bool {
+	return p.Completed != nil && p.ProjectID == nil && p.Title == nil && p.Description == nil && p.Priority == nil &&
+		p.DueDate == nil && p.DueTime == nil && p.DueTimezone == nil && p.Tags == nil && p.Recurrence == nil
+}
+
+func (s *TodoService) Create(ctx context.Context, userID string, params CreateTodoParams) (*domain.Todo, error) {
+	todo := domain.NewTodo(userID, params.Title, params.Description)
+
+	if params.ProjectID != "" {
+		if err := s.checkProject(userID, params.ProjectID); err != nil {
+			return nil, err
+		}
+		todo.ProjectID = params.ProjectID
+	}
+
+	priority, err := domain.ParsePriority(params.Priority)
+	if err != nil {
 		return nil, err
 	}
+	todo.Priority = priority
+
+	if params.DueDate != "" {
+		due, err := domain.NewDue(params.DueDate, params.DueTime, params.DueTimezone)
+		if err != nil {
+			return nil, err
+		}
+		todo.Due = due
+	}
+
+	if params.Recurrence != "" {
+		recurrence, err := domain.ParseRecurrence(params.Recurrence)
+		if err != nil {
+			return nil, err
+		}
+		todo.Recurrence = recurrence
+	}
+
+	// The todo is only saved along with its tags
+	err = s.inTx(func(tx *TodoService) error {
+		if err := tx.repo.Create(todo); err != nil {
+			return err
+		}
+
+		if len(params.Tags) > 0 {
+			if err := tx.setTags(todo, params.Tags); err != nil {
+				return err
+			}
+		}
+
+		return tx.audit.record(ctx, userID, "create", "todo", todo.ID, nil, todo)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return todo, nil
 }
 
+// GetByID returns a todo the requesting user owns, is assigned to, or that
+// was shared with them at any level.
 func (s *TodoService) GetByID(todoID, requestingUserID, requestingUserRole string) (*domain.Todo, error) {
+	return s.get(todoID, requestingUserID, requestingUserRole, domain.AccessView)
+}
+
+// GetForEdit returns a todo the requesting user may change: their own, or one
+// shared with them as an editor.
+func (s *TodoService) GetForEdit(todoID, requestingUserID, requestingUserRole string) (*domain.Todo, error) {
+	return s.get(todoID, requestingUserID, requestingUserRole, domain.AccessEdit)
+}
+
+func (s *TodoService) get(todoID, requestingUserID, requestingUserRole string, need domain.Access) (*domain.Todo, error) {
 	todo, err := s.repo.GetByID(todoID)
 	if err != nil {
 		return nil, err
 	}
 
-	if requestingUserRole != domain.RoleAdmin && todo.UserID != requestingUserID {
+	access, err := s.access(todo, requestingUserID, requestingUserRole)
+	if err != nil {
+		return nil, err
+	}
+	if access < need {
 		return nil, ErrForbidden
 	}
 
 	return todo, nil
 }
 
-func (s *TodoService) List(requestingUserID, requestingUserRole string) ([]*domain.Todo, error) {
+// access works out what the requesting user may do with a todo. Owners and
+// admins hold full access; everyone else gets what their shares of the todo
+// or its project grant, and assignees may at least complete it.
+func (s *TodoService) access(todo *domain.Todo, requestingUserID, requestingUserRole string) (domain.Access, error) {
+	if requestingUserRole == domain.RoleAdmin || todo.UserID == requestingUserID {
+		return domain.AccessOwner, nil
+	}
+
+	role, err := s.shareRepo.RoleFor(requestingUserID, todo.ID, todo.ProjectID)
+	if err != nil {
+		return domain.AccessNone, err
+	}
+
+	access := role.Access()
+	if todo.AssigneeID == requestingUserID {
+		access = max(access, domain.AccessComplete)
+	}
+
+	return access, nil
+}
+
+// List returns one page of the todos the requesting user may see along with
+// the cursor of the next page, which is "" on the last page. Only admins may
+// filter by another user. Todos of a project shared with the user are listed
+// when filtering by that project, filter.Shared lists everything shared with
+// them, and filtering by themselves as assignee lists everything assigned to
+// them whoever owns it.
+func (s *TodoService) List(requestingUserID, requestingUserRole string, filter domain.TodoFilter, page domain.PageRequest) ([]*domain.Todo, string, error) {
+	if filter.Shared {
+		return s.repo.GetSharedWith(requestingUserID, filter, page)
+	}
+
+	if requestingUserRole == domain.RoleAdmin {
+		return s.repo.GetAll(filter, page)
+	}
+
+	if filter.UserID != "" && filter.UserID != requestingUserID {
+		return nil, "", ErrForbidden
+	}
+
+	if filter.AssigneeID == requestingUserID {
+		return s.repo.GetAll(filter, page)
+	}
+
+	if filter.ProjectID != "" {
+		shared, err := s.projectSharedWith(filter.ProjectID, requestingUserID)
+		if err != nil {
+			return nil, "", err
+		}
+		if shared {
+			return s.repo.GetAll(filter, page)
+		}
+	}
+
+	return s.repo.GetByUserID(requestingUserID, filter, page)
+}
+
+// projectSharedWith reports whether another user's project was shared with
+// the user.
+func (s *TodoService) projectSharedWith(projectID, userID string) (bool, error) {
+	project, err := s.projectRepo.GetByID(projectID)
+	if errors.Is(err, domain.ErrProjectNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if project.UserID == userID {
+		return false, nil
+	}
+
+	role, err := s.shareRepo.RoleFor(userID, "", projectID)
+	if err != nil {
+		return false, err
+	}
+
+	return role != "", nil
+}
+
+// Search runs a full-text search over the todos the requesting user may see:
+// their own, or everyone's for admins.
+func (s *TodoService) Search(requestingUserID, requestingUserRole, query string, limit int) ([]*domain.SearchResult, error) {
 	if requestingUserRole == domain.RoleAdmin {
-		return s.repo.GetAll()
+		return s.repo.Search("", query, limit)
+	}
+
+	return s.repo.Search(requestingUserID, query, limit)
+}
+
+// Update applies a partial update. Editors the todo was shared with may change
+// everything but its project, which stays the owner's call. Assignees may only
+// mark it done or open. The todo, its tags and checklist, and the next
+// occurrence of a recurring todo are saved in one transaction.
+func (s *TodoService) Update(ctx context.Context, todoID, requestingUserID, requestingUserRole string, params UpdateTodoParams) (*domain.Todo, error) {
+	var todo *domain.Todo
+	err := s.inTx(func(tx *TodoService) error {
+		var err error
+		todo, err = tx.update(ctx, todoID, requestingUserID, requestingUserRole, params)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.repo.GetByUserID(requestingUserID)
+	return todo, nil
 }
 
-func (s *TodoService) Update(todoID, requestingUserID, requestingUserRole string, title, description *string, completed *bool) (*domain.Todo, error) {
+func (s *TodoService) update(ctx context.Context, todoID, requestingUserID, requestingUserRole string, params UpdateTodoParams) (*domain.Todo, error) {
 	todo, err := s.repo.GetByID(todoID)
 	if err != nil {
 		return nil, err
 	}
 
-	if requestingUserRole != domain.RoleAdmin && todo.UserID != requestingUserID {
+	access, err := s.access(todo, requestingUserID, requestingUserRole)
+	if err != nil {
+		return nil, err
+	}
+	need := domain.AccessEdit
+	if params.onlyCompletion() {
+		need = domain.AccessComplete
+	}
+	if access < need {
 		return nil, ErrForbidden
 	}
+	before := snapshot(todo)
+	baseline := domain.NewTodoRevision(todo.ID, 1, "", todo.State())
+	baseline.CreatedAt = todo.UpdatedAt
 
-	if title != nil {
-		todo.Title = *title
+	if params.ProjectID != nil && *params.ProjectID != todo.ProjectID {
+		if access < domain.AccessOwner {
+			return nil, ErrForbidden
+		}
+		if *params.ProjectID != "" {
+			if err := s.checkProject(todo.UserID, *params.ProjectID); err != nil {
+				return nil, err
+			}
+		}
+		todo.ProjectID = *params.ProjectID
+	}
+	if params.Title != nil {
+		todo.Title = *params.Title
+	}
+	if params.Description != nil {
+		todo.Description = *params.Description
 	}
-	if description != nil {
-		todo.Description = *description
+	completing := params.Completed != nil && *params.Completed && !todo.Completed
+	if completing && todo.Blocked && s.completionPolicy.RequireBlockersDone {
+		return nil, domain.ErrTodoBlocked
 	}
-	if completed != nil {
-		todo.Completed = *completed
+	if params.Completed != nil {
+		todo.Completed = *params.Completed
+	}
+	if params.Priority != nil {
+		priority, err := domain.ParsePriority(*params.Priority)
+		if err != nil {
+			return nil, err
+		}
+		todo.Priority = priority
+	}
+	if params.DueDate != nil || params.DueTime != nil || params.DueTimezone != nil {
+		due, err := mergeDue(todo.Due, params)
+		if err != nil {
+			return nil, err
+		}
+		todo.Due = due
+	}
+	// Tags are saved after the todo, so their names are checked before
+	// anything is written
+	if params.Tags != nil {
+		for _, name := range *params.Tags {
+			if _, err := domain.NormalizeTagName(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if params.Recurrence != nil {
+		todo.Recurrence = nil
+		if *params.Recurrence != "" {
+			recurrence, err := domain.ParseRecurrence(*params.Recurrence)
+			if err != nil {
+				return nil, err
+			}
+			todo.Recurrence = recurrence
+		}
 	}
 	todo.UpdatedAt = time.Now()
 
+	// Reopening a todo takes it out of the archive, since only completed
+	// todos are archived
+	if completing {
+		todo.CompletedAt = &todo.UpdatedAt
+	} else if !todo.Completed {
+		todo.CompletedAt = nil
+		todo.ArchivedAt = nil
+	}
+
+	// The series moves on to the next occurrence, so completing this todo
+	// again later won't spawn a second copy
+	var next *domain.Todo
+	if completing {
+		if n, ok := todo.Recur(todo.UpdatedAt); ok {
+			next = n
+			todo.Recurrence = nil
+		}
+	}
+
 	if err := s.repo.Update(todo); err != nil {
 		return nil, err
 	}
 
+	// Completing a todo completes its checklist too
+	if completing && todo.Progress.Done < todo.Progress.Total {
+		if err := s.subtaskRepo.CompleteAll(todo.ID); err != nil {
+			return nil, err
+		}
+		todo.Progress.Done = todo.Progress.Total
+	}
+
+	if params.Tags != nil {
+		if err := s.setTags(todo, *params.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if next != nil {
+		if err := s.createOccurrence(todo, next); err != nil {
+			return nil, err
+		}
+		todo.NextOccurrence = next
+	}
+
+	if err := s.recordRevision(todo, baseline, requestingUserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit.record(ctx, requestingUserID, "update", "todo", todo.ID, before, todo); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// recordRevision adds the updated todo to its history. The first update
+// recorded for a todo also records baseline, the state it had before, so
+// that every change can be reverted. Updates that change nothing are skipped.
+func (s *TodoService) recordRevision(todo *domain.Todo, baseline *domain.TodoRevision, userID string) error {
+	latest, err := s.revisionRepo.LatestNumber(todo.ID)
+	if err != nil {
+		return err
+	}
+
+	previous := baseline
+	if latest > 0 {
+		if previous, err = s.revisionRepo.GetByNumber(todo.ID, latest); err != nil {
+			return err
+		}
+	}
+
+	state := todo.State()
+	changes, err := domain.AuditDiff(previous.State, state)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if latest == 0 {
+		if err := s.revisionRepo.Create(baseline); err != nil {
+			return err
+		}
+		latest = baseline.Revision
+	}
+
+	revision := domain.NewTodoRevision(todo.ID, latest+1, userID, state)
+	revision.Changes = changes
+	return s.revisionRepo.Create(revision)
+}
+
+// History lists the revisions of a todo the requesting user can see, newest
+// first. A todo that was never updated has no history.
+func (s *TodoService) History(todoID, requestingUserID, requestingUserRole string) ([]*domain.TodoRevision, error) {
+	if _, err := s.GetByID(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	return s.revisionRepo.GetByTodoID(todoID)
+}
+
+// Revert restores a todo to the state it had at the given revision. This is
+// an ordinary update, so it needs edit access and adds a revision of its own.
+func (s *TodoService) Revert(ctx context.Context, todoID string, number int, requestingUserID, requestingUserRole string) (*domain.Todo, error) {
+	if _, err := s.GetForEdit(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	revision, err := s.revisionRepo.GetByNumber(todoID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Update(ctx, todoID, requestingUserID, requestingUserRole, revertParams(revision.State))
+}
+
+// revertParams builds the update that sets every field of a todo to state.
+func revertParams(state domain.TodoState) UpdateTodoParams {
+	priority := state.Priority.String()
+	tags := append([]string{}, state.Tags...)
+
+	var dueDate, dueTime, dueTimezone string
+	if state.Due != nil {
+		dueDate, dueTime, dueTimezone = state.Due.Date, state.Due.Time, state.Due.Timezone
+	}
+
+	var recurrence string
+	if state.Recurrence != nil {
+		recurrence = state.Recurrence.String()
+	}
+
+	return UpdateTodoParams{
+		ProjectID:   &state.ProjectID,
+		Title:       &state.Title,
+		Description: &state.Description,
+		Completed:   &state.Completed,
+		Priority:    &priority,
+		DueDate:     &dueDate,
+		DueTime:     &dueTime,
+		DueTimezone: &dueTimezone,
+		Tags:        &tags,
+		Recurrence:  &recurrence,
+	}
+}
+
+// Assign makes assigneeID responsible for a todo, or clears the assignee when
+// it is empty. Anyone who may edit the todo may assign it.
+func (s *TodoService) Assign(ctx context.Context, todoID, requestingUserID, requestingUserRole, assigneeID string) (*domain.Todo, error) {
+	todo, err := s.GetForEdit(todoID, requestingUserID, requestingUserRole)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(todo)
+
+	todo.AssigneeEmail = ""
+	if assigneeID != "" {
+		assignee, err := s.userRepo.GetByID(assigneeID)
+		if err != nil {
+			return nil, err
+		}
+		todo.AssigneeEmail = assignee.Email
+	}
+
+	todo.AssigneeID = assigneeID
+	todo.UpdatedAt = time.Now()
+	err = s.inTx(func(tx *TodoService) error {
+		if err := tx.repo.Update(todo); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "assign", "todo", todo.ID, before, todo)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return todo, nil
 }
 
-func (s *TodoService) Delete(todoID, requestingUserID, requestingUserRole string) error {
-	if requestingUserRole != domain.RoleAdmin {
+// Blockers lists the todos a todo depends on. Blockers since moved to the
+// trash are left out.
+func (s *TodoService) Blockers(todoID, requestingUserID, requestingUserRole string) ([]*domain.Todo, error) {
+	if _, err := s.GetByID(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.dependencyRepo.GetBlockerIDs(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	blockers := make([]*domain.Todo, 0, len(ids))
+	for _, id := range ids {
+		blocker, err := s.repo.GetByID(id)
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		blockers = append(blockers, blocker)
+	}
+
+	return blockers, nil
+}
+
+// AddBlocker makes a todo depend on blockerID, so it stays blocked until the
+// blocker is done. The requesting user must be able to edit the todo and see
+// the blocker, and the dependency may not close a cycle.
+func (s *TodoService) AddBlocker(ctx context.Context, todoID, blockerID, requestingUserID, requestingUserRole string) (*domain.Todo, error) {
+	if todoID == blockerID {
+		return nil, fmt.Errorf("%w: a todo cannot block itself", domain.ErrInvalidDependency)
+	}
+
+	if _, err := s.GetForEdit(todoID, requestingUserID, requestingUserRole); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetByID(blockerID, requestingUserID, requestingUserRole); err != nil {
+		if errors.Is(err, domain.ErrTodoNotFound) {
+			return nil, fmt.Errorf("%w: blocker not found", domain.ErrInvalidDependency)
+		}
+		return nil, err
+	}
+
+	cycle, err := s.dependsOn(blockerID, todoID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle {
+		return nil, domain.ErrDependencyCycle
+	}
+
+	blocker := map[string]string{"blocker_id": blockerID}
+	err = s.inTx(func(tx *TodoService) error {
+		if err := tx.dependencyRepo.Add(todoID, blockerID); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "add_blocker", "todo", todoID, nil, blocker)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(todoID)
+}
+
+func (s *TodoService) RemoveBlocker(ctx context.Context, todoID, blockerID, requestingUserID, requestingUserRole string) error {
+	if _, err := s.GetForEdit(todoID, requestingUserID, requestingUserRole); err != nil {
+		return err
+	}
+
+	blocker := map[string]string{"blocker_id": blockerID}
+	return s.inTx(func(tx *TodoService) error {
+		if err := tx.dependencyRepo.Remove(todoID, blockerID); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "remove_blocker", "todo", todoID, blocker, nil)
+	})
+}
+
+// dependsOn reports whether todoID depends on targetID, directly or through
+// other todos, by walking the blockers breadth first.
+func (s *TodoService) dependsOn(todoID, targetID string) (bool, error) {
+	seen := map[string]bool{todoID: true}
+	queue := []string{todoID}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		blockerIDs, err := s.dependencyRepo.GetBlockerIDs(id)
+		if err != nil {
+			return false, err
+		}
+		for _, blockerID := range blockerIDs {
+			if blockerID == targetID {
+				return true, nil
+			}
+			if !seen[blockerID] {
+				seen[blockerID] = true
+				queue = append(queue, blockerID)
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// createOccurrence saves the next todo of a recurring series along with the
+// tags and a fresh copy of the checklist of the todo it follows. It runs in
+// the transaction that completes the previous todo, so the series is never
+// left without a next occurrence.
+func (s *TodoService) createOccurrence(previous, next *domain.Todo) error {
+	if err := s.repo.Create(next); err != nil {
+		return err
+	}
+
+	if len(next.Tags) > 0 {
+		if err := s.setTags(next, next.Tags); err != nil {
+			return err
+		}
+	}
+
+	subtasks, err := s.subtaskRepo.GetByTodoID(previous.ID)
+	if err != nil {
+		return err
+	}
+	for _, subtask := range subtasks {
+		if err := s.subtaskRepo.Create(domain.NewSubtask(next.ID, subtask.Title, 0)); err != nil {
+			return err
+		}
+	}
+	next.Progress = domain.Progress{Total: len(subtasks)}
+
+	return nil
+}
+
+// Move places a todo in its owner's manual order, directly after the todo
+// afterID and before the todo beforeID. Either may be empty to only pin one
+// side, and only the moved todo is rewritten.
+func (s *TodoService) Move(ctx context.Context, todoID, requestingUserID, requestingUserRole, afterID, beforeID string) (*domain.Todo, error) {
+	if afterID == "" && beforeID == "" {
+		return nil, fmt.Errorf("%w: after_id or before_id is required", domain.ErrInvalidPosition)
+	}
+
+	todo, err := s.get(todoID, requestingUserID, requestingUserRole, domain.AccessOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	var lower, upper string
+	if afterID != "" {
+		if lower, err = s.neighborPosition(todo, afterID); err != nil {
+			return nil, err
+		}
+	}
+	if beforeID != "" {
+		if upper, err = s.neighborPosition(todo, beforeID); err != nil {
+			return nil, err
+		}
+	}
+	if afterID == "" {
+		if lower, err = s.repo.AdjacentPosition(todo.UserID, upper, true); err != nil {
+			return nil, err
+		}
+	}
+	if beforeID == "" {
+		if upper, err = s.repo.AdjacentPosition(todo.UserID, lower, false); err != nil {
+			return nil, err
+		}
+	}
+
+	position, err := domain.RankBetween(lower, upper)
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshot(todo)
+	err = s.inTx(func(tx *TodoService) error {
+		if err := tx.repo.SetPosition(todo.ID, position); err != nil {
+			return err
+		}
+		todo.Position = position
+		return tx.audit.record(ctx, requestingUserID, "move", "todo", todo.ID, before, todo)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// neighborPosition looks up a todo the moved todo should sit next to. It has
+// to be another todo in the same user's list.
+func (s *TodoService) neighborPosition(todo *domain.Todo, neighborID string) (string, error) {
+	if neighborID == todo.ID {
+		return "", fmt.Errorf("%w: a todo cannot be moved next to itself", domain.ErrInvalidPosition)
+	}
+
+	neighbor, err := s.repo.GetByID(neighborID)
+	if errors.Is(err, domain.ErrTodoNotFound) {
+		return "", fmt.Errorf("%w: todo %s does not exist", domain.ErrInvalidPosition, neighborID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if neighbor.UserID != todo.UserID {
+		return "", fmt.Errorf("%w: todo %s does not exist", domain.ErrInvalidPosition, neighborID)
+	}
+
+	return neighbor.Position, nil
+}
+
+// Delete moves a todo to the trash, from where it can be restored until it
+// is purged. A hard delete removes it for good instead, and also works on
+// todos already in the trash. What owners may do is up to the DeletePolicy.
+func (s *TodoService) Delete(ctx context.Context, todoID, requestingUserID, requestingUserRole string, hard bool) error {
+	todo, err := s.repo.GetByID(todoID)
+	if hard && errors.Is(err, domain.ErrTodoNotFound) {
+		todo, err = s.repo.GetTrashedByID(todoID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !s.deletePolicy.allows(todo, requestingUserID, requestingUserRole, hard) {
 		return ErrForbidden
 	}
 
-	return s.repo.Delete(todoID)
+	action := "delete"
+	if hard {
+		action = "hard_delete"
+	}
+
+	return s.inTx(func(tx *TodoService) error {
+		var err error
+		if hard {
+			err = tx.repo.HardDelete(todo.ID)
+		} else {
+			err = tx.repo.Delete(todo.ID)
+		}
+		if err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, action, "todo", todo.ID, todo, nil)
+	})
+}
+
+// Trash returns one page of the deleted todos the requesting user may see:
+// their own, or everyone's for admins.
+func (s *TodoService) Trash(requestingUserID, requestingUserRole string, page domain.PageRequest) ([]*domain.Todo, string, error) {
+	if requestingUserRole == domain.RoleAdmin {
+		return s.repo.GetTrash("", page)
+	}
+
+	return s.repo.GetTrash(requestingUserID, page)
+}
+
+// Restore takes a todo out of the trash. Owners may restore their own todos.
+func (s *TodoService) Restore(ctx context.Context, todoID, requestingUserID, requestingUserRole string) (*domain.Todo, error) {
+	todo, err := s.repo.GetTrashedByID(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if requestingUserRole != domain.RoleAdmin && todo.UserID != requestingUserID {
+		return nil, ErrForbidden
+	}
+
+	var restored *domain.Todo
+	err = s.inTx(func(tx *TodoService) error {
+		if err := tx.repo.Restore(todo.ID); err != nil {
+			return err
+		}
+
+		var err error
+		restored, err = tx.repo.GetByID(todo.ID)
+		if err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, requestingUserID, "restore", "todo", todo.ID, todo, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// PurgeTrash permanently removes the todos that have been in the trash for
+// longer than retention and reports how many were removed.
+func (s *TodoService) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	var purged int64
+	err := s.inTx(func(tx *TodoService) error {
+		var err error
+		purged, err = tx.repo.Purge(time.Now().Add(-retention))
+		if err != nil || purged == 0 {
+			return err
+		}
+		return tx.audit.record(ctx, "", "purge", "todo", "", nil, map[string]int64{"count": purged})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// Archive returns one page of the archived todos the requesting user may
+// see: their own, or everyone's for admins.
+func (s *TodoService) Archive(requestingUserID, requestingUserRole string, page domain.PageRequest) ([]*domain.Todo, string, error) {
+	if requestingUserRole == domain.RoleAdmin {
+		return s.repo.GetArchive("", page)
+	}
+
+	return s.repo.GetArchive(requestingUserID, page)
+}
+
+// Unarchive brings an archived todo back into the todo list. Owners may
+// unarchive their own todos.
+func (s *TodoService) Unarchive(ctx context.Context, todoID, requestingUserID, requestingUserRole string) (*domain.Todo, error) {
+	todo, err := s.repo.GetByID(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	if requestingUserRole != domain.RoleAdmin && todo.UserID != requestingUserID {
+		return nil, ErrForbidden
+	}
+
+	var unarchived *domain.Todo
+	err = s.inTx(func(tx *TodoService) error {
+		if err := tx.repo.Unarchive(todo.ID); err != nil {
+			return err
+		}
+
+		var err error
+		unarchived, err = tx.repo.GetByID(todo.ID)
+		if err != nil {
+			return err
+		}
+
+		return tx.audit.record(ctx, requestingUserID, "unarchive", "todo", todo.ID, todo, unarchived)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return unarchived, nil
+}
+
+// AutoArchive archives the completed todos of users who turned
+// auto-archiving on and reports how many were archived.
+func (s *TodoService) AutoArchive(ctx context.Context) (int64, error) {
+	var archived int64
+	err := s.inTx(func(tx *TodoService) error {
+		var err error
+		archived, err = tx.repo.ArchiveCompleted(time.Now())
+		if err != nil || archived == 0 {
+			return err
+		}
+		return tx.audit.record(ctx, "", "auto_archive", "todo", "", nil, map[string]int64{"count": archived})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return archived, nil
+}
+
+// checkProject makes sure a todo owned by userID may be filed under the
+// project: it must exist, belong to the same user and not be archived.
+func (s *TodoService) checkProject(userID, projectID string) error {
+	project, err := s.projectRepo.GetByID(projectID)
+	if errors.Is(err, domain.ErrProjectNotFound) {
+		return fmt.Errorf("%w: project does not exist", domain.ErrInvalidProject)
+	}
+	if err != nil {
+		return err
+	}
+
+	if project.UserID != userID {
+		return fmt.Errorf("%w: project does not exist", domain.ErrInvalidProject)
+	}
+	if project.Archived {
+		return fmt.Errorf("%w: project is archived", domain.ErrInvalidProject)
+	}
+
+	return nil
+}
+
+// mergeDue applies the due date fields of an update on top of the current due date.
+func mergeDue(current *domain.Due, params UpdateTodoParams) (*domain.Due, error) {
+	if params.DueDate != nil && *params.DueDate == "" {
+		return nil, nil
+	}
+
+	var date, timeOfDay, timezone string
+	if current != nil {
+		date, timeOfDay, timezone = current.Date, current.Time, current.Timezone
+	}
+
+	if params.DueDate != nil {
+		date = *params.DueDate
+	}
+	if params.DueTime != nil {
+		timeOfDay = *params.DueTime
+	}
+	if params.DueTimezone != nil {
+		timezone = *params.DueTimezone
+	}
+
+	if date == "" {
+		return nil, fmt.Errorf("%w: a date is required to set a time or timezone", domain.ErrInvalidDueDate)
+	}
+
+	return domain.NewDue(date, timeOfDay, timezone)
+}
+
+// setTags attaches the named tags to a todo, creating any of the owner's tags
+// that don't exist yet.
+func (s *TodoService) setTags(todo *domain.Todo, names []string) error {
+	tagIDs := make([]string, 0, len(names))
+	tagNames := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, raw := range names {
+		name, err := domain.NormalizeTagName(raw)
+		if err != nil {
+			return err
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		tag, err := s.tagRepo.GetByName(todo.UserID, name)
+		if errors.Is(err, domain.ErrTagNotFound) {
+			tag = domain.NewTag(todo.UserID, name)
+			err = s.tagRepo.Create(tag)
+		}
+		if err != nil {
+			return err
+		}
+
+		tagIDs = append(tagIDs, tag.ID)
+		tagNames = append(tagNames, tag.Name)
+	}
+
+	if err := s.tagRepo.SetTodoTags(todo.ID, tagIDs); err != nil {
+		return err
+	}
+
+	sort.Strings(tagNames)
+	todo.Tags = tagNames
+	return nil
 }
@@ -0,0 +1,889 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"godo/internal/domain"
+	"godo/internal/store"
+	"godo/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+type todoTestRepos struct {
+	users        *store.UserRepo
+	todos        *store.TodoRepo
+	tags         *store.TagRepo
+	subtasks     *store.SubtaskRepo
+	projects     *store.ProjectRepo
+	shares       *store.ShareRepo
+	dependencies *store.DependencyRepo
+	audit        *store.AuditRepo
+	revisions    *store.RevisionRepo
+	transactor   domain.Transactor
+}
+
+func setupTestTodoService(t *testing.T) (*TodoService, todoTestRepos) {
+	t.Helper()
+
+	db := testutil.SetupTestDB(t)
+	repos := todoTestRepos{
+		users:        store.NewUserRepo(db),
+		todos:        store.NewTodoRepo(db),
+		tags:         store.NewTagRepo(db),
+		subtasks:     store.NewSubtaskRepo(db),
+		projects:     store.NewProjectRepo(db),
+		shares:       store.NewShareRepo(db),
+		dependencies: store.NewDependencyRepo(db),
+		audit:        store.NewAuditRepo(db),
+		revisions:    store.NewRevisionRepo(db),
+		transactor:   store.NewTransactor(db),
+	}
+
+	return newTestTodoServiceWith(repos, CompletionPolicy{}), repos
+}
+
+func newTestTodoServiceWith(repos todoTestRepos, completionPolicy CompletionPolicy) *TodoService {
+	return NewTodoService(domain.Repos{
+		Todos:        repos.todos,
+		Tags:         repos.tags,
+		Subtasks:     repos.subtasks,
+		Projects:     repos.projects,
+		Shares:       repos.shares,
+		Users:        repos.users,
+		Dependencies: repos.dependencies,
+		Audit:        repos.audit,
+		Revisions:    repos.revisions,
+	}, repos.transactor, DefaultDeletePolicy(), completionPolicy)
+}
+
+func createTodoServiceTestUser(t *testing.T, userRepo *store.UserRepo, role string) *domain.User {
+	t.Helper()
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        domain.NewID() + "@example.com",
+		PasswordHash: "hashed_password",
+		Role:         role,
+	}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	return user
+}
+
+// Completing a todo completes its subtasks
+func TestTodoServiceUpdate_CompleteCascadesToSubtasks(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	todo, err := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Parent"})
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+	repos.subtasks.Create(domain.NewSubtask(todo.ID, "One", 0))
+	repos.subtasks.Create(domain.NewSubtask(todo.ID, "Two", 0))
+
+	completed := true
+	updated, err := todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Completed: &completed})
+	if err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+
+	if updated.Progress.Done != 2 || updated.Progress.Total != 2 {
+		t.Errorf("Expected progress 2/2, got %s", updated.Progress)
+	}
+
+	subtasks, _ := repos.subtasks.GetByTodoID(todo.ID)
+	for _, subtask := range subtasks {
+		if !subtask.Completed {
+			t.Errorf("Expected subtask %q to be completed", subtask.Title)
+		}
+	}
+}
+
+func TestTodoServiceUpdate_ReopenKeepsSubtasks(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	todo, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Parent"})
+	subtask := domain.NewSubtask(todo.ID, "Done", 0)
+	subtask.Completed = true
+	repos.subtasks.Create(subtask)
+	repos.subtasks.Create(domain.NewSubtask(todo.ID, "Open", 0))
+
+	completed := false
+	updated, err := todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Completed: &completed})
+	if err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+
+	if updated.Progress.Done != 1 {
+		t.Errorf("Expected subtasks to be left alone, got %s", updated.Progress)
+	}
+}
+
+func TestTodoServiceUpdate_TagsReplaced(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	todo, err := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Tagged", Tags: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+
+	tags := []string{"B", "c"}
+	updated, err := todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Tags: &tags})
+	if err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+
+	if len(updated.Tags) != 2 || updated.Tags[0] != "b" || updated.Tags[1] != "c" {
+		t.Errorf("Expected tags [b c], got %v", updated.Tags)
+	}
+
+	all, _ := repos.tags.GetByUserID(user.ID)
+	if len(all) != 3 {
+		t.Errorf("Expected 3 tags to exist for the user, got %d", len(all))
+	}
+}
+
+func TestTodoServiceCreateAndUpdate_RollBackOnTagFailure(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	if _, err := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Half saved", Tags: []string{"ok", "a,b"}}); !errors.Is(err, domain.ErrInvalidTag) {
+		t.Fatalf("Expected ErrInvalidTag, got %v", err)
+	}
+	todos, _, _ := repos.todos.GetByUserID(user.ID, domain.TodoFilter{}, domain.PageRequest{})
+	if len(todos) != 0 {
+		t.Errorf("Expected no todo to be saved without its tags, got %d", len(todos))
+	}
+
+	todo, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Before", Tags: []string{"old"}})
+	title := "After"
+	tags := []string{"new", "a,b"}
+	if _, err := todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Title: &title, Tags: &tags}); !errors.Is(err, domain.ErrInvalidTag) {
+		t.Fatalf("Expected ErrInvalidTag, got %v", err)
+	}
+	saved, _ := repos.todos.GetByID(todo.ID)
+	if saved.Title != "Before" || len(saved.Tags) != 1 || saved.Tags[0] != "old" {
+		t.Errorf("Expected the todo to be left as it was, got %q with tags %v", saved.Title, saved.Tags)
+	}
+	if all, _ := repos.tags.GetByUserID(user.ID); len(all) != 1 {
+		t.Errorf("Expected only the old tag to exist, got %d tags", len(all))
+	}
+}
+
+func TestTodoServiceUpdate_CompletingRecurringCreatesNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		dueDate  string
+		expected string
+	}{
+		{name: "every other day", rule: "FREQ=DAILY;INTERVAL=2", dueDate: "2030-01-01", expected: "2030-01-03"},
+		{name: "weekdays skip the weekend", rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", dueDate: "2030-01-04", expected: "2030-01-07"},
+		{name: "weekly", rule: "FREQ=WEEKLY", dueDate: "2030-01-01", expected: "2030-01-08"},
+		{name: "later in the same week", rule: "FREQ=WEEKLY;BYDAY=MO,TH", dueDate: "2030-01-07", expected: "2030-01-10"},
+		{name: "first day of a later week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", dueDate: "2030-01-10", expected: "2030-01-21"},
+		{name: "monthly skips short months", rule: "FREQ=MONTHLY", dueDate: "2030-01-31", expected: "2030-03-31"},
+		{name: "yearly on a leap day", rule: "FREQ=YEARLY", dueDate: "2028-02-29", expected: "2032-02-29"},
+		{name: "until is inclusive", rule: "FREQ=DAILY;UNTIL=20300102", dueDate: "2030-01-01", expected: "2030-01-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todoService, repos := setupTestTodoService(t)
+			user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+			todo, err := todoService.Create(context.Background(), user.ID, CreateTodoParams{
+				Title:      "Chore",
+				DueDate:    tt.dueDate,
+				DueTime:    "09:00",
+				Recurrence: tt.rule,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create todo: %v", err)
+			}
+
+			completed := true
+			updated, err := todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Completed: &completed})
+			if err != nil {
+				t.Fatalf("Failed to update todo: %v", err)
+			}
+
+			next := updated.NextOccurrence
+			if next == nil {
+				t.Fatal("Expected a next occurrence")
+			}
+			if next.Due.Date != tt.expected || next.Due.Time != "09:00" {
+				t.Errorf("Expected next due %s 09:00, got %s %s", tt.expected, next.Due.Date, next.Due.Time)
+			}
+
+			saved, err := repos.todos.GetByID(next.ID)
+			if err != nil {
+				t.Fatalf("Expected next occurrence to be saved: %v", err)
+			}
+			if saved.Completed || saved.Recurrence == nil {
+				t.Errorf("Expected an open recurring todo, got %+v", saved)
+			}
+		})
+	}
+}
+
+// failingCreateTransactor hands out transactions in which creating a todo
+// fails.
+type failingCreateTransactor struct {
+	domain.Transactor
+}
+
+type failingCreateTodos struct {
+	domain.TodoRepository
+}
+
+func (failingCreateTodos) Create(todo *domain.Todo) error {
+	return errors.New("disk full")
+}
+
+func (t failingCreateTransactor) InTx(fn func(repos domain.Repos) error) error {
+	return t.Transactor.InTx(func(repos domain.Repos) error {
+		repos.Todos = failingCreateTodos{repos.Todos}
+		return fn(repos)
+	})
+}
+
+func TestTodoServiceUpdate_CompletingRecurringIsAtomic(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	todo, err := todoService.Create(context.Background(), user.ID, CreateTodoParams{
+		Title:      "Chore",
+		DueDate:    "2030-01-01",
+		Recurrence: "FREQ=DAILY",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+
+	repos.transactor = failingCreateTransactor{repos.transactor}
+	failing := newTestTodoServiceWith(repos, CompletionPolicy{})
+
+	completed := true
+	if _, err := failing.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Completed: &completed}); err == nil {
+		t.Fatal("Expected the update to fail when the next occurrence can't be saved")
+	}
+
+	saved, _ := repos.todos.GetByID(todo.ID)
+	if saved.Completed || saved.Recurrence == nil {
+		t.Errorf("Expected the todo to stay open and recurring, got completed=%t recurrence=%v", saved.Completed, saved.Recurrence)
+	}
+}
+
+// failingAuditTransactor hands out transactions in which recording an audit
+// event fails.
+type failingAuditTransactor struct {
+	domain.Transactor
+}
+
+type failingAudit struct {
+	domain.AuditRepository
+}
+
+func (failingAudit) Record(event *domain.AuditEvent) error {
+	return errors.New("disk full")
+}
+
+func (t failingAuditTransactor) InTx(fn func(repos domain.Repos) error) error {
+	return t.Transactor.InTx(func(repos domain.Repos) error {
+		repos.Audit = failingAudit{repos.Audit}
+		return fn(repos)
+	})
+}
+
+func TestTodoService_ChangesRollBackWhenAuditFails(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+	other := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	todo, err := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Audited"})
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+
+	repos.transactor = failingAuditTransactor{repos.transactor}
+	failing := newTestTodoServiceWith(repos, CompletionPolicy{})
+
+	if _, err := failing.Assign(context.Background(), todo.ID, user.ID, user.Role, other.ID); err == nil {
+		t.Error("Expected assigning to fail when the audit event can't be saved")
+	}
+	if err := failing.Delete(context.Background(), todo.ID, user.ID, user.Role, false); err == nil {
+		t.Error("Expected deleting to fail when the audit event can't be saved")
+	}
+
+	saved, err := repos.todos.GetByID(todo.ID)
+	if err != nil {
+		t.Fatalf("Expected the todo to stay out of the trash: %v", err)
+	}
+	if saved.AssigneeID != "" {
+		t.Errorf("Expected the todo to stay unassigned, got %s", saved.AssigneeID)
+	}
+}
+
+func TestTodoServiceUpdate_RecurringSeriesEnds(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{name: "last count", rule: "FREQ=DAILY;COUNT=1"},
+		{name: "past until", rule: "FREQ=DAILY;UNTIL=20300101"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todoService, repos := setupTestTodoService(t)
+			user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+			todo, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Chore", DueDate: "2030-01-01", Recurrence: tt.rule})
+
+			completed := true
+			updated, err := todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Completed: &completed})
+			if err != nil {
+				t.Fatalf("Failed to update todo: %v", err)
+			}
+
+			if updated.NextOccurrence != nil {
+				t.Errorf("Expected the series to end, got %+v", updated.NextOccurrence)
+			}
+		})
+	}
+}
+
+func TestTodoServiceUpdate_RecurringCarriesOver(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	todo, err := todoService.Create(context.Background(), user.ID, CreateTodoParams{
+		Title:      "Water plants",
+		Priority:   "high",
+		Tags:       []string{"home"},
+		Recurrence: "FREQ=WEEKLY;COUNT=3",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+	repos.subtasks.Create(domain.NewSubtask(todo.ID, "Ferns", 0))
+
+	completed := true
+	updated, err := todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Completed: &completed})
+	if err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+
+	if updated.Recurrence != nil {
+		t.Errorf("Expected the completed todo to hand its recurrence on, got %s", updated.Recurrence)
+	}
+
+	next, _ := repos.todos.GetByID(updated.NextOccurrence.ID)
+	if next.Priority != domain.PriorityHigh || len(next.Tags) != 1 || next.Tags[0] != "home" {
+		t.Errorf("Expected priority and tags to carry over, got %s %v", next.Priority, next.Tags)
+	}
+	if next.Recurrence.Count != 2 {
+		t.Errorf("Expected COUNT=2 on the next occurrence, got %s", next.Recurrence)
+	}
+	if next.Progress.Done != 0 || next.Progress.Total != 1 {
+		t.Errorf("Expected a fresh checklist, got %s", next.Progress)
+	}
+	if next.Due == nil {
+		t.Error("Expected an undated recurring todo to get a due date")
+	}
+
+	// Reopening and completing again must not spawn another copy
+	reopened := false
+	todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Completed: &reopened})
+	again, _ := todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Completed: &completed})
+	if again.NextOccurrence != nil {
+		t.Error("Expected no second occurrence")
+	}
+}
+
+func todoTitles(t *testing.T, todoService *TodoService, user *domain.User) string {
+	t.Helper()
+	todos, _, err := todoService.List(user.ID, user.Role, domain.TodoFilter{}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list todos: %v", err)
+	}
+	var titles string
+	for _, todo := range todos {
+		titles += todo.Title
+	}
+	return titles
+}
+
+func TestTodoServiceMove(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	ids := make(map[string]string)
+	for _, title := range []string{"A", "B", "C", "D"} {
+		todo, err := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: title})
+		if err != nil {
+			t.Fatalf("Failed to create todo: %v", err)
+		}
+		ids[title] = todo.ID
+	}
+
+	if got := todoTitles(t, todoService, user); got != "DCBA" {
+		t.Fatalf("Expected newest first, got %s", got)
+	}
+
+	tests := []struct {
+		name     string
+		todo     string
+		after    string
+		before   string
+		expected string
+	}{
+		{name: "between two", todo: "A", after: "D", before: "C", expected: "DACB"},
+		{name: "to the top", todo: "B", before: "D", expected: "BDAC"},
+		{name: "to the bottom", todo: "D", after: "C", expected: "BACD"},
+		{name: "after only", todo: "B", after: "A", expected: "ABCD"},
+		{name: "before only", todo: "D", before: "B", expected: "ADBC"},
+	}
+
+	for _, tt := range tests {
+		_, err := todoService.Move(context.Background(), ids[tt.todo], user.ID, user.Role, ids[tt.after], ids[tt.before])
+		if err != nil {
+			t.Fatalf("%s: failed to move todo: %v", tt.name, err)
+		}
+		if got := todoTitles(t, todoService, user); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// Squeezing into the same gap over and over must keep finding room
+func TestTodoServiceMove_RepeatedlyIntoSameGap(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	bottom, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "bottom"})
+	top, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "top"})
+
+	upper := bottom.ID
+	for i := 0; i < 100; i++ {
+		todo, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "middle"})
+		if _, err := todoService.Move(context.Background(), todo.ID, user.ID, user.Role, top.ID, upper); err != nil {
+			t.Fatalf("Move %d failed: %v", i, err)
+		}
+		upper = todo.ID
+	}
+
+	todos, _, _ := todoService.List(user.ID, user.Role, domain.TodoFilter{}, domain.PageRequest{})
+	if todos[0].ID != top.ID || todos[len(todos)-1].ID != bottom.ID {
+		t.Fatalf("Expected top and bottom to stay put")
+	}
+	for i := 1; i < len(todos); i++ {
+		if todos[i-1].Position >= todos[i].Position {
+			t.Fatalf("Positions out of order at %d: %q >= %q", i, todos[i-1].Position, todos[i].Position)
+		}
+	}
+}
+
+// Todos created on the same clock tick still get distinct ranks
+func TestTodoServiceMove_BetweenTodosCreatedTogether(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	tick := time.Now()
+	var pair []*domain.Todo
+	for _, title := range []string{"one", "two"} {
+		todo, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: title})
+		todo.Position = domain.InitialRank(tick, todo.ID)
+		if err := repos.todos.SetPosition(todo.ID, todo.Position); err != nil {
+			t.Fatalf("Failed to set position: %v", err)
+		}
+		pair = append(pair, todo)
+	}
+	if pair[0].Position > pair[1].Position {
+		pair[0], pair[1] = pair[1], pair[0]
+	}
+	if pair[0].Position == pair[1].Position {
+		t.Fatalf("Expected distinct ranks, got %q twice", pair[0].Position)
+	}
+
+	moved, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "moved"})
+	if _, err := todoService.Move(context.Background(), moved.ID, user.ID, user.Role, pair[0].ID, pair[1].ID); err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+}
+
+func TestTodoServiceMove_Invalid(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+	other := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	first, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "First"})
+	second, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Second"})
+	foreign, _ := todoService.Create(context.Background(), other.ID, CreateTodoParams{Title: "Foreign"})
+
+	tests := []struct {
+		name   string
+		after  string
+		before string
+	}{
+		{name: "no neighbours", after: "", before: ""},
+		{name: "next to itself", after: first.ID},
+		{name: "someone else's todo", after: foreign.ID},
+		{name: "missing todo", before: "nonexistent-id"},
+		{name: "neighbours out of order", after: first.ID, before: second.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := todoService.Move(context.Background(), first.ID, user.ID, user.Role, tt.after, tt.before)
+			if !errors.Is(err, domain.ErrInvalidPosition) {
+				t.Errorf("Expected ErrInvalidPosition, got %v", err)
+			}
+		})
+	}
+}
+
+// The delete policy decides what owners may delete; admins may always delete
+func TestTodoServiceDelete_Policy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy DeletePolicy
+		hard   bool
+		owner  error
+		admin  error
+	}{
+		{"default trash", DefaultDeletePolicy(), false, nil, nil},
+		{"default hard", DefaultDeletePolicy(), true, ErrForbidden, nil},
+		{"admins only", DeletePolicy{}, false, ErrForbidden, nil},
+		{"owners hard", DeletePolicy{OwnersCanDelete: true, OwnersCanHardDelete: true}, true, nil, nil},
+	}
+
+	for _, tt := range tests {
+		todoService, repos := setupTestTodoService(t)
+		todoService.deletePolicy = tt.policy
+		owner := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+		admin := createTodoServiceTestUser(t, repos.users, domain.RoleAdmin)
+
+		todo, _ := todoService.Create(context.Background(), owner.ID, CreateTodoParams{Title: "Mine"})
+		if err := todoService.Delete(context.Background(), todo.ID, owner.ID, owner.Role, tt.hard); !errors.Is(err, tt.owner) {
+			t.Errorf("%s: expected owner delete to return %v, got %v", tt.name, tt.owner, err)
+		}
+
+		todo, _ = todoService.Create(context.Background(), owner.ID, CreateTodoParams{Title: "Theirs"})
+		if err := todoService.Delete(context.Background(), todo.ID, admin.ID, admin.Role, tt.hard); !errors.Is(err, tt.admin) {
+			t.Errorf("%s: expected admin delete to return %v, got %v", tt.name, tt.admin, err)
+		}
+	}
+}
+
+// Another user's todos can't be deleted whatever the policy
+func TestTodoServiceDelete_OtherUsersTodo(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	todoService.deletePolicy = DeletePolicy{OwnersCanDelete: true, OwnersCanHardDelete: true}
+	owner := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+	other := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	todo, _ := todoService.Create(context.Background(), owner.ID, CreateTodoParams{Title: "Mine"})
+	for _, hard := range []bool{false, true} {
+		if err := todoService.Delete(context.Background(), todo.ID, other.ID, other.Role, hard); !errors.Is(err, ErrForbidden) {
+			t.Errorf("hard=%t: expected ErrForbidden, got %v", hard, err)
+		}
+	}
+}
+
+// Reopening a todo records it as not completed and takes it out of the archive
+func TestTodoServiceUpdate_CompletionAndArchive(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	todo, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Finish"})
+	completed := true
+	updated, err := todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Completed: &completed})
+	if err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	if updated.CompletedAt == nil {
+		t.Fatal("Expected CompletedAt to be set")
+	}
+
+	user.AutoArchiveDays = 1
+	repos.users.Update(user)
+	if _, err := todoService.repo.ArchiveCompleted(time.Now().Add(48 * time.Hour)); err != nil {
+		t.Fatalf("Failed to archive todos: %v", err)
+	}
+
+	completed = false
+	reopened, err := todoService.Update(context.Background(), todo.ID, user.ID, user.Role, UpdateTodoParams{Completed: &completed})
+	if err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	stored, _ := repos.todos.GetByID(todo.ID)
+	if reopened.CompletedAt != nil || stored.CompletedAt != nil {
+		t.Error("Expected CompletedAt to be cleared")
+	}
+	if stored.ArchivedAt != nil {
+		t.Error("Expected the reopened todo to leave the archive")
+	}
+}
+
+func TestTodoServiceAddBlocker_RejectsCycles(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	var todos []*domain.Todo
+	for _, title := range []string{"Design", "Build", "Ship"} {
+		todo, err := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: title})
+		if err != nil {
+			t.Fatalf("Failed to create todo: %v", err)
+		}
+		todos = append(todos, todo)
+	}
+	design, build, ship := todos[0], todos[1], todos[2]
+
+	if _, err := todoService.AddBlocker(context.Background(), build.ID, design.ID, user.ID, domain.RoleUser); err != nil {
+		t.Fatalf("Failed to add blocker: %v", err)
+	}
+	blocked, err := todoService.AddBlocker(context.Background(), ship.ID, build.ID, user.ID, domain.RoleUser)
+	if err != nil {
+		t.Fatalf("Failed to add blocker: %v", err)
+	}
+	if !blocked.Blocked {
+		t.Errorf("Expected the todo to be blocked")
+	}
+
+	if _, err := todoService.AddBlocker(context.Background(), design.ID, ship.ID, user.ID, domain.RoleUser); !errors.Is(err, domain.ErrDependencyCycle) {
+		t.Errorf("Expected ErrDependencyCycle closing a three todo loop, got %v", err)
+	}
+	if _, err := todoService.AddBlocker(context.Background(), design.ID, design.ID, user.ID, domain.RoleUser); !errors.Is(err, domain.ErrInvalidDependency) {
+		t.Errorf("Expected ErrInvalidDependency for a self dependency, got %v", err)
+	}
+}
+
+func TestTodoServiceUpdate_RequireBlockersDone(t *testing.T) {
+	_, repos := setupTestTodoService(t)
+	todoService := newTestTodoServiceWith(repos, CompletionPolicy{RequireBlockersDone: true})
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	blocker, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "First"})
+	todo, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Second"})
+	if _, err := todoService.AddBlocker(context.Background(), todo.ID, blocker.ID, user.ID, domain.RoleUser); err != nil {
+		t.Fatalf("Failed to add blocker: %v", err)
+	}
+
+	completed := true
+	if _, err := todoService.Update(context.Background(), todo.ID, user.ID, domain.RoleUser, UpdateTodoParams{Completed: &completed}); !errors.Is(err, domain.ErrTodoBlocked) {
+		t.Fatalf("Expected ErrTodoBlocked, got %v", err)
+	}
+
+	if _, err := todoService.Update(context.Background(), blocker.ID, user.ID, domain.RoleUser, UpdateTodoParams{Completed: &completed}); err != nil {
+		t.Fatalf("Failed to complete blocker: %v", err)
+	}
+	if _, err := todoService.Update(context.Background(), todo.ID, user.ID, domain.RoleUser, UpdateTodoParams{Completed: &completed}); err != nil {
+		t.Errorf("Expected the todo to complete once unblocked, got %v", err)
+	}
+}
+
+func TestTodoServiceBulk_ReportsEachTodo(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+	other := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	mine, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Mine"})
+	theirs, _ := todoService.Create(context.Background(), other.ID, CreateTodoParams{Title: "Theirs"})
+	viewed, _ := todoService.Create(context.Background(), other.ID, CreateTodoParams{Title: "Shared to view"})
+	repos.shares.Save(domain.NewTodoShare(viewed.ID, user.ID, domain.ShareViewer))
+
+	results, err := todoService.Bulk(context.Background(), user.ID, domain.RoleUser, BulkParams{
+		Action: BulkComplete,
+		IDs:    []string{mine.ID, theirs.ID, viewed.ID, "missing", mine.ID},
+	})
+	if err != nil {
+		t.Fatalf("Bulk failed: %v", err)
+	}
+
+	want := map[string]string{mine.ID: BulkOK, theirs.ID: BulkForbidden, viewed.ID: BulkForbidden, "missing": BulkNotFound}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(results))
+	}
+	for _, result := range results {
+		if result.Status != want[result.ID] {
+			t.Errorf("Expected %s for %s, got %s (%s)", want[result.ID], result.ID, result.Status, result.Error)
+		}
+	}
+
+	got, _ := repos.todos.GetByID(mine.ID)
+	if !got.Completed {
+		t.Error("Expected the user's own todo to be completed")
+	}
+	got, _ = repos.todos.GetByID(theirs.ID)
+	if got.Completed {
+		t.Error("Expected the other user's todo to stay open")
+	}
+}
+
+func TestTodoServiceBulk_TagAndFilter(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	tagged, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Tagged", Tags: []string{"home"}})
+	plain, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Plain"})
+
+	results, err := todoService.Bulk(context.Background(), user.ID, domain.RoleUser, BulkParams{
+		Action: BulkTag,
+		Filter: &domain.TodoFilter{},
+		Tags:   []string{"urgent"},
+	})
+	if err != nil {
+		t.Fatalf("Bulk failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected both todos to match the filter, got %d results", len(results))
+	}
+
+	got, _ := todoService.GetByID(tagged.ID, user.ID, domain.RoleUser)
+	if strings.Join(got.Tags, ",") != "home,urgent" {
+		t.Errorf("Expected the new tag next to the old one, got %v", got.Tags)
+	}
+	got, _ = todoService.GetByID(plain.ID, user.ID, domain.RoleUser)
+	if strings.Join(got.Tags, ",") != "urgent" {
+		t.Errorf("Expected the new tag, got %v", got.Tags)
+	}
+
+	if _, err := todoService.Bulk(context.Background(), user.ID, domain.RoleUser, BulkParams{Action: "archive", IDs: []string{plain.ID}}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an unknown action, got %v", err)
+	}
+}
+
+func TestTodoServiceBulk_InvalidTagsOrProjectChangeNothing(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+	other := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	todo, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Mine"})
+	theirs := domain.NewProject(other.ID, "Theirs", "")
+	repos.projects.Create(theirs)
+
+	if _, err := todoService.Bulk(context.Background(), user.ID, domain.RoleUser, BulkParams{
+		Action: BulkTag,
+		IDs:    []string{todo.ID},
+		Tags:   []string{"fine", "a,b"},
+	}); !errors.Is(err, domain.ErrInvalidTag) {
+		t.Errorf("Expected ErrInvalidTag, got %v", err)
+	}
+	if _, err := todoService.Bulk(context.Background(), user.ID, domain.RoleUser, BulkParams{
+		Action:    BulkMove,
+		IDs:       []string{todo.ID},
+		ProjectID: theirs.ID,
+	}); !errors.Is(err, domain.ErrInvalidProject) {
+		t.Errorf("Expected ErrInvalidProject, got %v", err)
+	}
+
+	got, _ := repos.todos.GetByID(todo.ID)
+	if !got.UpdatedAt.Equal(todo.UpdatedAt) {
+		t.Errorf("Expected the todo to stay untouched, updated at %v", got.UpdatedAt)
+	}
+	events, _, _ := repos.audit.List(domain.AuditFilter{Action: "update"}, domain.PageRequest{})
+	if len(events) != 0 {
+		t.Errorf("Expected no update to be audited, got %d events", len(events))
+	}
+}
+
+func TestTodoServiceBulk_AuditsChangedTodos(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+	other := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	mine, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{Title: "Mine"})
+	theirs, _ := todoService.Create(context.Background(), other.ID, CreateTodoParams{Title: "Theirs"})
+
+	if _, err := todoService.Bulk(context.Background(), user.ID, domain.RoleUser, BulkParams{
+		Action: BulkDelete,
+		IDs:    []string{mine.ID, theirs.ID},
+	}); err != nil {
+		t.Fatalf("Bulk failed: %v", err)
+	}
+
+	events, _, err := repos.audit.List(domain.AuditFilter{Action: "delete"}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list audit events: %v", err)
+	}
+	if len(events) != 1 || events[0].TargetID != mine.ID || events[0].ActorID != user.ID {
+		t.Fatalf("Expected only the deleted todo to be audited, got %d events", len(events))
+	}
+	if change := events[0].Changes["title"]; change.Before != "Mine" || change.After != nil {
+		t.Errorf("Expected the deleted todo's fields as before values, got %+v", change)
+	}
+}
+
+func TestTodoServiceRevert_RestoresEarlierState(t *testing.T) {
+	todoService, repos := setupTestTodoService(t)
+	user := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+	other := createTodoServiceTestUser(t, repos.users, domain.RoleUser)
+
+	todo, _ := todoService.Create(context.Background(), user.ID, CreateTodoParams{
+		Title:   "Draft",
+		DueDate: "2026-10-20",
+		Tags:    []string{"work"},
+	})
+
+	title, priority, noDue := "Final", "high", ""
+	tags := []string{"home"}
+	if _, err := todoService.Update(context.Background(), todo.ID, user.ID, domain.RoleUser, UpdateTodoParams{
+		Title:    &title,
+		Priority: &priority,
+		DueDate:  &noDue,
+		Tags:     &tags,
+	}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	// An update that changes nothing adds no revision
+	if _, err := todoService.Update(context.Background(), todo.ID, user.ID, domain.RoleUser, UpdateTodoParams{Title: &title}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	history, err := todoService.History(todo.ID, user.ID, domain.RoleUser)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected the baseline and one revision, got %d", len(history))
+	}
+	if change := history[0].Changes["title"]; change.Before != "Draft" || change.After != "Final" {
+		t.Errorf("Expected the title change, got %+v", change)
+	}
+	if _, ok := history[0].Changes["description"]; ok {
+		t.Errorf("Expected unchanged fields to be left out of the diff")
+	}
+
+	if _, err := todoService.History(todo.ID, other.ID, domain.RoleUser); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden for another user's history, got %v", err)
+	}
+	if _, err := todoService.Revert(context.Background(), todo.ID, 1, other.ID, domain.RoleUser); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden when reverting another user's todo, got %v", err)
+	}
+	if _, err := todoService.Revert(context.Background(), todo.ID, 9, user.ID, domain.RoleUser); !errors.Is(err, domain.ErrRevisionNotFound) {
+		t.Errorf("Expected ErrRevisionNotFound, got %v", err)
+	}
+
+	reverted, err := todoService.Revert(context.Background(), todo.ID, 1, user.ID, domain.RoleUser)
+	if err != nil {
+		t.Fatalf("Revert failed: %v", err)
+	}
+	if reverted.Title != "Draft" || reverted.Priority != domain.PriorityNone || reverted.Due == nil || reverted.Due.Date != "2026-10-20" {
+		t.Errorf("Expected the original fields back, got %+v", reverted)
+	}
+	if strings.Join(reverted.Tags, ",") != "work" {
+		t.Errorf("Expected the original tags back, got %v", reverted.Tags)
+	}
+
+	history, _ = todoService.History(todo.ID, user.ID, domain.RoleUser)
+	if len(history) != 3 || history[0].Revision != 3 || history[0].Changes["title"].After != "Draft" {
+		t.Errorf("Expected the revert to add a revision, got %d revisions", len(history))
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"godo/internal/auth"
+	"godo/internal/domain"
+	"sync"
+	"time"
+)
+
+// revocationCacheTTL is how long a revocation check is answered from memory.
+// Revocations made by this instance apply at once; those made by another
+// instance sharing the database apply within this delay.
+const revocationCacheTTL = 30 * time.Second
+
+// TokenRevocations decides whether access tokens were revoked: one by one on
+// logout, or all of a user's at once by bumping their token version. The
+// database holds the revocations and memory caches the answers.
+type TokenRevocations struct {
+	revoked domain.RevokedTokenRepository
+	users   domain.UserRepository
+
+	mu       sync.Mutex
+	tokens   map[string]cachedRevocation
+	versions map[string]cachedVersion
+}
+
+type cachedRevocation struct {
+	revoked bool
+	until   time.Time
+}
+
+type cachedVersion struct {
+	version int
+	until   time.Time
+}
+
+func NewTokenRevocations(revoked domain.RevokedTokenRepository, users domain.UserRepository) *TokenRevocations {
+	return &TokenRevocations{
+		revoked:  revoked,
+		users:    users,
+		tokens:   map[string]cachedRevocation{},
+		versions: map[string]cachedVersion{},
+	}
+}
+
+// IsRevoked reports whether the token or its session was revoked, or the
+// token belongs to a user who logged out everywhere since or no longer exists.
+func (r *TokenRevocations) IsRevoked(claims *auth.Claims) (bool, error) {
+	version, err := r.tokenVersion(claims.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if claims.TokenVersion < version {
+		return true, nil
+	}
+
+	for _, id := range []string{claims.ID, claims.SessionID} {
+		if id == "" {
+			continue
+		}
+		revoked, err := r.tokenRevoked(id)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	return false, nil
+}
+
+// Revoke revokes one token until it expires.
+func (r *TokenRevocations) Revoke(claims *auth.Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	expiresAt := claims.ExpiresAt.Time
+	if err := r.revoked.Revoke(claims.ID, claims.UserID, expiresAt); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[claims.ID] = cachedRevocation{revoked: true, until: expiresAt}
+	return nil
+}
+
+// RevokeSession revokes every token issued for the session. Access tokens
+// issued for it expire by until, so the revocation can be forgotten then.
+func (r *TokenRevocations) RevokeSession(sessionID, userID string, until time.Time) error {
+	if err := r.revoked.Revoke(sessionID, userID, until); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[sessionID] = cachedRevocation{revoked: true, until: until}
+	return nil
+}
+
+// RevokeUser revokes every token issued to the user so far.
+func (r *TokenRevocations) RevokeUser(userID string) error {
+	if err := r.users.BumpTokenVersion(userID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.versions, userID)
+	return nil
+}
+
+// PurgeExpired forgets revocations of tokens that have expired anyway.
+func (r *TokenRevocations) PurgeExpired() (int64, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	for jti, entry := range r.tokens {
+		if now.After(entry.until) {
+			delete(r.tokens, jti)
+		}
+	}
+	for userID, entry := range r.versions {
+		if now.After(entry.until) {
+			delete(r.versions, userID)
+		}
+	}
+	r.mu.Unlock()
+
+	return r.revoked.PurgeExpired(now)
+}
+
+func (r *TokenRevocations) tokenRevoked(jti string) (bool, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	entry, ok := r.tokens[jti]
+	r.mu.Unlock()
+	if ok && now.Before(entry.until) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := r.revoked.IsRevoked(jti)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[jti] = cachedRevocation{revoked: revoked, until: now.Add(revocationCacheTTL)}
+	return revoked, nil
+}
+
+func (r *TokenRevocations) tokenVersion(userID string) (int, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	entry, ok := r.versions[userID]
+	r.mu.Unlock()
+	if ok && now.Before(entry.until) {
+		return entry.version, nil
+	}
+
+	user, err := r.users.GetByID(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.versions[userID] = cachedVersion{version: user.TokenVersion, until: now.Add(revocationCacheTTL)}
+	return user.TokenVersion, nil
+}
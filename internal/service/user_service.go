@@ -1,15 +1,26 @@
 package service
 
 import (
+	"context"
 	"godo/internal/domain"
 )
 
 type UserService struct {
-	repo domain.UserRepository
+	repo       domain.UserRepository
+	audit      auditLog
+	transactor domain.Transactor
 }
 
-func NewUserService(repo domain.UserRepository) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(repo domain.UserRepository, auditRepo domain.AuditRepository, transactor domain.Transactor) *UserService {
+	return &UserService{repo: repo, audit: auditLog{repo: auditRepo}, transactor: transactor}
+}
+
+// inTx runs fn on a copy of the service bound to one transaction, so a change
+// is saved along with its audit event or not at all.
+func (s *UserService) inTx(fn func(tx *UserService) error) error {
+	return s.transactor.InTx(func(repos domain.Repos) error {
+		return fn(&UserService{repo: repos.Users, audit: auditLog{repo: repos.Audit}, transactor: joinedTx{repos: repos}})
+	})
 }
 
 func (s *UserService) GetByID(userID, requestingUserID, requestingUserRole string) (*domain.User, error) {
@@ -25,15 +36,17 @@ func (s *UserService) GetByID(userID, requestingUserID, requestingUserRole strin
 	return user, nil
 }
 
-func (s *UserService) List(requestingUserRole string) ([]*domain.User, error) {
+// List returns one page of users along with the cursor of the next page,
+// which is "" on the last page. Only admins may list users.
+func (s *UserService) List(requestingUserRole string, page domain.PageRequest) ([]*domain.User, string, error) {
 	if requestingUserRole == domain.RoleAdmin {
-		return s.repo.GetAll()
+		return s.repo.GetAll(page)
 	}
 
-	return nil, ErrForbidden
+	return nil, "", ErrForbidden
 }
 
-func (s *UserService) Update(userID, requestingUserID, requestingUserRole string, newEmail, newPassword, newRole *string) (*domain.User, error) {
+func (s *UserService) Update(ctx context.Context, userID, requestingUserID, requestingUserRole string, newEmail, newPassword, newRole *string, newAutoArchiveDays *int) (*domain.User, error) {
 	user, err := s.repo.GetByID(userID)
 	if err != nil {
 		return nil, err
@@ -42,6 +55,7 @@ func (s *UserService) Update(userID, requestingUserID, requestingUserRole string
 	if requestingUserRole != domain.RoleAdmin && user.ID != requestingUserID {
 		return nil, ErrForbidden
 	}
+	before := snapshot(user)
 
 	if newEmail != nil {
 		user.Email = *newEmail
@@ -68,15 +82,32 @@ func (s *UserService) Update(userID, requestingUserID, requestingUserRole string
 		}
 		user.Role = *newRole
 	}
+	if newAutoArchiveDays != nil {
+		if err := domain.ValidateAutoArchiveDays(*newAutoArchiveDays); err != nil {
+			return nil, err
+		}
+		user.AutoArchiveDays = *newAutoArchiveDays
+	}
 
-	if err := s.repo.Update(user); err != nil {
+	// The hash is never serialized, so a new password is noted separately
+	after := struct {
+		*domain.User
+		PasswordChanged bool `json:"password_changed,omitempty"`
+	}{user, newPassword != nil}
+	err = s.inTx(func(tx *UserService) error {
+		if err := tx.repo.Update(user); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "update", "user", user.ID, before, after)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
-func (s *UserService) Delete(userID, requestingUserID, requestingUserRole string) error {
+func (s *UserService) Delete(ctx context.Context, userID, requestingUserID, requestingUserRole string) error {
 	user, err := s.repo.GetByID(userID)
 	if err != nil {
 		return err
@@ -96,5 +127,10 @@ func (s *UserService) Delete(userID, requestingUserID, requestingUserRole string
 		}
 	}
 
-	return s.repo.Delete(userID)
+	return s.inTx(func(tx *UserService) error {
+		if err := tx.repo.Delete(userID); err != nil {
+			return err
+		}
+		return tx.audit.record(ctx, requestingUserID, "delete", "user", userID, user, nil)
+	})
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"godo/internal/domain"
 	"godo/internal/store"
 	"godo/internal/testutil"
@@ -12,7 +13,7 @@ func setupTestUserService(t *testing.T) (*UserService, *store.UserRepo) {
 
 	db := testutil.SetupTestDB(t)
 	userRepo := store.NewUserRepo(db)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, store.NewAuditRepo(db), store.NewTransactor(db))
 
 	return userService, userRepo
 }
@@ -133,7 +134,7 @@ func TestUserServiceList_Success(t *testing.T) {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
-	userList, err := userService.List(requestingUserRole)
+	userList, _, err := userService.List(requestingUserRole, domain.PageRequest{})
 	if err != nil {
 		t.Fatalf("Expected users got: %v", err)
 	}
@@ -145,7 +146,7 @@ func TestUserServiceList_Success(t *testing.T) {
 
 func TestUserServiceList_Failure(t *testing.T) {
 	userService, _ := setupTestUserService(t)
-	userList, err := userService.List(domain.RoleUser)
+	userList, _, err := userService.List(domain.RoleUser, domain.PageRequest{})
 	if err != ErrForbidden {
 		t.Fatalf("Expected ErrForbidden got: %v", err)
 	}
@@ -171,7 +172,7 @@ func TestUserServiceUpdate_User_Success(t *testing.T) {
 
 	newEmail := "test2@example.com"
 
-	user, err := userService.Update(user.ID, user.ID, user.Role, &newEmail, nil, nil)
+	user, err := userService.Update(context.Background(), user.ID, user.ID, user.Role, &newEmail, nil, nil, nil)
 	if err != nil {
 		t.Fatalf("Failed to update user: %v", err)
 	}
@@ -197,7 +198,7 @@ func TestUserServiceUpdate_User_Failure(t *testing.T) {
 
 	newEmail := "test2@example.com"
 
-	user, err := userService.Update(user.ID, domain.NewID(), domain.RoleUser, &newEmail, nil, nil)
+	user, err := userService.Update(context.Background(), user.ID, domain.NewID(), domain.RoleUser, &newEmail, nil, nil, nil)
 	if err != ErrForbidden {
 		t.Fatalf("Expected ErrForbidden got: %v", err)
 	}
@@ -223,7 +224,7 @@ func TestUserServiceUpdate_UserRole_Failure(t *testing.T) {
 
 	newRole := domain.RoleAdmin
 
-	user, err := userService.Update(user.ID, user.ID, domain.RoleUser, nil, nil, &newRole)
+	user, err := userService.Update(context.Background(), user.ID, user.ID, domain.RoleUser, nil, nil, &newRole, nil)
 	if err != ErrForbidden {
 		t.Fatalf("Expected ErrForbidden got: %v", err)
 	}
@@ -245,7 +246,7 @@ func TestUserServiceUpdate_Admin_Success(t *testing.T) {
 
 	newRole := domain.RoleAdmin
 
-	updatedUser, err := userService.Update(user.ID, domain.NewID(), domain.RoleAdmin, nil, nil, &newRole)
+	updatedUser, err := userService.Update(context.Background(), user.ID, domain.NewID(), domain.RoleAdmin, nil, nil, &newRole, nil)
 	if err != nil {
 		t.Fatalf("Expected to update user got: %v", err)
 	}
@@ -280,7 +281,7 @@ func TestUserServiceUpdate_AdminDemote_Success(t *testing.T) {
 
 	newRole := domain.RoleUser
 
-	updatedUser, err := userService.Update(user1.ID, domain.NewID(), domain.RoleAdmin, nil, nil, &newRole)
+	updatedUser, err := userService.Update(context.Background(), user1.ID, domain.NewID(), domain.RoleAdmin, nil, nil, &newRole, nil)
 	if err != nil {
 		t.Fatalf("Expected to update user got: %v", err)
 	}
@@ -306,7 +307,7 @@ func TestUserServiceUpdate_AdminDemote_Failure(t *testing.T) {
 
 	newRole := domain.RoleUser
 
-	user, err := userService.Update(user.ID, user.ID, user.Role, nil, nil, &newRole)
+	user, err := userService.Update(context.Background(), user.ID, user.ID, user.Role, nil, nil, &newRole, nil)
 	if err != ErrLastAdmin {
 		t.Fatalf("Expected ErrLastAdmin got: %v", err)
 	}
@@ -327,7 +328,7 @@ func TestUserServiceDelete_User_Success(t *testing.T) {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
-	err := userService.Delete(user.ID, user.ID, user.Role)
+	err := userService.Delete(context.Background(), user.ID, user.ID, user.Role)
 	if err != nil {
 		t.Fatalf("Failed to delete user: %v", err)
 	}
@@ -347,7 +348,7 @@ func TestUserServiceDelete_User_Failure(t *testing.T) {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
-	err := userService.Delete(user.ID, domain.NewID(), user.Role)
+	err := userService.Delete(context.Background(), user.ID, domain.NewID(), user.Role)
 	if err != ErrForbidden {
 		t.Fatalf("Expected ErrForbidden, got: %v", err)
 	}
@@ -367,7 +368,7 @@ func TestUserServiceDelete_Admin_Success(t *testing.T) {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
-	err := userService.Delete(user.ID, domain.NewID(), domain.RoleAdmin)
+	err := userService.Delete(context.Background(), user.ID, domain.NewID(), domain.RoleAdmin)
 	if err != nil {
 		t.Fatalf("Failed to delete user: %v", err)
 	}
@@ -387,8 +388,58 @@ func TestUserServiceDelete_Admin_Failure(t *testing.T) {
 		t.Fatalf("Failed to create user: %v", err)
 	}
 
-	err := userService.Delete(user.ID, user.ID, user.Role)
+	err := userService.Delete(context.Background(), user.ID, user.ID, user.Role)
 	if err != ErrLastAdmin {
 		t.Fatalf("Expected ErrLastAdmin, got: %v", err)
 	}
 }
+
+func TestUserServiceUpdate_RecordsAudit(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	userRepo := store.NewUserRepo(db)
+	auditRepo := store.NewAuditRepo(db)
+	userService := NewUserService(userRepo, auditRepo, store.NewTransactor(db))
+
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        "test@example.com",
+		PasswordHash: "hashed_password",
+		Role:         domain.RoleUser,
+	}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	adminID := domain.NewID()
+	ctx := WithRequestInfo(context.Background(), RequestInfo{RequestID: "req-1", IP: "192.0.2.1"})
+	newRole := domain.RoleAdmin
+	newPassword := "a-new-password"
+	if _, err := userService.Update(ctx, user.ID, adminID, domain.RoleAdmin, nil, &newPassword, &newRole, nil); err != nil {
+		t.Fatalf("Expected to update user got: %v", err)
+	}
+
+	events, _, err := auditRepo.List(domain.AuditFilter{TargetID: user.ID}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list audit events: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 audit event, got %d", len(events))
+	}
+
+	event := events[0]
+	if event.ActorID != adminID || event.Action != "update" || event.TargetType != "user" {
+		t.Errorf("Expected an update of the user by the admin, got %+v", event)
+	}
+	if event.RequestID != "req-1" || event.IP != "192.0.2.1" {
+		t.Errorf("Expected the request info, got %q %q", event.RequestID, event.IP)
+	}
+	if change := event.Changes["role"]; change.Before != domain.RoleUser || change.After != domain.RoleAdmin {
+		t.Errorf("Expected the role change, got %+v", change)
+	}
+	if change := event.Changes["password_changed"]; change.After != true {
+		t.Errorf("Expected the password change to be noted, got %+v", event.Changes)
+	}
+	if _, ok := event.Changes["email"]; ok {
+		t.Errorf("Expected unchanged fields to be left out, got %+v", event.Changes)
+	}
+}
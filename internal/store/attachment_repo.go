@@ -0,0 +1,140 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+)
+
+type AttachmentRepo struct {
+	db dbtx
+}
+
+func NewAttachmentRepo(db *sql.DB) *AttachmentRepo {
+	return &AttachmentRepo{db: db}
+}
+
+const attachmentColumns = `id, todo_id, user_id, filename, content_type, size, storage_key, created_at`
+
+func scanAttachment(row rowScanner) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.TodoID,
+		&attachment.UserID,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.StorageKey,
+		&attachment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *AttachmentRepo) Create(attachment *domain.Attachment) error {
+	query := `INSERT INTO attachments (` + attachmentColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query, attachment.ID, attachment.TodoID, attachment.UserID, attachment.Filename,
+		attachment.ContentType, attachment.Size, attachment.StorageKey, attachment.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AttachmentRepo) GetByID(id string) (*domain.Attachment, error) {
+	attachment, err := scanAttachment(r.db.QueryRow(`SELECT `+attachmentColumns+` FROM attachments WHERE id = ?`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrAttachmentNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+func (r *AttachmentRepo) GetByTodoID(todoID string) ([]*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE todo_id = ? ORDER BY created_at, id`
+
+	rows, err := r.db.Query(query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := make([]*domain.Attachment, 0)
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// Delete removes the attachment row. Its blob is queued for deletion by the
+// attachments_queue_blob_deletion trigger.
+func (r *AttachmentRepo) Delete(id string) error {
+	query := `DELETE FROM attachments WHERE id = ?`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+// PendingBlobDeletions lists up to limit queued storage keys, oldest first.
+func (r *AttachmentRepo) PendingBlobDeletions(limit int) ([]string, error) {
+	rows, err := r.db.Query(`SELECT storage_key FROM blob_deletions ORDER BY queued_at, storage_key LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blob deletions: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan blob deletion: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blob deletions: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *AttachmentRepo) ClearBlobDeletion(key string) error {
+	if _, err := r.db.Exec(`DELETE FROM blob_deletions WHERE storage_key = ?`, key); err != nil {
+		return fmt.Errorf("failed to clear blob deletion: %w", err)
+	}
+
+	return nil
+}
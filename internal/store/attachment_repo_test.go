@@ -0,0 +1,73 @@
+package store
+
+import (
+	"godo/internal/domain"
+	"testing"
+)
+
+func TestAttachmentRepo_CreateAndList(t *testing.T) {
+	db := setupTestDB(t)
+	attachmentRepo := NewAttachmentRepo(db)
+	todo := createSubtaskTestTodo(t, NewTodoRepo(db), NewUserRepo(db))
+
+	attachment := domain.NewAttachment(todo.ID, todo.UserID, "screenshot.png", "image/png", 1234)
+	if err := attachmentRepo.Create(attachment); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	attachments, err := attachmentRepo.GetByTodoID(todo.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(attachments))
+	}
+	got := attachments[0]
+	if got.Filename != "screenshot.png" || got.Size != 1234 || got.StorageKey != attachment.StorageKey {
+		t.Errorf("expected the attachment back as stored, got %+v", got)
+	}
+}
+
+// Removing attachments, directly or through their todo, queues their blobs
+func TestAttachmentRepo_DeleteQueuesBlobs(t *testing.T) {
+	db := setupTestDB(t)
+	todoRepo := NewTodoRepo(db)
+	attachmentRepo := NewAttachmentRepo(db)
+	todo := createSubtaskTestTodo(t, todoRepo, NewUserRepo(db))
+
+	first := domain.NewAttachment(todo.ID, todo.UserID, "a.pdf", "application/pdf", 1)
+	second := domain.NewAttachment(todo.ID, todo.UserID, "b.pdf", "application/pdf", 1)
+	attachmentRepo.Create(first)
+	attachmentRepo.Create(second)
+
+	if err := attachmentRepo.Delete(first.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := attachmentRepo.Delete(first.ID); err != domain.ErrAttachmentNotFound {
+		t.Errorf("expected ErrAttachmentNotFound deleting twice, got %v", err)
+	}
+
+	// Moving the todo to the trash keeps its attachments for a restore
+	todoRepo.Delete(todo.ID)
+	if keys, _ := attachmentRepo.PendingBlobDeletions(10); len(keys) != 1 || keys[0] != first.StorageKey {
+		t.Fatalf("expected only the deleted attachment's blob queued, got %v", keys)
+	}
+
+	todoRepo.HardDelete(todo.ID)
+	keys, err := attachmentRepo.PendingBlobDeletions(10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("expected both blobs queued once the todo is gone, got %v", keys)
+	}
+
+	for _, key := range keys {
+		if err := attachmentRepo.ClearBlobDeletion(key); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if keys, _ := attachmentRepo.PendingBlobDeletions(10); len(keys) != 0 {
+		t.Errorf("expected the queue to be empty, got %v", keys)
+	}
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"godo/internal/domain"
+	"strings"
+)
+
+type AuditRepo struct {
+	db dbtx
+}
+
+func NewAuditRepo(db *sql.DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+func (r *AuditRepo) Record(event *domain.AuditEvent) error {
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	query := `INSERT INTO audit_events (id, actor_id, action, target_type, target_id, changes, request_id, ip, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.Exec(query, event.ID, event.ActorID, event.Action, event.TargetType, event.TargetID,
+		string(changes), event.RequestID, event.IP, event.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return nil
+}
+
+// auditSortKeys orders events newest first.
+var auditSortKeys = []sortKey{{expr: "created_at", desc: true}, {expr: "id", desc: true}}
+
+// List returns one page of events matching filter, along with the cursor of
+// the next page or "" on the last one.
+func (r *AuditRepo) List(filter domain.AuditFilter, page domain.PageRequest) ([]*domain.AuditEvent, string, error) {
+	var clauses []string
+	var args []any
+
+	for _, eq := range []struct {
+		column, value string
+	}{
+		{"actor_id", filter.ActorID},
+		{"action", filter.Action},
+		{"target_type", filter.TargetType},
+		{"target_id", filter.TargetID},
+	} {
+		if eq.value != "" {
+			clauses = append(clauses, eq.column+" = ?")
+			args = append(args, eq.value)
+		}
+	}
+	if filter.Since != nil {
+		clauses = append(clauses, "created_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if filter.Until != nil {
+		clauses = append(clauses, "created_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	after, afterArgs, limit, err := pageQuery(auditSortKeys, "audit", page)
+	if err != nil {
+		return nil, "", err
+	}
+	if after != "" {
+		clauses = append(clauses, after)
+		args = append(args, afterArgs...)
+	}
+
+	query := `SELECT id, actor_id, action, target_type, target_id, changes, request_id, ip, created_at FROM audit_events`
+	if len(clauses) > 0 {
+		query += ` WHERE ` + strings.Join(clauses, " AND ")
+	}
+	query += ` ORDER BY ` + orderBy(auditSortKeys)
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*domain.AuditEvent, 0)
+	hasMore := false
+	for rows.Next() {
+		var event domain.AuditEvent
+		var changes string
+		err := rows.Scan(
+			&event.ID,
+			&event.ActorID,
+			&event.Action,
+			&event.TargetType,
+			&event.TargetID,
+			&changes,
+			&event.RequestID,
+			&event.IP,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		if err := json.Unmarshal([]byte(changes), &event.Changes); err != nil {
+			return nil, "", fmt.Errorf("failed to decode audit changes: %w", err)
+		}
+		if limit > 0 && len(events) == page.Limit {
+			hasMore = true
+			break
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error iterating audit events: %w", err)
+	}
+
+	var next string
+	if hasMore {
+		last := events[len(events)-1]
+		next = encodeCursor("audit", []any{last.CreatedAt, last.ID})
+	}
+
+	return events, next, nil
+}
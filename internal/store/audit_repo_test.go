@@ -0,0 +1,75 @@
+package store
+
+import (
+	"godo/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestAuditRepo_RecordAndList(t *testing.T) {
+	inEachZone(t, func(t *testing.T) {
+		db := setupTestDB(t)
+		auditRepo := NewAuditRepo(db)
+
+		base := time.Now().Add(-time.Hour)
+		for i, action := range []string{"create", "update", "delete"} {
+			event := domain.NewAuditEvent("actor-1", action, "todo", "todo-1")
+			event.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+			event.Changes = map[string]domain.AuditChange{"title": {Before: "Old", After: "New"}}
+			event.RequestID = "req-1"
+			event.IP = "10.0.0.1"
+			if err := auditRepo.Record(event); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		auditRepo.Record(domain.NewAuditEvent("actor-2", "create", "project", "project-1"))
+
+		events, next, err := auditRepo.List(domain.AuditFilter{TargetType: "todo"}, domain.PageRequest{Limit: 2})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(events) != 2 || next == "" {
+			t.Fatalf("expected a first page of 2 with a cursor, got %d (%q)", len(events), next)
+		}
+		if events[0].Action != "delete" || events[1].Action != "update" {
+			t.Errorf("expected newest first, got %s then %s", events[0].Action, events[1].Action)
+		}
+		if change := events[0].Changes["title"]; change.Before != "Old" || change.After != "New" {
+			t.Errorf("expected the title change to round-trip, got %+v", change)
+		}
+		if events[0].RequestID != "req-1" || events[0].IP != "10.0.0.1" {
+			t.Errorf("expected the request info to round-trip, got %q %q", events[0].RequestID, events[0].IP)
+		}
+
+		events, next, err = auditRepo.List(domain.AuditFilter{TargetType: "todo"}, domain.PageRequest{Limit: 2, Cursor: next})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(events) != 1 || events[0].Action != "create" || next != "" {
+			t.Errorf("expected the oldest todo event on the last page, got %d", len(events))
+		}
+
+		since := base.Add(90 * time.Second)
+		events, _, _ = auditRepo.List(domain.AuditFilter{ActorID: "actor-1", Since: &since}, domain.PageRequest{})
+		if len(events) != 1 || events[0].Action != "delete" {
+			t.Errorf("expected only the delete after %v, got %d events", since, len(events))
+		}
+	})
+}
+
+func TestAuditRepo_EventsAreImmutable(t *testing.T) {
+	db := setupTestDB(t)
+	auditRepo := NewAuditRepo(db)
+
+	event := domain.NewAuditEvent("actor-1", "delete", "user", "user-1")
+	if err := auditRepo.Record(event); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := db.Exec(`UPDATE audit_events SET action = 'create' WHERE id = ?`, event.ID); err == nil {
+		t.Error("expected updating an audit event to fail")
+	}
+	if _, err := db.Exec(`DELETE FROM audit_events WHERE id = ?`, event.ID); err == nil {
+		t.Error("expected deleting an audit event to fail")
+	}
+}
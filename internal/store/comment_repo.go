@@ -0,0 +1,128 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+)
+
+type CommentRepo struct {
+	db dbtx
+}
+
+func NewCommentRepo(db *sql.DB) *CommentRepo {
+	return &CommentRepo{db: db}
+}
+
+// commentColumns selects a comment along with its author's email.
+const commentColumns = `c.id, c.todo_id, c.user_id, u.email, c.body, c.created_at, c.updated_at
+	FROM comments c JOIN users u ON u.id = c.user_id`
+
+func scanComment(row rowScanner) (*domain.Comment, error) {
+	var comment domain.Comment
+	err := row.Scan(
+		&comment.ID,
+		&comment.TodoID,
+		&comment.UserID,
+		&comment.AuthorEmail,
+		&comment.Body,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *CommentRepo) Create(comment *domain.Comment) error {
+	query := `INSERT INTO comments (id, todo_id, user_id, body, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query, comment.ID, comment.TodoID, comment.UserID, comment.Body, comment.CreatedAt.UTC(), comment.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CommentRepo) GetByID(id string) (*domain.Comment, error) {
+	comment, err := scanComment(r.db.QueryRow(`SELECT `+commentColumns+` WHERE c.id = ?`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrCommentNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// GetByTodoID lists a todo's comments oldest first, the order a thread reads in.
+func (r *CommentRepo) GetByTodoID(todoID string) ([]*domain.Comment, error) {
+	query := `SELECT ` + commentColumns + ` WHERE c.todo_id = ? ORDER BY c.created_at, c.id`
+
+	rows, err := r.db.Query(query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := make([]*domain.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+func (r *CommentRepo) Update(comment *domain.Comment) error {
+	query := `UPDATE comments SET body = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.Exec(query, comment.Body, comment.UpdatedAt.UTC(), comment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+func (r *CommentRepo) Delete(id string) error {
+	query := `DELETE FROM comments WHERE id = ?`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrCommentNotFound
+	}
+
+	return nil
+}
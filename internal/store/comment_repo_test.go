@@ -0,0 +1,87 @@
+package store
+
+import (
+	"godo/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestCommentRepo_CreateAndList(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	commentRepo := NewCommentRepo(db)
+	todo := createSubtaskTestTodo(t, NewTodoRepo(db), userRepo)
+	author, _ := userRepo.GetByID(todo.UserID)
+
+	first := domain.NewComment(todo.ID, author.ID, "First")
+	second := domain.NewComment(todo.ID, author.ID, "Second")
+	second.CreatedAt = first.CreatedAt.Add(time.Second)
+	if err := commentRepo.Create(second); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := commentRepo.Create(first); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	comments, err := commentRepo.GetByTodoID(todo.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(comments) != 2 || comments[0].ID != first.ID || comments[1].ID != second.ID {
+		t.Fatalf("expected both comments oldest first, got %d comments", len(comments))
+	}
+	if comments[0].AuthorEmail != author.Email {
+		t.Errorf("expected author email %q, got %q", author.Email, comments[0].AuthorEmail)
+	}
+}
+
+func TestCommentRepo_UpdateAndDelete(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	commentRepo := NewCommentRepo(db)
+	todo := createSubtaskTestTodo(t, NewTodoRepo(db), userRepo)
+
+	comment := domain.NewComment(todo.ID, todo.UserID, "Draft")
+	commentRepo.Create(comment)
+
+	comment.Body = "Final"
+	comment.UpdatedAt = comment.CreatedAt.Add(time.Minute)
+	if err := commentRepo.Update(comment); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	updated, err := commentRepo.GetByID(comment.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated.Body != "Final" || !updated.Edited() {
+		t.Errorf("expected the edited body, got %q (edited %t)", updated.Body, updated.Edited())
+	}
+
+	if err := commentRepo.Delete(comment.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := commentRepo.GetByID(comment.ID); err != domain.ErrCommentNotFound {
+		t.Errorf("expected ErrCommentNotFound, got %v", err)
+	}
+	if err := commentRepo.Delete(comment.ID); err != domain.ErrCommentNotFound {
+		t.Errorf("expected ErrCommentNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestCommentRepo_RemovedWithTodo(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+	commentRepo := NewCommentRepo(db)
+	todo := createSubtaskTestTodo(t, todoRepo, userRepo)
+
+	commentRepo.Create(domain.NewComment(todo.ID, todo.UserID, "Note"))
+	if err := todoRepo.HardDelete(todo.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if comments, _ := commentRepo.GetByTodoID(todo.ID); len(comments) != 0 {
+		t.Errorf("expected comments to be removed with the todo, got %d", len(comments))
+	}
+}
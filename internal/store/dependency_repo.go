@@ -0,0 +1,71 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+	"time"
+)
+
+type DependencyRepo struct {
+	db dbtx
+}
+
+func NewDependencyRepo(db *sql.DB) *DependencyRepo {
+	return &DependencyRepo{db: db}
+}
+
+func (r *DependencyRepo) Add(todoID, blockerID string) error {
+	query := `INSERT INTO todo_dependencies (todo_id, blocker_id, created_at) VALUES (?, ?, ?)
+		ON CONFLICT (todo_id, blocker_id) DO NOTHING`
+
+	if _, err := r.db.Exec(query, todoID, blockerID, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to add dependency: %w", err)
+	}
+
+	return nil
+}
+
+func (r *DependencyRepo) Remove(todoID, blockerID string) error {
+	query := `DELETE FROM todo_dependencies WHERE todo_id = ? AND blocker_id = ?`
+
+	result, err := r.db.Exec(query, todoID, blockerID)
+	if err != nil {
+		return fmt.Errorf("failed to remove dependency: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrDependencyNotFound
+	}
+
+	return nil
+}
+
+// GetBlockerIDs lists the todos the todo depends on, oldest dependency first.
+func (r *DependencyRepo) GetBlockerIDs(todoID string) ([]string, error) {
+	rows, err := r.db.Query(`SELECT blocker_id FROM todo_dependencies WHERE todo_id = ? ORDER BY created_at, blocker_id`, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan dependency: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dependencies: %w", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"godo/internal/domain"
+	"testing"
+)
+
+func TestDependencyRepo_BlockedFlag(t *testing.T) {
+	db := setupTestDB(t)
+	todoRepo := NewTodoRepo(db)
+	dependencyRepo := NewDependencyRepo(db)
+	todo := createSubtaskTestTodo(t, todoRepo, NewUserRepo(db))
+	blocker := domain.NewTodo(todo.UserID, "Blocker", "")
+	todoRepo.Create(blocker)
+
+	if err := dependencyRepo.Add(todo.ID, blocker.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := dependencyRepo.Add(todo.ID, blocker.ID); err != nil {
+		t.Fatalf("expected adding twice to be a no-op, got %v", err)
+	}
+
+	ids, err := dependencyRepo.GetBlockerIDs(todo.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(ids) != 1 || ids[0] != blocker.ID {
+		t.Fatalf("expected one blocker, got %v", ids)
+	}
+
+	got, _ := todoRepo.GetByID(todo.ID)
+	if !got.Blocked {
+		t.Errorf("expected the todo to be blocked by an open todo")
+	}
+
+	blocker.Completed = true
+	todoRepo.Update(blocker)
+	got, _ = todoRepo.GetByID(todo.ID)
+	if got.Blocked {
+		t.Errorf("expected the todo to be unblocked once its blocker is done")
+	}
+
+	if err := dependencyRepo.Remove(todo.ID, blocker.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := dependencyRepo.Remove(todo.ID, blocker.ID); err != domain.ErrDependencyNotFound {
+		t.Errorf("expected ErrDependencyNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"godo/internal/domain"
+	"strings"
+	"time"
+)
+
+// sortKey is one term of an ORDER BY. Listings end their keys with a unique
+// column so every row has a distinct position to resume from.
+type sortKey struct {
+	expr string
+	desc bool
+}
+
+func orderBy(keys []sortKey) string {
+	terms := make([]string, len(keys))
+	for i, key := range keys {
+		terms[i] = key.expr + " ASC"
+		if key.desc {
+			terms[i] = key.expr + " DESC"
+		}
+	}
+	return strings.Join(terms, ", ")
+}
+
+// keyColumns selects the sort key values so the last row of a page can be
+// turned into a cursor.
+func keyColumns(keys []sortKey) string {
+	exprs := make([]string, len(keys))
+	for i, key := range keys {
+		exprs[i] = key.expr
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// afterClause matches the rows that sort after values, the keys of the last
+// row on the previous page. It expands the row comparison by hand because
+// the keys mix ascending and descending order. Key expressions are wrapped in
+// parentheses so ones like "due_at IS NULL" keep their meaning.
+func afterClause(keys []sortKey, values []any) (string, []any) {
+	var alternatives []string
+	var args []any
+
+	for i, key := range keys {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, "("+keys[j].expr+") = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if key.desc {
+			op = " < ?"
+		}
+		terms = append(terms, "("+key.expr+")"+op)
+		args = append(args, values[i])
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// cursor is the decoded form of a page token. Scope records which listing
+// and ordering the keys belong to, so a token can't be replayed elsewhere.
+type cursor struct {
+	Scope string `json:"s"`
+	Keys  []any  `json:"k"`
+}
+
+// encodeCursor packs sort key values into an opaque token. Times are kept in
+// the text form the driver stores them in, in UTC like every stored time, so
+// they compare equal to the column values.
+func encodeCursor(scope string, values []any) string {
+	keys := make([]any, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case time.Time:
+			keys[i] = v.UTC().Format(time.RFC3339Nano)
+		case []byte:
+			keys[i] = string(v)
+		default:
+			keys[i] = v
+		}
+	}
+
+	data, _ := json.Marshal(cursor{Scope: scope, Keys: keys})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token, scope string, n int) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Scope != scope || len(c.Keys) != n {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	// JSON numbers come back as float64; the sort keys only hold integers
+	for i, v := range c.Keys {
+		switch v := v.(type) {
+		case float64:
+			c.Keys[i] = int64(v)
+		case string, nil:
+		default:
+			return nil, fmt.Errorf("%w: unexpected key %v", domain.ErrInvalidCursor, v)
+		}
+	}
+
+	return c.Keys, nil
+}
+
+// pageQuery narrows a listing query to the requested page. It returns the
+// extra WHERE clause (empty on the first page), its arguments and the LIMIT
+// to apply, which is one more than requested so the caller can tell whether
+// another page follows.
+func pageQuery(keys []sortKey, scope string, page domain.PageRequest) (string, []any, int, error) {
+	limit := 0
+	if page.Limit > 0 {
+		limit = page.Limit + 1
+	}
+
+	if page.Cursor == "" {
+		return "", nil, limit, nil
+	}
+
+	values, err := decodeCursor(page.Cursor, scope, len(keys))
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	clause, args := afterClause(keys, values)
+	return clause, args, limit, nil
+}
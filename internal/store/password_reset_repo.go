@@ -0,0 +1,99 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+	"time"
+)
+
+type PasswordResetRepo struct {
+	db dbtx
+}
+
+func NewPasswordResetRepo(db *sql.DB) *PasswordResetRepo {
+	return &PasswordResetRepo{db: db}
+}
+
+func (r *PasswordResetRepo) Create(token *domain.PasswordResetToken) error {
+	query := `INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query, token.ID, token.UserID, token.TokenHash, token.ExpiresAt.UTC(), token.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PasswordResetRepo) GetByHash(tokenHash string) (*domain.PasswordResetToken, error) {
+	query := `SELECT id, user_id, token_hash, expires_at, created_at, used_at
+		FROM password_reset_tokens WHERE token_hash = ?`
+
+	var token domain.PasswordResetToken
+	var usedAt sql.NullTime
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&usedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPasswordResetTokenNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
+
+func (r *PasswordResetRepo) MarkUsed(id string, at time.Time) error {
+	query := `UPDATE password_reset_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`
+
+	result, err := r.db.Exec(query, at.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark password reset token used: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrPasswordResetTokenNotFound
+	}
+
+	return nil
+}
+
+func (r *PasswordResetRepo) MarkUserUsed(userID string, at time.Time) error {
+	query := `UPDATE password_reset_tokens SET used_at = ? WHERE user_id = ? AND used_at IS NULL`
+
+	if _, err := r.db.Exec(query, at.UTC(), userID); err != nil {
+		return fmt.Errorf("failed to mark password reset tokens used: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PasswordResetRepo) CountSince(userID string, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM password_reset_tokens WHERE user_id = ? AND created_at > ?`
+
+	var count int
+	if err := r.db.QueryRow(query, userID, since.UTC()).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count password reset tokens: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"errors"
+	"godo/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestPasswordResetRepo_SingleUse(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPasswordResetRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	token, value, err := domain.NewPasswordResetToken(user.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	other, _, _ := domain.NewPasswordResetToken(user.ID, time.Hour)
+	for _, tok := range []*domain.PasswordResetToken{token, other} {
+		if err := repo.Create(tok); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	found, err := repo.GetByHash(domain.HashPasswordResetToken(value))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found.ID != token.ID || found.UsedAt != nil {
+		t.Errorf("expected the unused token, got %+v", found)
+	}
+	if _, err := repo.GetByHash(value); !errors.Is(err, domain.ErrPasswordResetTokenNotFound) {
+		t.Errorf("expected the raw value not to be stored, got %v", err)
+	}
+
+	if count, err := repo.CountSince(user.ID, time.Now().Add(-time.Minute)); err != nil || count != 2 {
+		t.Errorf("expected 2 recent tokens, got %d, %v", count, err)
+	}
+
+	if err := repo.MarkUsed(token.ID, time.Now()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := repo.MarkUsed(token.ID, time.Now()); !errors.Is(err, domain.ErrPasswordResetTokenNotFound) {
+		t.Errorf("expected a used token not to be used again, got %v", err)
+	}
+
+	if err := repo.MarkUserUsed(user.ID, time.Now()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found, _ := repo.GetByHash(other.TokenHash); found.UsedAt == nil {
+		t.Error("expected the user's other token to be used up")
+	}
+}
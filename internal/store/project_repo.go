@@ -0,0 +1,153 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+)
+
+const projectColumns = `id, user_id, name, description, archived, created_at, updated_at,
+	(SELECT COUNT(*) FROM todos t WHERE t.project_id = projects.id AND t.deleted_at IS NULL AND t.completed = 1),
+	(SELECT COUNT(*) FROM todos t WHERE t.project_id = projects.id AND t.deleted_at IS NULL)`
+
+type ProjectRepo struct {
+	db dbtx
+}
+
+func NewProjectRepo(db *sql.DB) *ProjectRepo {
+	return &ProjectRepo{db: db}
+}
+
+func scanProject(s rowScanner) (*domain.Project, error) {
+	var project domain.Project
+	err := s.Scan(
+		&project.ID,
+		&project.UserID,
+		&project.Name,
+		&project.Description,
+		&project.Archived,
+		&project.CreatedAt,
+		&project.UpdatedAt,
+		&project.Progress.Done,
+		&project.Progress.Total,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *ProjectRepo) Create(project *domain.Project) error {
+	query := `INSERT INTO projects (id, user_id, name, description, archived, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query, project.ID, project.UserID, project.Name, project.Description,
+		project.Archived, project.CreatedAt.UTC(), project.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ProjectRepo) GetByID(id string) (*domain.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = ?`
+
+	project, err := scanProject(r.db.QueryRow(query, id))
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrProjectNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project, nil
+}
+
+// GetByUserID lists a user's projects by name. Archived projects are left
+// out unless includeArchived is set.
+func (r *ProjectRepo) GetByUserID(userID string, includeArchived bool) ([]*domain.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE user_id = ?`
+	if !includeArchived {
+		query += ` AND archived = 0`
+	}
+	query += ` ORDER BY name COLLATE NOCASE`
+
+	return r.query(query, userID)
+}
+
+// GetSharedWith lists the unarchived projects other users shared with the
+// user, by name.
+func (r *ProjectRepo) GetSharedWith(userID string) ([]*domain.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects
+		WHERE archived = 0 AND id IN (SELECT project_id FROM shares WHERE user_id = ? AND project_id IS NOT NULL)
+		ORDER BY name COLLATE NOCASE`
+
+	return r.query(query, userID)
+}
+
+func (r *ProjectRepo) query(query string, args ...any) ([]*domain.Project, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]*domain.Project, 0)
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+func (r *ProjectRepo) Update(project *domain.Project) error {
+	query := `UPDATE projects SET name = ?, description = ?, archived = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.Exec(query, project.Name, project.Description, project.Archived, project.UpdatedAt.UTC(), project.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrProjectNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a project. Its todos stay behind without a project.
+func (r *ProjectRepo) Delete(id string) error {
+	query := `DELETE FROM projects WHERE id = ?`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrProjectNotFound
+	}
+
+	return nil
+}
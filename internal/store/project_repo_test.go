@@ -0,0 +1,91 @@
+package store
+
+import (
+	"godo/internal/domain"
+	"testing"
+)
+
+func TestProjectRepo_Progress(t *testing.T) {
+	db := setupTestDB(t)
+	projectRepo := NewProjectRepo(db)
+	todoRepo := NewTodoRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	project := domain.NewProject(user.ID, "Home", "")
+	if err := projectRepo.Create(project); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, completed := range []bool{true, false, false} {
+		todo := domain.NewTodo(user.ID, "Chore", "")
+		todo.ProjectID = project.ID
+		todo.Completed = completed
+		todoRepo.Create(todo)
+	}
+	todoRepo.Create(domain.NewTodo(user.ID, "Loose", ""))
+
+	saved, err := projectRepo.GetByID(project.ID)
+	if err != nil {
+		t.Fatalf("failed to get project: %v", err)
+	}
+	if saved.Progress.Done != 1 || saved.Progress.Total != 3 {
+		t.Errorf("expected progress 1/3, got %s", saved.Progress)
+	}
+
+	todos, _, _ := todoRepo.GetByUserID(user.ID, domain.TodoFilter{ProjectID: project.ID}, domain.PageRequest{})
+	if len(todos) != 3 {
+		t.Errorf("expected 3 todos in project, got %d", len(todos))
+	}
+}
+
+func TestProjectRepo_GetByUserID_Archived(t *testing.T) {
+	db := setupTestDB(t)
+	projectRepo := NewProjectRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	active := domain.NewProject(user.ID, "Active", "")
+	archived := domain.NewProject(user.ID, "Archived", "")
+	archived.Archived = true
+	projectRepo.Create(active)
+	projectRepo.Create(archived)
+
+	projects, _ := projectRepo.GetByUserID(user.ID, false)
+	if len(projects) != 1 || projects[0].ID != active.ID {
+		t.Errorf("expected only the active project, got %d projects", len(projects))
+	}
+
+	projects, _ = projectRepo.GetByUserID(user.ID, true)
+	if len(projects) != 2 {
+		t.Errorf("expected 2 projects, got %d", len(projects))
+	}
+}
+
+func TestProjectRepo_Delete_KeepsTodos(t *testing.T) {
+	db := setupTestDB(t)
+	projectRepo := NewProjectRepo(db)
+	todoRepo := NewTodoRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	project := domain.NewProject(user.ID, "Temporary", "")
+	projectRepo.Create(project)
+
+	todo := domain.NewTodo(user.ID, "Survivor", "")
+	todo.ProjectID = project.ID
+	todoRepo.Create(todo)
+
+	if err := projectRepo.Delete(project.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	saved, err := todoRepo.GetByID(todo.ID)
+	if err != nil {
+		t.Fatalf("expected todo to survive, got %v", err)
+	}
+	if saved.ProjectID != "" {
+		t.Errorf("expected project to be cleared, got %q", saved.ProjectID)
+	}
+
+	if err := projectRepo.Delete(project.ID); err != domain.ErrProjectNotFound {
+		t.Errorf("expected ErrProjectNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+	"time"
+)
+
+type RefreshTokenRepo struct {
+	db dbtx
+}
+
+func NewRefreshTokenRepo(db *sql.DB) *RefreshTokenRepo {
+	return &RefreshTokenRepo{db: db}
+}
+
+func (r *RefreshTokenRepo) Create(token *domain.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query, token.ID, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt.UTC(), token.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepo) GetByHash(tokenHash string) (*domain.RefreshToken, error) {
+	query := `SELECT id, user_id, family_id, token_hash, expires_at, created_at, used_at, revoked_at
+		FROM refresh_tokens WHERE token_hash = ?`
+
+	var token domain.RefreshToken
+	var usedAt, revokedAt sql.NullTime
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&usedAt,
+		&revokedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrRefreshTokenNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
+
+func (r *RefreshTokenRepo) MarkUsed(id string, at time.Time) error {
+	query := `UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, at.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark refresh token used: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrRefreshTokenNotFound
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every token of the family that isn't revoked yet.
+func (r *RefreshTokenRepo) RevokeFamily(familyID string, at time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`
+
+	if _, err := r.db.Exec(query, at.UTC(), familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every token of the user that isn't revoked yet.
+func (r *RefreshTokenRepo) RevokeUser(userID string, at time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`
+
+	if _, err := r.db.Exec(query, at.UTC(), userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
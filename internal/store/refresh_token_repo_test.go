@@ -0,0 +1,52 @@
+package store
+
+import (
+	"godo/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestRefreshTokenRepo_UseAndRevoke(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRefreshTokenRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	token, value, err := domain.NewRefreshToken(user.ID, domain.NewID(), time.Hour)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := repo.Create(token); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got, err := repo.GetByHash(domain.HashRefreshToken(value))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.ID != token.ID || got.UsedAt != nil || got.RevokedAt != nil {
+		t.Fatalf("expected the unused token back, got %+v", got)
+	}
+	if _, err := repo.GetByHash(value); err != domain.ErrRefreshTokenNotFound {
+		t.Errorf("expected tokens to be found by hash only, got %v", err)
+	}
+
+	if err := repo.MarkUsed(token.ID, time.Now()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := repo.MarkUsed(token.ID, time.Now()); err != domain.ErrRefreshTokenNotFound {
+		t.Errorf("expected a token to be usable once, got %v", err)
+	}
+
+	sibling, _, _ := domain.NewRefreshToken(user.ID, token.FamilyID, time.Hour)
+	repo.Create(sibling)
+	if err := repo.RevokeFamily(token.FamilyID, time.Now()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	got, _ = repo.GetByHash(sibling.TokenHash)
+	if got.RevokedAt == nil {
+		t.Errorf("expected every token of the family to be revoked")
+	}
+	if err := repo.MarkUsed(sibling.ID, time.Now()); err != domain.ErrRefreshTokenNotFound {
+		t.Errorf("expected a revoked token to be unusable, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"godo/internal/domain"
+)
+
+type RevisionRepo struct {
+	db dbtx
+}
+
+func NewRevisionRepo(db *sql.DB) *RevisionRepo {
+	return &RevisionRepo{db: db}
+}
+
+// revisionColumns selects a revision along with its author's email, if any.
+const revisionColumns = `r.id, r.todo_id, r.revision, r.user_id, COALESCE(u.email, ''), r.state, r.changes, r.created_at
+	FROM todo_revisions r LEFT JOIN users u ON u.id = r.user_id`
+
+func scanRevision(row rowScanner) (*domain.TodoRevision, error) {
+	var revision domain.TodoRevision
+	var state, changes string
+	err := row.Scan(
+		&revision.ID,
+		&revision.TodoID,
+		&revision.Revision,
+		&revision.UserID,
+		&revision.UserEmail,
+		&state,
+		&changes,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(state), &revision.State); err != nil {
+		return nil, fmt.Errorf("failed to decode revision state: %w", err)
+	}
+	if err := json.Unmarshal([]byte(changes), &revision.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode revision changes: %w", err)
+	}
+	return &revision, nil
+}
+
+func (r *RevisionRepo) Create(revision *domain.TodoRevision) error {
+	state, err := json.Marshal(revision.State)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision state: %w", err)
+	}
+	changes, err := json.Marshal(revision.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision changes: %w", err)
+	}
+
+	query := `INSERT INTO todo_revisions (id, todo_id, revision, user_id, state, changes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.Exec(query, revision.ID, revision.TodoID, revision.Revision, revision.UserID,
+		string(state), string(changes), revision.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create revision: %w", err)
+	}
+
+	return nil
+}
+
+// GetByTodoID lists a todo's revisions newest first.
+func (r *RevisionRepo) GetByTodoID(todoID string) ([]*domain.TodoRevision, error) {
+	rows, err := r.db.Query(`SELECT `+revisionColumns+` WHERE r.todo_id = ? ORDER BY r.revision DESC`, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]*domain.TodoRevision, 0)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+func (r *RevisionRepo) GetByNumber(todoID string, number int) (*domain.TodoRevision, error) {
+	revision, err := scanRevision(r.db.QueryRow(`SELECT `+revisionColumns+` WHERE r.todo_id = ? AND r.revision = ?`, todoID, number))
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrRevisionNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+// LatestNumber returns the number of the todo's newest revision, or 0 when it
+// has none yet.
+func (r *RevisionRepo) LatestNumber(todoID string) (int, error) {
+	var number int
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(revision), 0) FROM todo_revisions WHERE todo_id = ?`, todoID).Scan(&number)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest revision: %w", err)
+	}
+
+	return number, nil
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"godo/internal/domain"
+	"testing"
+)
+
+func TestRevisionRepo_CreateAndList(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+	revisionRepo := NewRevisionRepo(db)
+	todo := createSubtaskTestTodo(t, todoRepo, userRepo)
+
+	latest, err := revisionRepo.LatestNumber(todo.ID)
+	if err != nil || latest != 0 {
+		t.Fatalf("expected no revisions yet, got %d, %v", latest, err)
+	}
+
+	first := domain.NewTodoRevision(todo.ID, 1, "", todo.State())
+	if err := revisionRepo.Create(first); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	todo.Title = "Renamed"
+	second := domain.NewTodoRevision(todo.ID, 2, todo.UserID, todo.State())
+	second.Changes = map[string]domain.AuditChange{"title": {Before: "Parent", After: "Renamed"}}
+	if err := revisionRepo.Create(second); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := revisionRepo.Create(domain.NewTodoRevision(todo.ID, 2, todo.UserID, todo.State())); err == nil {
+		t.Errorf("expected a duplicate revision number to be rejected")
+	}
+
+	revisions, err := revisionRepo.GetByTodoID(todo.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Revision != 2 || revisions[1].Revision != 1 {
+		t.Fatalf("expected both revisions newest first, got %d", len(revisions))
+	}
+	if revisions[0].State.Title != "Renamed" || revisions[0].Changes["title"].After != "Renamed" {
+		t.Errorf("expected the stored state and changes, got %+v", revisions[0])
+	}
+	if revisions[0].UserEmail == "" || revisions[1].UserEmail != "" {
+		t.Errorf("expected the author's email only where there is an author")
+	}
+
+	got, err := revisionRepo.GetByNumber(todo.ID, 1)
+	if err != nil || got.State.Title != "Parent" {
+		t.Errorf("expected the first revision, got %+v, %v", got, err)
+	}
+	if _, err := revisionRepo.GetByNumber(todo.ID, 3); err != domain.ErrRevisionNotFound {
+		t.Errorf("expected ErrRevisionNotFound, got %v", err)
+	}
+}
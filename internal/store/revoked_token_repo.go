@@ -0,0 +1,47 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type RevokedTokenRepo struct {
+	db dbtx
+}
+
+func NewRevokedTokenRepo(db *sql.DB) *RevokedTokenRepo {
+	return &RevokedTokenRepo{db: db}
+}
+
+// Revoke lists the token as revoked. Revoking it again is not an error.
+func (r *RevokedTokenRepo) Revoke(jti, userID string, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (jti, user_id, expires_at, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (jti) DO NOTHING`
+
+	if _, err := r.db.Exec(query, jti, userID, expiresAt.UTC(), time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RevokedTokenRepo) IsRevoked(jti string) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = ?)`, jti).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// PurgeExpired forgets revoked tokens that have expired anyway.
+func (r *RevokedTokenRepo) PurgeExpired(now time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < ?`, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge revoked tokens: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevokedTokenRepo_RevokeAndPurge(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRevokedTokenRepo(db)
+
+	if err := repo.Revoke("expired", "user-1", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := repo.Revoke("live", "user-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := repo.Revoke("live", "user-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("expected revoking twice to be a no-op, got %v", err)
+	}
+
+	if revoked, err := repo.IsRevoked("live"); err != nil || !revoked {
+		t.Errorf("expected the token to be revoked, got %v, %v", revoked, err)
+	}
+	if revoked, _ := repo.IsRevoked("unknown"); revoked {
+		t.Error("expected an unknown token not to be revoked")
+	}
+
+	purged, err := repo.PurgeExpired(time.Now())
+	if err != nil || purged != 1 {
+		t.Fatalf("expected the expired token to be purged, got %d, %v", purged, err)
+	}
+	if revoked, _ := repo.IsRevoked("live"); !revoked {
+		t.Error("expected the live token to stay revoked")
+	}
+}
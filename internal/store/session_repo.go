@@ -0,0 +1,136 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+	"time"
+)
+
+type SessionRepo struct {
+	db dbtx
+}
+
+func NewSessionRepo(db *sql.DB) *SessionRepo {
+	return &SessionRepo{db: db}
+}
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_seen_at, expires_at FROM sessions`
+
+// liveSession matches sessions that are neither revoked nor expired.
+const liveSession = `revoked_at IS NULL AND expires_at > ?`
+
+func scanSession(row rowScanner) (*domain.Session, error) {
+	var session domain.Session
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IP,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepo) Create(session *domain.Session) error {
+	query := `INSERT INTO sessions (id, user_id, user_agent, ip, created_at, last_seen_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query, session.ID, session.UserID, session.UserAgent, session.IP,
+		session.CreatedAt.UTC(), session.LastSeenAt.UTC(), session.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SessionRepo) GetByID(id string) (*domain.Session, error) {
+	session, err := scanSession(r.db.QueryRow(`SELECT `+sessionColumns+` WHERE id = ? AND `+liveSession, id, time.Now().UTC()))
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
+
+func (r *SessionRepo) GetByUserID(userID string) ([]*domain.Session, error) {
+	query := `SELECT ` + sessionColumns + ` WHERE user_id = ? AND ` + liveSession + ` ORDER BY last_seen_at DESC, id`
+
+	rows, err := r.db.Query(query, userID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*domain.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+func (r *SessionRepo) Touch(id, userAgent, ip string, at, expiresAt time.Time) error {
+	query := `UPDATE sessions SET user_agent = ?, ip = ?, last_seen_at = ?, expires_at = ? WHERE id = ? AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, userAgent, ip, at.UTC(), expiresAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *SessionRepo) Revoke(id string, at time.Time) error {
+	result, err := r.db.Exec(`UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, at.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *SessionRepo) RevokeUser(userID string, at time.Time) error {
+	if _, err := r.db.Exec(`UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`, at.UTC(), userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
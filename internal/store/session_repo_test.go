@@ -0,0 +1,59 @@
+package store
+
+import (
+	"errors"
+	"godo/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestSessionRepo_ListTouchAndRevoke(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewSessionRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	older := domain.NewSession(user.ID, "Firefox", "10.0.0.1", time.Now().Add(time.Hour))
+	newer := domain.NewSession(user.ID, "Safari", "10.0.0.2", time.Now().Add(time.Hour))
+	expired := domain.NewSession(user.ID, "Chrome", "10.0.0.3", time.Now().Add(-time.Minute))
+	for _, session := range []*domain.Session{older, newer, expired} {
+		if err := repo.Create(session); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	seen := time.Now().Add(time.Minute)
+	if err := repo.Touch(older.ID, "Firefox 2", "10.0.0.9", seen, time.Now().Add(2*time.Hour)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sessions, err := repo.GetByUserID(user.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected the 2 live sessions, got %d", len(sessions))
+	}
+	if sessions[0].ID != older.ID || sessions[0].UserAgent != "Firefox 2" || sessions[0].IP != "10.0.0.9" {
+		t.Errorf("expected the touched session first with its new client, got %+v", sessions[0])
+	}
+
+	if err := repo.Revoke(newer.ID, time.Now()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := repo.Revoke(newer.ID, time.Now()); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("expected revoking twice to report ErrSessionNotFound, got %v", err)
+	}
+	if _, err := repo.GetByID(newer.ID); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("expected a revoked session not to be found, got %v", err)
+	}
+	if err := repo.Touch(newer.ID, "Safari", "10.0.0.2", time.Now(), time.Now().Add(time.Hour)); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("expected a revoked session not to be touched, got %v", err)
+	}
+
+	if err := repo.RevokeUser(user.ID, time.Now()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if sessions, _ := repo.GetByUserID(user.ID); len(sessions) != 0 {
+		t.Errorf("expected no live sessions, got %d", len(sessions))
+	}
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+)
+
+type ShareRepo struct {
+	db dbtx
+}
+
+func NewShareRepo(db *sql.DB) *ShareRepo {
+	return &ShareRepo{db: db}
+}
+
+// shareColumns selects a share along with the email of the user it's for.
+const shareColumns = `s.id, COALESCE(s.todo_id, ''), COALESCE(s.project_id, ''), s.user_id, u.email, s.role, s.created_at
+	FROM shares s JOIN users u ON u.id = s.user_id`
+
+func scanShare(row rowScanner) (*domain.Share, error) {
+	var share domain.Share
+	err := row.Scan(
+		&share.ID,
+		&share.TodoID,
+		&share.ProjectID,
+		&share.UserID,
+		&share.UserEmail,
+		&share.Role,
+		&share.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+func (r *ShareRepo) Save(share *domain.Share) error {
+	target := "(todo_id, user_id) WHERE todo_id IS NOT NULL"
+	if share.ProjectID != "" {
+		target = "(project_id, user_id) WHERE project_id IS NOT NULL"
+	}
+	query := `INSERT INTO shares (id, todo_id, project_id, user_id, role, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT ` + target + ` DO UPDATE SET role = excluded.role
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(query, share.ID, nullString(share.TodoID), nullString(share.ProjectID),
+		share.UserID, share.Role, share.CreatedAt.UTC()).Scan(&share.ID, &share.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save share: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ShareRepo) GetByID(id string) (*domain.Share, error) {
+	share, err := scanShare(r.db.QueryRow(`SELECT `+shareColumns+` WHERE s.id = ?`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrShareNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share: %w", err)
+	}
+
+	return share, nil
+}
+
+func (r *ShareRepo) GetByTodoID(todoID string) ([]*domain.Share, error) {
+	return r.query(`SELECT `+shareColumns+` WHERE s.todo_id = ? ORDER BY u.email`, todoID)
+}
+
+func (r *ShareRepo) GetByProjectID(projectID string) ([]*domain.Share, error) {
+	return r.query(`SELECT `+shareColumns+` WHERE s.project_id = ? ORDER BY u.email`, projectID)
+}
+
+func (r *ShareRepo) query(query string, args ...any) ([]*domain.Share, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shares: %w", err)
+	}
+	defer rows.Close()
+
+	shares := make([]*domain.Share, 0)
+	for rows.Next() {
+		share, err := scanShare(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share: %w", err)
+		}
+		shares = append(shares, share)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+func (r *ShareRepo) Delete(id string) error {
+	query := `DELETE FROM shares WHERE id = ?`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete share: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrShareNotFound
+	}
+
+	return nil
+}
+
+func (r *ShareRepo) RoleFor(userID, todoID, projectID string) (domain.ShareRole, error) {
+	query := `SELECT role FROM shares
+		WHERE user_id = ? AND (todo_id = ? OR project_id = ?)
+		ORDER BY role = 'editor' DESC LIMIT 1`
+
+	var role domain.ShareRole
+	err := r.db.QueryRow(query, userID, todoID, projectID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get share role: %w", err)
+	}
+
+	return role, nil
+}
+
+// nullString stores an empty string as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"godo/internal/domain"
+	"testing"
+)
+
+func TestShareRepo_SaveUpsertsRole(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	shareRepo := NewShareRepo(db)
+	todo := createSubtaskTestTodo(t, NewTodoRepo(db), userRepo)
+	friend := createTagTestUser(t, userRepo)
+
+	first := domain.NewTodoShare(todo.ID, friend.ID, domain.ShareViewer)
+	if err := shareRepo.Save(first); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	again := domain.NewTodoShare(todo.ID, friend.ID, domain.ShareEditor)
+	if err := shareRepo.Save(again); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if again.ID != first.ID {
+		t.Errorf("expected sharing again to keep share %s, got %s", first.ID, again.ID)
+	}
+
+	shares, err := shareRepo.GetByTodoID(todo.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(shares) != 1 || shares[0].Role != domain.ShareEditor || shares[0].UserEmail != friend.Email {
+		t.Fatalf("expected one editor share for %s, got %+v", friend.Email, shares)
+	}
+
+	if err := shareRepo.Delete(first.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := shareRepo.Delete(first.ID); err != domain.ErrShareNotFound {
+		t.Errorf("expected ErrShareNotFound, got %v", err)
+	}
+}
+
+func TestShareRepo_RoleFor(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+	shareRepo := NewShareRepo(db)
+	owner := createTagTestUser(t, userRepo)
+	friend := createTagTestUser(t, userRepo)
+
+	project := domain.NewProject(owner.ID, "Shared", "")
+	NewProjectRepo(db).Create(project)
+	todo := domain.NewTodo(owner.ID, "In project", "")
+	todo.ProjectID = project.ID
+	todoRepo.Create(todo)
+
+	role, err := shareRepo.RoleFor(friend.ID, todo.ID, todo.ProjectID)
+	if err != nil || role != "" {
+		t.Fatalf("expected no role before sharing, got %q (%v)", role, err)
+	}
+
+	shareRepo.Save(domain.NewTodoShare(todo.ID, friend.ID, domain.ShareViewer))
+	shareRepo.Save(domain.NewProjectShare(project.ID, friend.ID, domain.ShareEditor))
+
+	// The strongest of the todo and project shares wins
+	role, err = shareRepo.RoleFor(friend.ID, todo.ID, todo.ProjectID)
+	if err != nil || role != domain.ShareEditor {
+		t.Errorf("expected editor, got %q (%v)", role, err)
+	}
+}
+
+func TestGetSharedWith(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+	projectRepo := NewProjectRepo(db)
+	shareRepo := NewShareRepo(db)
+	owner := createTagTestUser(t, userRepo)
+	friend := createTagTestUser(t, userRepo)
+
+	project := domain.NewProject(owner.ID, "Groceries", "")
+	projectRepo.Create(project)
+
+	direct := domain.NewTodo(owner.ID, "Shared directly", "")
+	inProject := domain.NewTodo(owner.ID, "Shared through project", "")
+	inProject.ProjectID = project.ID
+	private := domain.NewTodo(owner.ID, "Private", "")
+	for _, todo := range []*domain.Todo{direct, inProject, private} {
+		todoRepo.Create(todo)
+	}
+
+	shareRepo.Save(domain.NewTodoShare(direct.ID, friend.ID, domain.ShareViewer))
+	shareRepo.Save(domain.NewProjectShare(project.ID, friend.ID, domain.ShareViewer))
+
+	todos, _, err := todoRepo.GetSharedWith(friend.ID, domain.TodoFilter{}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(todos) != 2 {
+		t.Fatalf("expected 2 shared todos, got %d", len(todos))
+	}
+	for _, todo := range todos {
+		if todo.ID == private.ID {
+			t.Errorf("expected the private todo to stay hidden")
+		}
+	}
+
+	projects, err := projectRepo.GetSharedWith(friend.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(projects) != 1 || projects[0].ID != project.ID {
+		t.Errorf("expected the shared project, got %d projects", len(projects))
+	}
+}
@@ -70,16 +70,114 @@ func RunMigrations(db *sql.DB, migrationPath string) error {
 			return fmt.Errorf("failed to read migration %s: %w", fileName, err)
 		}
 
-		_, err = db.Exec(string(content))
-		if err != nil {
+		if err := applyMigration(db, version, string(content)); err != nil {
 			return fmt.Errorf("failed to run migration %s: %w", fileName, err)
 		}
+	}
 
-		_, err = db.Exec("INSERT INTO schema_migrations (version) VALUES (?)", version)
-		if err != nil {
-			return fmt.Errorf("failed to record migration %s: %w", fileName, err)
+	return nil
+}
+
+// applyMigration runs every statement of a migration and records its version
+// in a single transaction. The libsql driver only executes the first statement
+// of a multi-statement string, so the file is split up front.
+func applyMigration(db *sql.DB, version, content string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(content) {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES (?)", version); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// splitStatements splits a SQL script on semicolons, ignoring those inside
+// quotes and comments. Semicolons inside a CREATE TRIGGER body do not end the
+// statement; only the one following its closing END does.
+func splitStatements(script string) []string {
+	var stmts []string
+	var current strings.Builder
+
+	flush := func() {
+		stmt := strings.TrimSpace(current.String())
+		if stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(script[i+1:], c)
+			if end < 0 {
+				current.WriteString(script[i:])
+				i = len(script)
+				continue
+			}
+			current.WriteString(script[i : i+end+2])
+			i += end + 1
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += end
+			current.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += end + 3
+		case c == ';':
+			if isTrigger(current.String()) && !endsWithEnd(current.String()) {
+				current.WriteByte(c)
+				continue
+			}
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	return stmts
+}
+
+// isTrigger reports whether stmt creates a trigger, whose body holds
+// semicolons of its own. DROP TRIGGER has no body.
+func isTrigger(stmt string) bool {
+	fields := strings.Fields(strings.ToUpper(stmt))
+	if len(fields) == 0 || fields[0] != "CREATE" {
+		return false
+	}
+	for i, f := range fields {
+		if i > 2 {
+			break
+		}
+		if f == "TRIGGER" {
+			return true
+		}
+	}
+	return false
+}
+
+func endsWithEnd(stmt string) bool {
+	fields := strings.Fields(strings.ToUpper(stmt))
+	return len(fields) > 0 && fields[len(fields)-1] == "END"
 }
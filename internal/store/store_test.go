@@ -2,8 +2,11 @@ package store
 
 import (
 	"database/sql"
+	"godo/internal/domain"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func setupTestDB(t *testing.T) *sql.DB {
@@ -23,6 +26,21 @@ func setupTestDB(t *testing.T) *sql.DB {
 	return db
 }
 
+// inEachZone runs test with time.Local set to UTC and to a zone behind it.
+// Times are compared as text in the database, so they must be stored the
+// same whatever zone the server runs in.
+func inEachZone(t *testing.T, test func(t *testing.T)) {
+	for _, zone := range []*time.Location{time.UTC, time.FixedZone("UTC-4", -4*60*60)} {
+		t.Run(zone.String(), func(t *testing.T) {
+			local := time.Local
+			time.Local = zone
+			defer func() { time.Local = local }()
+
+			test(t)
+		})
+	}
+}
+
 func TestNewDB_Success(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -64,3 +82,128 @@ func TestRunMigrations_Idempotent(t *testing.T) {
 		t.Fatalf("Second migration run failed: %v", err)
 	}
 }
+
+func TestRunMigrations_CreatesIndexes(t *testing.T) {
+	db := setupTestDB(t)
+
+	// Indexes are declared after the CREATE TABLE statement in the same file
+	var name string
+	err := db.QueryRow("SELECT name FROM sqlite_master WHERE type='index' AND name='idx_todos_user_id'").Scan(&name)
+	if err != nil {
+		t.Errorf("idx_todos_user_id not found: %v", err)
+	}
+}
+
+func TestRunMigrations_RewritesTimesInUTC(t *testing.T) {
+	db := setupTestDB(t)
+
+	_, err := db.Exec(`INSERT INTO users (id, email, password_hash, role, created_at, auto_archive_days)
+		VALUES ('u1', 'a@example.com', 'x', 'user', '2026-10-16T23:43:50.2073-04:00', 0),
+		       ('u2', 'b@example.com', 'x', 'user', '2026-10-16T23:43:50+02:00', 0)`)
+	if err != nil {
+		t.Fatalf("failed to insert users: %v", err)
+	}
+	if _, err := db.Exec("DELETE FROM schema_migrations WHERE version = '000024_store_todo_and_user_times_in_utc'"); err != nil {
+		t.Fatalf("failed to forget migration: %v", err)
+	}
+	if err := RunMigrations(db, "../../migrations"); err != nil {
+		t.Fatalf("failed to rerun migration: %v", err)
+	}
+
+	for id, expected := range map[string]string{
+		"u1": "2026-10-17T03:43:50.2073Z",
+		"u2": "2026-10-16T21:43:50Z",
+	} {
+		var createdAt string
+		if err := db.QueryRow("SELECT created_at FROM users WHERE id = ?", id).Scan(&createdAt); err != nil {
+			t.Fatalf("failed to read %s: %v", id, err)
+		}
+		if createdAt != expected {
+			t.Errorf("%s: expected %s, got %s", id, expected, createdAt)
+		}
+	}
+}
+
+func TestRepos_StoreTimesInUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC-4", -4*60*60)
+	defer func() { time.Local = local }()
+
+	db := setupTestDB(t)
+	user := &domain.User{ID: domain.NewID(), Email: "utc@example.com", PasswordHash: "hash", Role: domain.RoleUser}
+	other := &domain.User{ID: domain.NewID(), Email: "other@example.com", PasswordHash: "hash", Role: domain.RoleUser}
+	todo := domain.NewTodo(user.ID, "Todo", "")
+	blocker := domain.NewTodo(user.ID, "Blocker", "")
+	subtask := domain.NewSubtask(todo.ID, "Step", 0)
+	comment := domain.NewComment(todo.ID, user.ID, "Note")
+
+	for _, create := range []func() error{
+		func() error { return NewUserRepo(db).Create(user) },
+		func() error { return NewUserRepo(db).Create(other) },
+		func() error { return NewTodoRepo(db).Create(todo) },
+		func() error { return NewTodoRepo(db).Create(blocker) },
+		func() error { return NewProjectRepo(db).Create(domain.NewProject(user.ID, "Project", "")) },
+		func() error { return NewTagRepo(db).Create(domain.NewTag(user.ID, "tag")) },
+		func() error { return NewSubtaskRepo(db).Create(subtask) },
+		func() error { return NewSubtaskRepo(db).CompleteAll(todo.ID) },
+		func() error { return NewCommentRepo(db).Create(comment) },
+		func() error { return NewCommentRepo(db).Update(comment) },
+		func() error {
+			return NewAttachmentRepo(db).Create(domain.NewAttachment(todo.ID, user.ID, "a.txt", "text/plain", 1))
+		},
+		func() error { return NewShareRepo(db).Save(domain.NewTodoShare(todo.ID, other.ID, domain.ShareViewer)) },
+		func() error { return NewDependencyRepo(db).Add(todo.ID, blocker.ID) },
+		func() error {
+			return NewRevisionRepo(db).Create(domain.NewTodoRevision(todo.ID, 1, user.ID, todo.State()))
+		},
+	} {
+		if err := create(); err != nil {
+			t.Fatalf("failed to create row: %v", err)
+		}
+	}
+
+	for _, column := range []string{
+		"users.created_at", "todos.created_at", "todos.updated_at", "projects.created_at", "projects.updated_at",
+		"tags.created_at", "subtasks.created_at", "subtasks.updated_at", "comments.created_at", "comments.updated_at",
+		"attachments.created_at", "shares.created_at", "todo_dependencies.created_at", "todo_revisions.created_at",
+	} {
+		table, name, _ := strings.Cut(column, ".")
+		var offset int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table + " WHERE " + name + " NOT LIKE '%Z'").Scan(&offset); err != nil {
+			t.Fatalf("failed to read %s: %v", column, err)
+		}
+		if offset > 0 {
+			t.Errorf("expected %s in UTC, got %d rows with an offset", column, offset)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `
+-- comment; with a semicolon
+CREATE TABLE a (id TEXT DEFAULT 'x;y');
+CREATE TRIGGER a_ai AFTER INSERT ON a BEGIN
+    INSERT INTO b VALUES (new.id);
+    INSERT INTO c VALUES (new.id);
+END;
+/* trailing; */
+DROP TRIGGER a_ai;
+DROP TABLE a`
+
+	stmts := splitStatements(script)
+	if len(stmts) != 4 {
+		t.Fatalf("expected 4 statements, got %d: %q", len(stmts), stmts)
+	}
+	if stmts[0] != "CREATE TABLE a (id TEXT DEFAULT 'x;y')" {
+		t.Errorf("unexpected first statement %q", stmts[0])
+	}
+	if !strings.HasSuffix(stmts[1], "END") {
+		t.Errorf("expected trigger to end with END, got %q", stmts[1])
+	}
+	if stmts[2] != "DROP TRIGGER a_ai" {
+		t.Errorf("unexpected third statement %q", stmts[2])
+	}
+	if stmts[3] != "DROP TABLE a" {
+		t.Errorf("unexpected last statement %q", stmts[3])
+	}
+}
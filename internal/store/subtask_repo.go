@@ -0,0 +1,165 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+	"time"
+)
+
+type SubtaskRepo struct {
+	db dbtx
+}
+
+func NewSubtaskRepo(db *sql.DB) *SubtaskRepo {
+	return &SubtaskRepo{db: db}
+}
+
+// Create appends the subtask after the todo's existing subtasks, overwriting
+// subtask.Position.
+func (r *SubtaskRepo) Create(subtask *domain.Subtask) error {
+	query := `INSERT INTO subtasks (id, todo_id, title, completed, position, created_at, updated_at)
+		VALUES (?, ?, ?, ?, (SELECT COALESCE(MAX(position), -1) + 1 FROM subtasks WHERE todo_id = ?), ?, ?)
+		RETURNING position`
+
+	err := r.db.QueryRow(query, subtask.ID, subtask.TodoID, subtask.Title, subtask.Completed,
+		subtask.TodoID, subtask.CreatedAt.UTC(), subtask.UpdatedAt.UTC()).Scan(&subtask.Position)
+	if err != nil {
+		return fmt.Errorf("failed to create subtask: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SubtaskRepo) GetByID(id string) (*domain.Subtask, error) {
+	query := `SELECT id, todo_id, title, completed, position, created_at, updated_at
+		FROM subtasks WHERE id = ?`
+
+	var subtask domain.Subtask
+	err := r.db.QueryRow(query, id).Scan(
+		&subtask.ID,
+		&subtask.TodoID,
+		&subtask.Title,
+		&subtask.Completed,
+		&subtask.Position,
+		&subtask.CreatedAt,
+		&subtask.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrSubtaskNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtask: %w", err)
+	}
+
+	return &subtask, nil
+}
+
+func (r *SubtaskRepo) GetByTodoID(todoID string) ([]*domain.Subtask, error) {
+	query := `SELECT id, todo_id, title, completed, position, created_at, updated_at
+		FROM subtasks WHERE todo_id = ? ORDER BY position, created_at`
+
+	rows, err := r.db.Query(query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subtasks: %w", err)
+	}
+	defer rows.Close()
+
+	subtasks := make([]*domain.Subtask, 0)
+	for rows.Next() {
+		var subtask domain.Subtask
+		err := rows.Scan(
+			&subtask.ID,
+			&subtask.TodoID,
+			&subtask.Title,
+			&subtask.Completed,
+			&subtask.Position,
+			&subtask.CreatedAt,
+			&subtask.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subtask: %w", err)
+		}
+		subtasks = append(subtasks, &subtask)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating subtasks: %w", err)
+	}
+
+	return subtasks, nil
+}
+
+func (r *SubtaskRepo) Update(subtask *domain.Subtask) error {
+	query := `UPDATE subtasks SET title = ?, completed = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.Exec(query, subtask.Title, subtask.Completed, subtask.UpdatedAt.UTC(), subtask.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update subtask: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrSubtaskNotFound
+	}
+
+	return nil
+}
+
+func (r *SubtaskRepo) Delete(id string) error {
+	query := `DELETE FROM subtasks WHERE id = ?`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete subtask: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrSubtaskNotFound
+	}
+
+	return nil
+}
+
+// Reorder assigns positions following the order of ids. Every id must belong
+// to the todo.
+func (r *SubtaskRepo) Reorder(todoID string, ids []string) error {
+	return inTx(r.db, func(tx dbtx) error {
+		for i, id := range ids {
+			result, err := tx.Exec(`UPDATE subtasks SET position = ? WHERE id = ? AND todo_id = ?`, i, id, todoID)
+			if err != nil {
+				return fmt.Errorf("failed to reorder subtasks: %w", err)
+			}
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if rows == 0 {
+				return domain.ErrSubtaskNotFound
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *SubtaskRepo) CompleteAll(todoID string) error {
+	query := `UPDATE subtasks SET completed = 1, updated_at = ? WHERE todo_id = ? AND completed = 0`
+
+	if _, err := r.db.Exec(query, time.Now().UTC(), todoID); err != nil {
+		return fmt.Errorf("failed to complete subtasks: %w", err)
+	}
+
+	return nil
+}
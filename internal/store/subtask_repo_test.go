@@ -0,0 +1,108 @@
+package store
+
+import (
+	"godo/internal/domain"
+	"testing"
+)
+
+func createSubtaskTestTodo(t *testing.T, todoRepo *TodoRepo, userRepo *UserRepo) *domain.Todo {
+	t.Helper()
+	user := createTagTestUser(t, userRepo)
+	todo := domain.NewTodo(user.ID, "Parent", "")
+	if err := todoRepo.Create(todo); err != nil {
+		t.Fatalf("failed to create todo: %v", err)
+	}
+	return todo
+}
+
+func TestSubtaskRepo_Create_AppendsPositions(t *testing.T) {
+	db := setupTestDB(t)
+	subtaskRepo := NewSubtaskRepo(db)
+	todo := createSubtaskTestTodo(t, NewTodoRepo(db), NewUserRepo(db))
+
+	first := domain.NewSubtask(todo.ID, "First", 0)
+	second := domain.NewSubtask(todo.ID, "Second", 0)
+	if err := subtaskRepo.Create(first); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := subtaskRepo.Create(second); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if first.Position != 0 || second.Position != 1 {
+		t.Errorf("expected positions 0 and 1, got %d and %d", first.Position, second.Position)
+	}
+}
+
+func TestSubtaskRepo_Reorder(t *testing.T) {
+	db := setupTestDB(t)
+	subtaskRepo := NewSubtaskRepo(db)
+	todo := createSubtaskTestTodo(t, NewTodoRepo(db), NewUserRepo(db))
+
+	a := domain.NewSubtask(todo.ID, "A", 0)
+	b := domain.NewSubtask(todo.ID, "B", 0)
+	c := domain.NewSubtask(todo.ID, "C", 0)
+	subtaskRepo.Create(a)
+	subtaskRepo.Create(b)
+	subtaskRepo.Create(c)
+
+	if err := subtaskRepo.Reorder(todo.ID, []string{c.ID, a.ID, b.ID}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	subtasks, _ := subtaskRepo.GetByTodoID(todo.ID)
+	if subtasks[0].ID != c.ID || subtasks[1].ID != a.ID || subtasks[2].ID != b.ID {
+		t.Errorf("unexpected order: %s, %s, %s", subtasks[0].Title, subtasks[1].Title, subtasks[2].Title)
+	}
+}
+
+func TestSubtaskRepo_Reorder_ForeignSubtask(t *testing.T) {
+	db := setupTestDB(t)
+	subtaskRepo := NewSubtaskRepo(db)
+	todoRepo := NewTodoRepo(db)
+	userRepo := NewUserRepo(db)
+	todo := createSubtaskTestTodo(t, todoRepo, userRepo)
+	other := createSubtaskTestTodo(t, todoRepo, userRepo)
+
+	foreign := domain.NewSubtask(other.ID, "Foreign", 0)
+	subtaskRepo.Create(foreign)
+
+	if err := subtaskRepo.Reorder(todo.ID, []string{foreign.ID}); err != domain.ErrSubtaskNotFound {
+		t.Errorf("expected ErrSubtaskNotFound, got %v", err)
+	}
+}
+
+func TestSubtaskRepo_ProgressOnTodo(t *testing.T) {
+	db := setupTestDB(t)
+	subtaskRepo := NewSubtaskRepo(db)
+	todoRepo := NewTodoRepo(db)
+	todo := createSubtaskTestTodo(t, todoRepo, NewUserRepo(db))
+
+	done := domain.NewSubtask(todo.ID, "Done", 0)
+	done.Completed = true
+	subtaskRepo.Create(done)
+	subtaskRepo.Create(domain.NewSubtask(todo.ID, "Open", 0))
+
+	saved, _ := todoRepo.GetByID(todo.ID)
+	if saved.Progress.Done != 1 || saved.Progress.Total != 2 {
+		t.Errorf("expected progress 1/2, got %s", saved.Progress)
+	}
+
+	if err := subtaskRepo.CompleteAll(todo.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	saved, _ = todoRepo.GetByID(todo.ID)
+	if saved.Progress.Done != 2 {
+		t.Errorf("expected progress 2/2, got %s", saved.Progress)
+	}
+}
+
+func TestSubtaskRepo_Delete_NotFound(t *testing.T) {
+	db := setupTestDB(t)
+	subtaskRepo := NewSubtaskRepo(db)
+
+	if err := subtaskRepo.Delete("nonexistent-id"); err != domain.ErrSubtaskNotFound {
+		t.Errorf("expected ErrSubtaskNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+)
+
+type TagRepo struct {
+	db dbtx
+}
+
+func NewTagRepo(db *sql.DB) *TagRepo {
+	return &TagRepo{db: db}
+}
+
+func (r *TagRepo) Create(tag *domain.Tag) error {
+	query := `INSERT INTO tags (id, user_id, name, created_at) VALUES (?, ?, ?, ?)`
+
+	_, err := r.db.Exec(query, tag.ID, tag.UserID, tag.Name, tag.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TagRepo) GetByID(id string) (*domain.Tag, error) {
+	query := `SELECT id, user_id, name, created_at FROM tags WHERE id = ?`
+
+	var tag domain.Tag
+	err := r.db.QueryRow(query, id).Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrTagNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	return &tag, nil
+}
+
+func (r *TagRepo) GetByName(userID, name string) (*domain.Tag, error) {
+	query := `SELECT id, user_id, name, created_at FROM tags WHERE user_id = ? AND name = ?`
+
+	var tag domain.Tag
+	err := r.db.QueryRow(query, userID, name).Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrTagNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag by name: %w", err)
+	}
+
+	return &tag, nil
+}
+
+func (r *TagRepo) GetByUserID(userID string) ([]*domain.Tag, error) {
+	query := `SELECT id, user_id, name, created_at FROM tags WHERE user_id = ? ORDER BY name`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]*domain.Tag, 0)
+	for rows.Next() {
+		var tag domain.Tag
+		if err := rows.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, &tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (r *TagRepo) Update(tag *domain.Tag) error {
+	query := `UPDATE tags SET name = ? WHERE id = ?`
+
+	result, err := r.db.Exec(query, tag.Name, tag.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	return nil
+}
+
+func (r *TagRepo) Delete(id string) error {
+	query := `DELETE FROM tags WHERE id = ?`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	return nil
+}
+
+// SetTodoTags replaces the tags attached to a todo.
+func (r *TagRepo) SetTodoTags(todoID string, tagIDs []string) error {
+	return inTx(r.db, func(tx dbtx) error {
+		if _, err := tx.Exec(`DELETE FROM todo_tags WHERE todo_id = ?`, todoID); err != nil {
+			return fmt.Errorf("failed to clear todo tags: %w", err)
+		}
+
+		for _, tagID := range tagIDs {
+			if _, err := tx.Exec(`INSERT INTO todo_tags (todo_id, tag_id) VALUES (?, ?)`, todoID, tagID); err != nil {
+				return fmt.Errorf("failed to tag todo: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
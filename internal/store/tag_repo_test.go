@@ -0,0 +1,161 @@
+package store
+
+import (
+	"godo/internal/domain"
+	"testing"
+)
+
+func createTagTestUser(t *testing.T, userRepo *UserRepo) *domain.User {
+	t.Helper()
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        domain.NewID() + "@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleUser,
+	}
+	if err := userRepo.Create(user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+func TestTagRepo_Create_Success(t *testing.T) {
+	db := setupTestDB(t)
+	tagRepo := NewTagRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	tag := domain.NewTag(user.ID, "work")
+	if err := tagRepo.Create(tag); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	found, err := tagRepo.GetByName(user.ID, "work")
+	if err != nil {
+		t.Fatalf("failed to get tag by name: %v", err)
+	}
+	if found.ID != tag.ID {
+		t.Errorf("expected ID %q, got %q", tag.ID, found.ID)
+	}
+}
+
+func TestTagRepo_Create_DuplicateName(t *testing.T) {
+	db := setupTestDB(t)
+	tagRepo := NewTagRepo(db)
+	userRepo := NewUserRepo(db)
+	user := createTagTestUser(t, userRepo)
+	other := createTagTestUser(t, userRepo)
+
+	tagRepo.Create(domain.NewTag(user.ID, "work"))
+
+	if err := tagRepo.Create(domain.NewTag(user.ID, "work")); err == nil {
+		t.Error("expected error for duplicate tag name")
+	}
+
+	// Names are only unique per user
+	if err := tagRepo.Create(domain.NewTag(other.ID, "work")); err != nil {
+		t.Errorf("expected other user to reuse the name, got %v", err)
+	}
+}
+
+func TestTagRepo_GetByID_NotFound(t *testing.T) {
+	db := setupTestDB(t)
+	tagRepo := NewTagRepo(db)
+
+	_, err := tagRepo.GetByID("nonexistent-id")
+	if err != domain.ErrTagNotFound {
+		t.Errorf("expected ErrTagNotFound, got %v", err)
+	}
+}
+
+func TestTagRepo_SetTodoTags(t *testing.T) {
+	db := setupTestDB(t)
+	tagRepo := NewTagRepo(db)
+	todoRepo := NewTodoRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	work := domain.NewTag(user.ID, "work")
+	home := domain.NewTag(user.ID, "home")
+	tagRepo.Create(work)
+	tagRepo.Create(home)
+
+	todo := domain.NewTodo(user.ID, "Tagged", "")
+	todoRepo.Create(todo)
+
+	if err := tagRepo.SetTodoTags(todo.ID, []string{work.ID, home.ID}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	saved, _ := todoRepo.GetByID(todo.ID)
+	if len(saved.Tags) != 2 || saved.Tags[0] != "home" || saved.Tags[1] != "work" {
+		t.Errorf("expected tags [home work], got %v", saved.Tags)
+	}
+
+	// Replacing the set drops tags that are no longer listed
+	if err := tagRepo.SetTodoTags(todo.ID, []string{work.ID}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	saved, _ = todoRepo.GetByID(todo.ID)
+	if len(saved.Tags) != 1 || saved.Tags[0] != "work" {
+		t.Errorf("expected tags [work], got %v", saved.Tags)
+	}
+}
+
+func TestTagRepo_Delete_RemovesFromTodos(t *testing.T) {
+	db := setupTestDB(t)
+	tagRepo := NewTagRepo(db)
+	todoRepo := NewTodoRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	tag := domain.NewTag(user.ID, "work")
+	tagRepo.Create(tag)
+	todo := domain.NewTodo(user.ID, "Tagged", "")
+	todoRepo.Create(todo)
+	tagRepo.SetTodoTags(todo.ID, []string{tag.ID})
+
+	if err := tagRepo.Delete(tag.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	saved, _ := todoRepo.GetByID(todo.ID)
+	if len(saved.Tags) != 0 {
+		t.Errorf("expected no tags after delete, got %v", saved.Tags)
+	}
+}
+
+func TestTodoRepo_GetByUserID_TagFilter(t *testing.T) {
+	db := setupTestDB(t)
+	tagRepo := NewTagRepo(db)
+	todoRepo := NewTodoRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	work := domain.NewTag(user.ID, "work")
+	urgent := domain.NewTag(user.ID, "urgent")
+	tagRepo.Create(work)
+	tagRepo.Create(urgent)
+
+	both := domain.NewTodo(user.ID, "Both", "")
+	workOnly := domain.NewTodo(user.ID, "Work only", "")
+	untagged := domain.NewTodo(user.ID, "Untagged", "")
+	todoRepo.Create(both)
+	todoRepo.Create(workOnly)
+	todoRepo.Create(untagged)
+	tagRepo.SetTodoTags(both.ID, []string{work.ID, urgent.ID})
+	tagRepo.SetTodoTags(workOnly.ID, []string{work.ID})
+
+	anyTodos, _, err := todoRepo.GetByUserID(user.ID, domain.TodoFilter{Tags: []string{"work", "urgent"}}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(anyTodos) != 2 {
+		t.Errorf("expected 2 todos matching any tag, got %d", len(anyTodos))
+	}
+
+	allTodos, _, err := todoRepo.GetByUserID(user.ID, domain.TodoFilter{Tags: []string{"work", "urgent"}, MatchAllTags: true}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(allTodos) != 1 || allTodos[0].ID != both.ID {
+		t.Errorf("expected only the todo with both tags, got %d todos", len(allTodos))
+	}
+}
@@ -4,42 +4,295 @@ import (
 	"database/sql"
 	"fmt"
 	"godo/internal/domain"
+	"html"
+	"strings"
+	"time"
+	"unicode"
 )
 
+const todoColumns = `id, user_id, project_id, title, description, completed, priority, position, due_date, due_time, due_timezone, due_at, recurrence, created_at, updated_at, completed_at, archived_at, deleted_at,
+	assignee_id, (SELECT email FROM users u WHERE u.id = todos.assignee_id),
+	EXISTS (SELECT 1 FROM todo_dependencies d JOIN todos b ON b.id = d.blocker_id
+		WHERE d.todo_id = todos.id AND b.completed = 0 AND b.deleted_at IS NULL),
+	(SELECT COUNT(*) FROM subtasks s WHERE s.todo_id = todos.id AND s.completed = 1),
+	(SELECT COUNT(*) FROM subtasks s WHERE s.todo_id = todos.id)`
+
 type TodoRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewTodoRepo(db *sql.DB) *TodoRepo {
 	return &TodoRepo{db: db}
 }
 
-func (r *TodoRepo) Create(todo *domain.Todo) error {
-	query := `INSERT INTO todos (id, user_id, title, description, completed, created_at, updated_at)
-			  VALUES (?, ?, ?, ?, ?, ?, ?)`
-
-	_, err := r.db.Exec(query, todo.ID, todo.UserID, todo.Title, todo.Description, todo.Completed, todo.CreatedAt, todo.UpdatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to create todo: %w", err)
-	}
-
-	return nil
+// placeholders returns n comma-separated bind parameters for an IN clause.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
 }
 
-func (r *TodoRepo) GetByID(id string) (*domain.Todo, error) {
-	query := `SELECT id, user_id, title, description, completed, created_at, updated_at
-			  FROM todos WHERE id = ?`
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
+func scanTodo(s rowScanner) (*domain.Todo, error) {
 	var todo domain.Todo
-	err := r.db.QueryRow(query, id).Scan(
+	// The driver hands back date-shaped TEXT as time.Time, so due_date is
+	// scanned as a time and formatted back to YYYY-MM-DD below.
+	var dueDate, dueAt, completedAt, archivedAt, deletedAt sql.NullTime
+	var projectID, dueTime, dueTimezone, recurrence, assigneeID, assigneeEmail sql.NullString
+
+	err := s.Scan(
 		&todo.ID,
 		&todo.UserID,
+		&projectID,
 		&todo.Title,
 		&todo.Description,
 		&todo.Completed,
+		&todo.Priority,
+		&todo.Position,
+		&dueDate,
+		&dueTime,
+		&dueTimezone,
+		&dueAt,
+		&recurrence,
 		&todo.CreatedAt,
 		&todo.UpdatedAt,
+		&completedAt,
+		&archivedAt,
+		&deletedAt,
+		&assigneeID,
+		&assigneeEmail,
+		&todo.Blocked,
+		&todo.Progress.Done,
+		&todo.Progress.Total,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	todo.ProjectID = projectID.String
+	todo.AssigneeID = assigneeID.String
+	todo.AssigneeEmail = assigneeEmail.String
+	if completedAt.Valid {
+		todo.CompletedAt = &completedAt.Time
+	}
+	if archivedAt.Valid {
+		todo.ArchivedAt = &archivedAt.Time
+	}
+	if deletedAt.Valid {
+		todo.DeletedAt = &deletedAt.Time
+	}
+
+	if dueDate.Valid && dueAt.Valid {
+		todo.Due = &domain.Due{
+			Date:     dueDate.Time.Format(domain.DueDateLayout),
+			Time:     dueTime.String,
+			Timezone: dueTimezone.String,
+			At:       dueAt.Time,
+		}
+	}
+
+	if recurrence.Valid {
+		todo.Recurrence, err = domain.ParseRecurrence(recurrence.String)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &todo, nil
+}
+
+// projectColumn stores a todo without a project as NULL.
+func projectColumn(projectID string) sql.NullString {
+	return sql.NullString{String: projectID, Valid: projectID != ""}
+}
+
+// assigneeColumn stores an unassigned todo as NULL.
+func assigneeColumn(assigneeID string) sql.NullString {
+	return sql.NullString{String: assigneeID, Valid: assigneeID != ""}
+}
+
+// recurrenceColumn stores a recurrence rule as its RRULE string.
+func recurrenceColumn(recurrence *domain.Recurrence) sql.NullString {
+	if recurrence == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: recurrence.String(), Valid: true}
+}
+
+// timeColumn stores an optional timestamp as NULL when unset.
+func timeColumn(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// dueColumns flattens an optional due date into its nullable column values.
+func dueColumns(due *domain.Due) (date, timeOfDay, timezone sql.NullString, at sql.NullTime) {
+	if due == nil {
+		return
+	}
+	date = sql.NullString{String: due.Date, Valid: true}
+	timeOfDay = sql.NullString{String: due.Time, Valid: due.Time != ""}
+	timezone = sql.NullString{String: due.Timezone, Valid: due.Timezone != ""}
+	at = sql.NullTime{Time: due.At.UTC(), Valid: true}
+	return
+}
+
+// filterClauses turns a TodoFilter into WHERE conditions and their arguments.
+func filterClauses(filter domain.TodoFilter) ([]string, []any) {
+	var clauses []string
+	var args []any
+
+	if filter.ProjectID != "" {
+		clauses = append(clauses, "project_id = ?")
+		args = append(args, filter.ProjectID)
+	}
+	if !filter.IncludeArchived {
+		clauses = append(clauses, "archived_at IS NULL")
+	}
+	if filter.UserID != "" {
+		clauses = append(clauses, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.AssigneeID != "" {
+		clauses = append(clauses, "assignee_id = ?")
+		args = append(args, filter.AssigneeID)
+	}
+	if filter.Completed != nil {
+		clauses = append(clauses, "completed = ?")
+		args = append(args, *filter.Completed)
+	}
+	if filter.TitleContains != "" {
+		clauses = append(clauses, `title LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(filter.TitleContains)+"%")
+	}
+	if filter.Overdue {
+		clauses = append(clauses, "completed = 0 AND due_at IS NOT NULL AND due_at < ?")
+		args = append(args, time.Now().UTC())
+	}
+	if filter.DueOn != "" {
+		clauses = append(clauses, "due_date = ?")
+		args = append(args, filter.DueOn)
+	}
+	if filter.DueBefore != nil {
+		clauses = append(clauses, "due_at < ?")
+		args = append(args, filter.DueBefore.UTC())
+	}
+	if filter.DueAfter != nil {
+		clauses = append(clauses, "due_at > ?")
+		args = append(args, filter.DueAfter.UTC())
+	}
+	if filter.CreatedBefore != nil {
+		clauses = append(clauses, "created_at < ?")
+		args = append(args, filter.CreatedBefore.UTC())
+	}
+	if filter.CreatedAfter != nil {
+		clauses = append(clauses, "created_at > ?")
+		args = append(args, filter.CreatedAfter.UTC())
+	}
+	if filter.UpdatedBefore != nil {
+		clauses = append(clauses, "updated_at < ?")
+		args = append(args, filter.UpdatedBefore.UTC())
+	}
+	if filter.UpdatedAfter != nil {
+		clauses = append(clauses, "updated_at > ?")
+		args = append(args, filter.UpdatedAfter.UTC())
+	}
+	if len(filter.Tags) > 0 {
+		tagged := `id IN (SELECT tt.todo_id FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id
+			WHERE t.name IN (` + placeholders(len(filter.Tags)) + `)`
+		for _, name := range filter.Tags {
+			args = append(args, name)
+		}
+		if filter.MatchAllTags {
+			tagged += ` GROUP BY tt.todo_id HAVING COUNT(DISTINCT t.name) = ?`
+			args = append(args, len(filter.Tags))
+		}
+		clauses = append(clauses, tagged+`)`)
+	}
+
+	return clauses, args
+}
+
+// likeEscaper escapes the LIKE wildcards in a user-supplied substring.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// sortFieldColumns maps the fields a custom ordering may use onto columns.
+var sortFieldColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "title COLLATE NOCASE",
+	"priority":   "priority",
+	"due_at":     "COALESCE(due_at, '')",
+	"completed":  "completed",
+}
+
+// todoSortKeys maps the ordering of a filter onto the keys a listing is
+// ordered and paged by, along with the cursor scope naming that ordering.
+// Undated todos sort after dated ones, and created_at then id break any
+// remaining ties.
+func todoSortKeys(filter domain.TodoFilter) ([]sortKey, string) {
+	tail := []sortKey{{expr: "created_at", desc: true}, {expr: "id", desc: true}}
+	due := []sortKey{{expr: "due_at IS NULL"}, {expr: "COALESCE(due_at, '')"}}
+
+	if len(filter.SortFields) > 0 {
+		var keys []sortKey
+		names := make([]string, len(filter.SortFields))
+		for i, field := range filter.SortFields {
+			if field.Field == "due_at" {
+				// Undated todos stay last whichever way due_at is ordered
+				keys = append(keys, sortKey{expr: "due_at IS NULL"})
+			}
+			keys = append(keys, sortKey{expr: sortFieldColumns[field.Field], desc: field.Desc})
+			names[i] = field.String()
+		}
+		return append(keys, tail...), "todos:" + strings.Join(names, ",")
+	}
+
+	switch filter.Sort {
+	case domain.SortCreated:
+		return tail, "todos:created"
+	case domain.SortPriority:
+		return append(append([]sortKey{{expr: "priority", desc: true}}, due...), tail...), "todos:priority"
+	case domain.SortDue:
+		return append(due, tail...), "todos:due"
+	default:
+		return append([]sortKey{{expr: "position"}}, tail...), "todos:manual"
+	}
+}
+
+func (r *TodoRepo) Create(todo *domain.Todo) error {
+	query := `INSERT INTO todos (id, user_id, project_id, title, description, completed, priority, position, due_date, due_time, due_timezone, due_at, recurrence, created_at, updated_at, completed_at, archived_at, assignee_id)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	dueDate, dueTime, dueTimezone, dueAt := dueColumns(todo.Due)
+
+	_, err := r.db.Exec(query, todo.ID, todo.UserID, projectColumn(todo.ProjectID), todo.Title, todo.Description, todo.Completed, todo.Priority,
+		todo.Position, dueDate, dueTime, dueTimezone, dueAt, recurrenceColumn(todo.Recurrence), todo.CreatedAt.UTC(), todo.UpdatedAt.UTC(),
+		timeColumn(todo.CompletedAt), timeColumn(todo.ArchivedAt), assigneeColumn(todo.AssigneeID))
+	if err != nil {
+		return fmt.Errorf("failed to create todo: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TodoRepo) GetByID(id string) (*domain.Todo, error) {
+	return r.get(`id = ? AND deleted_at IS NULL`, id)
+}
+
+// GetTrashedByID loads a todo that is in the trash.
+func (r *TodoRepo) GetTrashedByID(id string) (*domain.Todo, error) {
+	return r.get(`id = ? AND deleted_at IS NOT NULL`, id)
+}
+
+func (r *TodoRepo) get(where, id string) (*domain.Todo, error) {
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE ` + where
+
+	todo, err := scanTodo(r.db.QueryRow(query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, domain.ErrTodoNotFound
@@ -49,84 +302,193 @@ func (r *TodoRepo) GetByID(id string) (*domain.Todo, error) {
 		return nil, fmt.Errorf("failed to get todo: %w", err)
 	}
 
-	return &todo, nil
+	if err := r.attachTags([]*domain.Todo{todo}); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+func (r *TodoRepo) GetByUserID(userID string, filter domain.TodoFilter, page domain.PageRequest) ([]*domain.Todo, string, error) {
+	clauses, args := filterClauses(filter)
+	clauses = append([]string{"user_id = ?", "deleted_at IS NULL"}, clauses...)
+	args = append([]any{userID}, args...)
+
+	keys, scope := todoSortKeys(filter)
+	return r.list(clauses, args, keys, scope, page)
+}
+
+func (r *TodoRepo) GetAll(filter domain.TodoFilter, page domain.PageRequest) ([]*domain.Todo, string, error) {
+	clauses, args := filterClauses(filter)
+	clauses = append([]string{"deleted_at IS NULL"}, clauses...)
+
+	keys, scope := todoSortKeys(filter)
+	return r.list(clauses, args, keys, scope, page)
+}
+
+// GetSharedWith lists other users' todos shared with the user, directly or
+// through their project.
+func (r *TodoRepo) GetSharedWith(userID string, filter domain.TodoFilter, page domain.PageRequest) ([]*domain.Todo, string, error) {
+	clauses, args := filterClauses(filter)
+	clauses = append([]string{
+		"deleted_at IS NULL",
+		"user_id != ?",
+		`(id IN (SELECT todo_id FROM shares WHERE user_id = ? AND todo_id IS NOT NULL)
+			OR project_id IN (SELECT project_id FROM shares WHERE user_id = ? AND project_id IS NOT NULL))`,
+	}, clauses...)
+	args = append([]any{userID, userID, userID}, args...)
+
+	keys, scope := todoSortKeys(filter)
+	return r.list(clauses, args, keys, scope, page)
+}
+
+// trashSortKeys lists the most recently deleted todos first.
+var trashSortKeys = []sortKey{{expr: "deleted_at", desc: true}, {expr: "id", desc: true}}
+
+// GetTrash lists the todos in the trash. An empty userID lists every user's.
+func (r *TodoRepo) GetTrash(userID string, page domain.PageRequest) ([]*domain.Todo, string, error) {
+	clauses := []string{"deleted_at IS NOT NULL"}
+	var args []any
+	if userID != "" {
+		clauses = append(clauses, "user_id = ?")
+		args = append(args, userID)
+	}
+
+	return r.list(clauses, args, trashSortKeys, "trash", page)
+}
+
+// archiveSortKeys lists the most recently archived todos first.
+var archiveSortKeys = []sortKey{{expr: "archived_at", desc: true}, {expr: "id", desc: true}}
+
+// GetArchive lists archived todos. An empty userID lists every user's.
+func (r *TodoRepo) GetArchive(userID string, page domain.PageRequest) ([]*domain.Todo, string, error) {
+	clauses := []string{"archived_at IS NOT NULL", "deleted_at IS NULL"}
+	var args []any
+	if userID != "" {
+		clauses = append(clauses, "user_id = ?")
+		args = append(args, userID)
+	}
+
+	return r.list(clauses, args, archiveSortKeys, "archive", page)
 }
 
-func (r *TodoRepo) GetByUserID(userID string) ([]*domain.Todo, error) {
-	query := `SELECT id, user_id, title, description, completed, created_at, updated_at
-		FROM todos WHERE user_id = ? ORDER BY created_at DESC`
+// list runs a todo listing one page at a time, returning the cursor of the
+// next page or "" on the last one.
+func (r *TodoRepo) list(clauses []string, args []any, keys []sortKey, scope string, page domain.PageRequest) ([]*domain.Todo, string, error) {
+	after, afterArgs, limit, err := pageQuery(keys, scope, page)
+	if err != nil {
+		return nil, "", err
+	}
+	if after != "" {
+		clauses = append(clauses, after)
+		args = append(args, afterArgs...)
+	}
+
+	query := `SELECT ` + todoColumns + `, ` + keyColumns(keys) + ` FROM todos`
+	if len(clauses) > 0 {
+		query += ` WHERE ` + strings.Join(clauses, " AND ")
+	}
+	query += ` ORDER BY ` + orderBy(keys)
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query todos: %w", err)
+		return nil, "", fmt.Errorf("failed to query todos: %w", err)
 	}
 	defer rows.Close()
 
 	todos := make([]*domain.Todo, 0)
+	var lastKeys []any
+	hasMore := false
 	for rows.Next() {
-		var todo domain.Todo
-		err := rows.Scan(
-			&todo.ID,
-			&todo.UserID,
-			&todo.Title,
-			&todo.Description,
-			&todo.Completed,
-			&todo.CreatedAt,
-			&todo.UpdatedAt,
-		)
+		values := make([]any, len(keys))
+		dest := make([]any, len(keys))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		todo, err := scanTodo(scanWith{rows, dest})
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan todo: %w", err)
+			return nil, "", fmt.Errorf("failed to scan todo: %w", err)
+		}
+
+		if limit > 0 && len(todos) == page.Limit {
+			// The extra row only tells us another page exists
+			hasMore = true
+			break
 		}
-		todos = append(todos, &todo)
+		todos = append(todos, todo)
+		lastKeys = values
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating todos: %w", err)
+		return nil, "", fmt.Errorf("error iterating todos: %w", err)
 	}
 
-	return todos, nil
+	var next string
+	if hasMore {
+		next = encodeCursor(scope, lastKeys)
+	}
+
+	if err := r.attachTags(todos); err != nil {
+		return nil, "", err
+	}
+
+	return todos, next, nil
 }
 
-func (r *TodoRepo) GetAll() ([]*domain.Todo, error) {
-	query := `SELECT id, user_id, title, description, completed, created_at, updated_at
-			  FROM todos ORDER BY created_at DESC`
+// attachTags loads the tag names for a batch of todos in a single query.
+func (r *TodoRepo) attachTags(todos []*domain.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*domain.Todo, len(todos))
+	args := make([]any, 0, len(todos))
+	for _, todo := range todos {
+		todo.Tags = []string{}
+		byID[todo.ID] = todo
+		args = append(args, todo.ID)
+	}
+
+	query := `SELECT tt.todo_id, t.name FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.todo_id IN (` + placeholders(len(todos)) + `) ORDER BY t.name`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query todos: %w", err)
+		return fmt.Errorf("failed to query todo tags: %w", err)
 	}
 	defer rows.Close()
 
-	todos := make([]*domain.Todo, 0)
 	for rows.Next() {
-		var todo domain.Todo
-		err := rows.Scan(
-			&todo.ID,
-			&todo.UserID,
-			&todo.Title,
-			&todo.Description,
-			&todo.Completed,
-			&todo.CreatedAt,
-			&todo.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan todo: %w", err)
+		var todoID, name string
+		if err := rows.Scan(&todoID, &name); err != nil {
+			return fmt.Errorf("failed to scan todo tag: %w", err)
 		}
-		todos = append(todos, &todo)
+		byID[todoID].Tags = append(byID[todoID].Tags, name)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating todos: %w", err)
+		return fmt.Errorf("error iterating todo tags: %w", err)
 	}
 
-	return todos, nil
+	return nil
 }
 
 func (r *TodoRepo) Update(todo *domain.Todo) error {
-	query := `UPDATE todos SET title = ?, description = ?, completed = ?, updated_at = ?
-			  WHERE id = ?`
+	query := `UPDATE todos SET project_id = ?, title = ?, description = ?, completed = ?, priority = ?,
+			  due_date = ?, due_time = ?, due_timezone = ?, due_at = ?, recurrence = ?, updated_at = ?, completed_at = ?, archived_at = ?,
+			  assignee_id = ?
+			  WHERE id = ? AND deleted_at IS NULL`
+
+	dueDate, dueTime, dueTimezone, dueAt := dueColumns(todo.Due)
 
-	result, err := r.db.Exec(query, todo.Title, todo.Description, todo.Completed, todo.UpdatedAt, todo.ID)
+	result, err := r.db.Exec(query, projectColumn(todo.ProjectID), todo.Title, todo.Description, todo.Completed, todo.Priority,
+		dueDate, dueTime, dueTimezone, dueAt, recurrenceColumn(todo.Recurrence), todo.UpdatedAt.UTC(),
+		timeColumn(todo.CompletedAt), timeColumn(todo.ArchivedAt), assigneeColumn(todo.AssigneeID), todo.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update todo: %w", err)
 	}
@@ -143,7 +505,135 @@ func (r *TodoRepo) Update(todo *domain.Todo) error {
 	return nil
 }
 
+// SetPosition moves a todo to a new rank without touching updated_at.
+func (r *TodoRepo) SetPosition(id, position string) error {
+	result, err := r.db.Exec(`UPDATE todos SET position = ? WHERE id = ? AND deleted_at IS NULL`, position, id)
+	if err != nil {
+		return fmt.Errorf("failed to move todo: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrTodoNotFound
+	}
+
+	return nil
+}
+
+// AdjacentPosition returns the rank of the user's todo directly above (or
+// below) position, or "" when position is at that end of the list.
+func (r *TodoRepo) AdjacentPosition(userID, position string, above bool) (string, error) {
+	query := `SELECT MIN(position) FROM todos WHERE user_id = ? AND deleted_at IS NULL AND position > ?`
+	if above {
+		query = `SELECT MAX(position) FROM todos WHERE user_id = ? AND deleted_at IS NULL AND position < ?`
+	}
+
+	var adjacent sql.NullString
+	if err := r.db.QueryRow(query, userID, position).Scan(&adjacent); err != nil {
+		return "", fmt.Errorf("failed to get adjacent position: %w", err)
+	}
+
+	return adjacent.String, nil
+}
+
+// Delete moves a todo to the trash. Purge removes it for good later.
 func (r *TodoRepo) Delete(id string) error {
+	query := `UPDATE todos SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete todo: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrTodoNotFound
+	}
+
+	return nil
+}
+
+// Restore takes a todo back out of the trash.
+func (r *TodoRepo) Restore(id string) error {
+	query := `UPDATE todos SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore todo: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrTodoNotFound
+	}
+
+	return nil
+}
+
+// Unarchive brings an archived todo back into the todo list. It counts as an
+// update, so auto-archiving waits another full period before archiving the
+// todo again.
+func (r *TodoRepo) Unarchive(id string) error {
+	query := `UPDATE todos SET archived_at = NULL, updated_at = ?
+		WHERE id = ? AND archived_at IS NOT NULL AND deleted_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to unarchive todo: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrTodoNotFound
+	}
+
+	return nil
+}
+
+// ArchiveCompleted archives the completed todos of every user with
+// auto-archiving turned on once their delay has passed since the todo was
+// completed and last updated. It reports how many todos were archived.
+func (r *TodoRepo) ArchiveCompleted(now time.Time) (int64, error) {
+	query := `UPDATE todos SET archived_at = ?
+		WHERE completed = 1 AND completed_at IS NOT NULL AND archived_at IS NULL AND deleted_at IS NULL
+		AND EXISTS (
+			SELECT 1 FROM users u WHERE u.id = todos.user_id AND u.auto_archive_days > 0
+			AND julianday(todos.completed_at) <= julianday(?) - u.auto_archive_days
+			AND julianday(todos.updated_at) <= julianday(?) - u.auto_archive_days
+		)`
+
+	now = now.UTC()
+	result, err := r.db.Exec(query, now, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive todos: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+// HardDelete permanently removes a todo, whether or not it is in the trash.
+func (r *TodoRepo) HardDelete(id string) error {
 	query := `DELETE FROM todos WHERE id = ?`
 
 	result, err := r.db.Exec(query, id)
@@ -162,3 +652,123 @@ func (r *TodoRepo) Delete(id string) error {
 
 	return nil
 }
+
+// Purge permanently removes the todos deleted before deletedBefore, along
+// with their subtasks and tag links, and reports how many were removed.
+func (r *TodoRepo) Purge(deletedBefore time.Time) (int64, error) {
+	query := `DELETE FROM todos WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+
+	result, err := r.db.Exec(query, deletedBefore.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge todos: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+// Highlight markers FTS5 wraps around matches. Control characters can't
+// appear in typed text, so they survive HTML escaping and are then swapped
+// for <mark> tags.
+const (
+	matchStart = "\x02"
+	matchEnd   = "\x03"
+)
+
+// ftsQuery turns free text into an FTS5 query matching every word as a
+// prefix, so results show up while the user is still typing. Punctuation is
+// dropped rather than passed through as FTS5 syntax.
+func ftsQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + word + `"*`
+	}
+	return strings.Join(terms, " ")
+}
+
+// markMatches escapes FTS5 output for HTML and turns the match markers into
+// <mark> tags.
+func markMatches(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, matchStart, "<mark>")
+	return strings.ReplaceAll(s, matchEnd, "</mark>")
+}
+
+// scanWith appends extra destinations to every Scan call, letting scanTodo
+// read rows that carry more columns than todoColumns.
+type scanWith struct {
+	rowScanner
+	extra []any
+}
+
+func (s scanWith) Scan(dest ...any) error {
+	return s.rowScanner.Scan(append(dest, s.extra...)...)
+}
+
+// Search ranks todos against the query with BM25, weighting title matches
+// above description matches. An empty userID searches every user's todos.
+func (r *TodoRepo) Search(userID, query string, limit int) ([]*domain.SearchResult, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return []*domain.SearchResult{}, nil
+	}
+
+	sqlQuery := `SELECT ` + todoColumns + `, m.title_match, m.snippet, m.score
+		FROM todos JOIN (
+			SELECT todo_id,
+				highlight(todos_fts, 1, ?, ?) AS title_match,
+				snippet(todos_fts, 2, ?, ?, '…', 12) AS snippet,
+				bm25(todos_fts, 0, 10.0, 1.0) AS score
+			FROM todos_fts WHERE todos_fts MATCH ?
+		) m ON m.todo_id = todos.id
+		WHERE deleted_at IS NULL`
+	args := []any{matchStart, matchEnd, matchStart, matchEnd, match}
+	if userID != "" {
+		sqlQuery += ` AND user_id = ?`
+		args = append(args, userID)
+	}
+	sqlQuery += ` ORDER BY m.score, created_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search todos: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]*domain.SearchResult, 0)
+	todos := make([]*domain.Todo, 0)
+	for rows.Next() {
+		var result domain.SearchResult
+		todo, err := scanTodo(scanWith{rows, []any{&result.Title, &result.Snippet, &result.Score}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		result.Todo = todo
+		result.Title = markMatches(result.Title)
+		result.Snippet = markMatches(result.Snippet)
+		// bm25 scores are negative, lower meaning better; flip them so
+		// clients can treat a higher score as a better match
+		result.Score = -result.Score
+		results = append(results, &result)
+		todos = append(todos, todo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	if err := r.attachTags(todos); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -1,8 +1,11 @@
 package store
 
 import (
+	"errors"
+	"fmt"
 	"godo/internal/domain"
 	"testing"
+	"time"
 )
 
 func TestTodoRepo_Create_Success(t *testing.T) {
@@ -94,7 +97,7 @@ func TestTodoRepo_GetByUserID_Success(t *testing.T) {
 	todoRepo.Create(todo1)
 	todoRepo.Create(todo2)
 
-	todos, err := todoRepo.GetByUserID(user.ID)
+	todos, _, err := todoRepo.GetByUserID(user.ID, domain.TodoFilter{}, domain.PageRequest{})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -117,7 +120,7 @@ func TestTodoRepo_GetByUserID_Empty(t *testing.T) {
 	}
 	userRepo.Create(user)
 
-	todos, err := todoRepo.GetByUserID(user.ID)
+	todos, _, err := todoRepo.GetByUserID(user.ID, domain.TodoFilter{}, domain.PageRequest{})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -150,7 +153,7 @@ func TestTodoRepo_GetAll_Success(t *testing.T) {
 	todoRepo.Create(domain.NewTodo(user1.ID, "User1 Todo", ""))
 	todoRepo.Create(domain.NewTodo(user2.ID, "User2 Todo", ""))
 
-	todos, err := todoRepo.GetAll()
+	todos, _, err := todoRepo.GetAll(domain.TodoFilter{}, domain.PageRequest{})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -233,6 +236,110 @@ func TestTodoRepo_Delete_Success(t *testing.T) {
 	}
 }
 
+func TestTodoRepo_Delete_MovesToTrash(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleUser,
+	}
+	userRepo.Create(user)
+
+	kept := domain.NewTodo(user.ID, "Kept groceries", "")
+	deleted := domain.NewTodo(user.ID, "Deleted groceries", "")
+	todoRepo.Create(kept)
+	todoRepo.Create(deleted)
+
+	if err := todoRepo.Delete(deleted.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := todoRepo.Delete(deleted.ID); err != domain.ErrTodoNotFound {
+		t.Errorf("expected ErrTodoNotFound deleting twice, got %v", err)
+	}
+
+	todos, _, _ := todoRepo.GetByUserID(user.ID, domain.TodoFilter{}, domain.PageRequest{})
+	if len(todos) != 1 || todos[0].ID != kept.ID {
+		t.Errorf("expected only the kept todo to be listed, got %d todos", len(todos))
+	}
+	results, _ := todoRepo.Search(user.ID, "groceries", 10)
+	if len(results) != 1 {
+		t.Errorf("expected deleted todos to be left out of search, got %d results", len(results))
+	}
+
+	trash, _, err := todoRepo.GetTrash(user.ID, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != deleted.ID || trash[0].DeletedAt == nil {
+		t.Fatalf("expected the deleted todo in the trash, got %d todos", len(trash))
+	}
+
+	if err := todoRepo.Restore(deleted.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	restored, err := todoRepo.GetByID(deleted.ID)
+	if err != nil {
+		t.Fatalf("expected restored todo to load, got %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Errorf("expected DeletedAt to be cleared, got %v", restored.DeletedAt)
+	}
+	if err := todoRepo.Restore(deleted.ID); err != domain.ErrTodoNotFound {
+		t.Errorf("expected ErrTodoNotFound restoring a todo not in the trash, got %v", err)
+	}
+}
+
+func TestTodoRepo_Purge(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+	subtaskRepo := NewSubtaskRepo(db)
+
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleUser,
+	}
+	userRepo.Create(user)
+
+	old := domain.NewTodo(user.ID, "Old", "")
+	recent := domain.NewTodo(user.ID, "Recent", "")
+	open := domain.NewTodo(user.ID, "Open", "")
+	for _, todo := range []*domain.Todo{old, recent, open} {
+		todoRepo.Create(todo)
+	}
+	subtaskRepo.Create(domain.NewSubtask(old.ID, "Step", 0))
+	todoRepo.Delete(old.ID)
+	todoRepo.Delete(recent.ID)
+	db.Exec(`UPDATE todos SET deleted_at = ? WHERE id = ?`, time.Now().Add(-48*time.Hour).UTC(), old.ID)
+
+	purged, err := todoRepo.Purge(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("expected 1 todo purged, got %d", purged)
+	}
+
+	if _, err := todoRepo.GetTrashedByID(old.ID); err != domain.ErrTodoNotFound {
+		t.Errorf("expected the old todo to be gone, got %v", err)
+	}
+	if _, err := todoRepo.GetTrashedByID(recent.ID); err != nil {
+		t.Errorf("expected the recent todo to stay in the trash, got %v", err)
+	}
+	if _, err := todoRepo.GetByID(open.ID); err != nil {
+		t.Errorf("expected the open todo to be untouched, got %v", err)
+	}
+	if subtasks, _ := subtaskRepo.GetByTodoID(old.ID); len(subtasks) != 0 {
+		t.Errorf("expected the purged todo's subtasks to be removed, got %d", len(subtasks))
+	}
+}
+
 func TestTodoRepo_Delete_NotFound(t *testing.T) {
 	db := setupTestDB(t)
 	todoRepo := NewTodoRepo(db)
@@ -242,3 +349,610 @@ func TestTodoRepo_Delete_NotFound(t *testing.T) {
 		t.Errorf("expected ErrTodoNotFound, got %v", err)
 	}
 }
+
+func TestTodoRepo_Create_WithDueDate(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleUser,
+	}
+	userRepo.Create(user)
+
+	due, err := domain.NewDue("2030-01-15", "09:30", "America/New_York")
+	if err != nil {
+		t.Fatalf("failed to build due date: %v", err)
+	}
+
+	todo := domain.NewTodo(user.ID, "Scheduled", "")
+	todo.Due = due
+	if err := todoRepo.Create(todo); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	saved, err := todoRepo.GetByID(todo.ID)
+	if err != nil {
+		t.Fatalf("failed to retrieve todo: %v", err)
+	}
+
+	if saved.Due == nil {
+		t.Fatal("expected due date to be saved")
+	}
+	if saved.Due.Date != "2030-01-15" || saved.Due.Time != "09:30" || saved.Due.Timezone != "America/New_York" {
+		t.Errorf("unexpected due date %+v", saved.Due)
+	}
+	if !saved.Due.At.Equal(due.At) {
+		t.Errorf("expected due instant %v, got %v", due.At, saved.Due.At)
+	}
+}
+
+func TestTodoRepo_GetByUserID_DueFilters(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleUser,
+	}
+	userRepo.Create(user)
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(domain.DueDateLayout)
+	nextWeek := time.Now().UTC().AddDate(0, 0, 7).Format(domain.DueDateLayout)
+
+	overdue := domain.NewTodo(user.ID, "Overdue", "")
+	overdue.Due, _ = domain.NewDue(yesterday, "", "")
+	doneLate := domain.NewTodo(user.ID, "Done late", "")
+	doneLate.Due, _ = domain.NewDue(yesterday, "", "")
+	doneLate.Completed = true
+	upcoming := domain.NewTodo(user.ID, "Upcoming", "")
+	upcoming.Due, _ = domain.NewDue(nextWeek, "", "")
+	undated := domain.NewTodo(user.ID, "Undated", "")
+
+	for _, todo := range []*domain.Todo{overdue, doneLate, upcoming, undated} {
+		if err := todoRepo.Create(todo); err != nil {
+			t.Fatalf("failed to create todo: %v", err)
+		}
+	}
+
+	todos, _, err := todoRepo.GetByUserID(user.ID, domain.TodoFilter{Overdue: true}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(todos) != 1 || todos[0].ID != overdue.ID {
+		t.Errorf("expected only the open overdue todo, got %d todos", len(todos))
+	}
+
+	todos, _, _ = todoRepo.GetByUserID(user.ID, domain.TodoFilter{DueOn: nextWeek}, domain.PageRequest{})
+	if len(todos) != 1 || todos[0].ID != upcoming.ID {
+		t.Errorf("expected only the upcoming todo, got %d todos", len(todos))
+	}
+
+	now := time.Now()
+	todos, _, _ = todoRepo.GetByUserID(user.ID, domain.TodoFilter{DueAfter: &now}, domain.PageRequest{})
+	if len(todos) != 1 || todos[0].ID != upcoming.ID {
+		t.Errorf("expected only the upcoming todo after now, got %d todos", len(todos))
+	}
+
+	todos, _, _ = todoRepo.GetByUserID(user.ID, domain.TodoFilter{DueBefore: &now}, domain.PageRequest{})
+	if len(todos) != 2 {
+		t.Errorf("expected 2 todos due before now, got %d", len(todos))
+	}
+}
+
+func TestTodoRepo_GetByUserID_SortByPriority(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleUser,
+	}
+	userRepo.Create(user)
+
+	low := domain.NewTodo(user.ID, "Low", "")
+	low.Priority = domain.PriorityLow
+	urgentLater := domain.NewTodo(user.ID, "Urgent later", "")
+	urgentLater.Priority = domain.PriorityUrgent
+	urgentLater.Due, _ = domain.NewDue("2030-06-01", "", "")
+	urgentSoon := domain.NewTodo(user.ID, "Urgent soon", "")
+	urgentSoon.Priority = domain.PriorityUrgent
+	urgentSoon.Due, _ = domain.NewDue("2030-01-01", "", "")
+	urgentUndated := domain.NewTodo(user.ID, "Urgent undated", "")
+	urgentUndated.Priority = domain.PriorityUrgent
+
+	for _, todo := range []*domain.Todo{low, urgentLater, urgentSoon, urgentUndated} {
+		if err := todoRepo.Create(todo); err != nil {
+			t.Fatalf("failed to create todo: %v", err)
+		}
+	}
+
+	todos, _, err := todoRepo.GetByUserID(user.ID, domain.TodoFilter{Sort: domain.SortPriority}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []string{urgentSoon.ID, urgentLater.ID, urgentUndated.ID, low.ID}
+	if len(todos) != len(expected) {
+		t.Fatalf("expected %d todos, got %d", len(expected), len(todos))
+	}
+	for i, id := range expected {
+		if todos[i].ID != id {
+			t.Errorf("position %d: expected %q, got %q", i, id, todos[i].Title)
+		}
+	}
+}
+
+func TestTodoRepo_GetAll_Filters(t *testing.T) {
+	inEachZone(t, func(t *testing.T) {
+		db := setupTestDB(t)
+		userRepo := NewUserRepo(db)
+		todoRepo := NewTodoRepo(db)
+
+		alice := &domain.User{ID: domain.NewID(), Email: "alice@example.com", PasswordHash: "hash", Role: domain.RoleUser}
+		bob := &domain.User{ID: domain.NewID(), Email: "bob@example.com", PasswordHash: "hash", Role: domain.RoleUser}
+		userRepo.Create(alice)
+		userRepo.Create(bob)
+
+		old := domain.NewTodo(alice.ID, "Pay 100% of rent", "")
+		old.CreatedAt = time.Now().Add(-48 * time.Hour)
+		old.UpdatedAt = old.CreatedAt
+		old.Completed = true
+		recent := domain.NewTodo(alice.ID, "Pay 100 dollars", "")
+		other := domain.NewTodo(bob.ID, "Walk the dog", "")
+		for _, todo := range []*domain.Todo{old, recent, other} {
+			if err := todoRepo.Create(todo); err != nil {
+				t.Fatalf("failed to create todo: %v", err)
+			}
+		}
+
+		yesterday := time.Now().Add(-24 * time.Hour)
+		anHourAgo := time.Now().Add(-time.Hour)
+		done, open := true, false
+		tests := []struct {
+			name     string
+			filter   domain.TodoFilter
+			expected []string
+		}{
+			{"user", domain.TodoFilter{UserID: bob.ID}, []string{other.ID}},
+			{"completed", domain.TodoFilter{Completed: &done}, []string{old.ID}},
+			{"open", domain.TodoFilter{Completed: &open, UserID: alice.ID}, []string{recent.ID}},
+			{"title ignores case", domain.TodoFilter{TitleContains: "PAY"}, []string{recent.ID, old.ID}},
+			{"title wildcard is literal", domain.TodoFilter{TitleContains: "100%"}, []string{old.ID}},
+			{"created before", domain.TodoFilter{CreatedBefore: &yesterday}, []string{old.ID}},
+			{"updated after", domain.TodoFilter{UpdatedAfter: &yesterday, UserID: alice.ID}, []string{recent.ID}},
+			{"created after", domain.TodoFilter{CreatedAfter: &anHourAgo, UserID: alice.ID}, []string{recent.ID}},
+			{"updated before", domain.TodoFilter{UpdatedBefore: &anHourAgo}, []string{old.ID}},
+		}
+
+		for _, tt := range tests {
+			todos, _, err := todoRepo.GetAll(tt.filter, domain.PageRequest{})
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", tt.name, err)
+			}
+			if len(todos) != len(tt.expected) {
+				t.Errorf("%s: expected %d todos, got %d", tt.name, len(tt.expected), len(todos))
+				continue
+			}
+			for i, id := range tt.expected {
+				if todos[i].ID != id {
+					t.Errorf("%s: position %d: expected %s, got %q", tt.name, i, id, todos[i].Title)
+				}
+			}
+		}
+	})
+}
+
+func TestTodoRepo_GetByUserID_SortFields(t *testing.T) {
+	inEachZone(t, func(t *testing.T) {
+		db := setupTestDB(t)
+		userRepo := NewUserRepo(db)
+		todoRepo := NewTodoRepo(db)
+
+		user := &domain.User{
+			ID:           domain.NewID(),
+			Email:        "test@example.com",
+			PasswordHash: "hash",
+			Role:         domain.RoleUser,
+		}
+		userRepo.Create(user)
+
+		now := time.Now()
+		banana := domain.NewTodo(user.ID, "banana", "")
+		banana.UpdatedAt = now.Add(-time.Hour)
+		apple := domain.NewTodo(user.ID, "Apple", "")
+		apple.UpdatedAt = now
+		cherry := domain.NewTodo(user.ID, "cherry", "")
+		cherry.UpdatedAt = now.Add(-time.Hour)
+		cherry.Due, _ = domain.NewDue("2030-01-01", "", "")
+		for _, todo := range []*domain.Todo{banana, apple, cherry} {
+			if err := todoRepo.Create(todo); err != nil {
+				t.Fatalf("failed to create todo: %v", err)
+			}
+		}
+
+		tests := []struct {
+			sort     string
+			expected []string
+		}{
+			{"-updated_at,title", []string{apple.ID, banana.ID, cherry.ID}},
+			{"-title", []string{cherry.ID, banana.ID, apple.ID}},
+			{"-due_at,title", []string{cherry.ID, apple.ID, banana.ID}},
+		}
+
+		for _, tt := range tests {
+			fields, err := domain.ParseTodoSortFields(tt.sort)
+			if err != nil {
+				t.Fatalf("%s: failed to parse sort: %v", tt.sort, err)
+			}
+			filter := domain.TodoFilter{SortFields: fields}
+
+			// Page one todo at a time to exercise the cursor for each ordering
+			var ids []string
+			page := domain.PageRequest{Limit: 1}
+			for {
+				todos, next, err := todoRepo.GetByUserID(user.ID, filter, page)
+				if err != nil {
+					t.Fatalf("%s: expected no error, got %v", tt.sort, err)
+				}
+				for _, todo := range todos {
+					ids = append(ids, todo.ID)
+				}
+				if next == "" {
+					break
+				}
+				if len(ids) > len(tt.expected) {
+					t.Fatalf("%s: paging never ends", tt.sort)
+				}
+				page.Cursor = next
+			}
+
+			if len(ids) != len(tt.expected) {
+				t.Fatalf("%s: expected %d todos, got %d", tt.sort, len(tt.expected), len(ids))
+			}
+			for i, id := range tt.expected {
+				if ids[i] != id {
+					t.Errorf("%s: position %d: expected %s, got %s", tt.sort, i, id, ids[i])
+				}
+			}
+		}
+	})
+}
+
+func TestTodoRepo_GetByUserID_Paginates(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleUser,
+	}
+	userRepo.Create(user)
+
+	// Shared creation times and priorities make the id tiebreaker matter
+	createdAt := time.Now()
+	for i := 0; i < 7; i++ {
+		todo := domain.NewTodo(user.ID, "Todo", "")
+		todo.CreatedAt = createdAt.Add(time.Duration(i/2) * time.Second)
+		todo.Priority = domain.Priority(i % 3)
+		if i%2 == 0 {
+			todo.Due, _ = domain.NewDue(fmt.Sprintf("2030-01-%02d", i%3+1), "", "")
+		}
+		if err := todoRepo.Create(todo); err != nil {
+			t.Fatalf("failed to create todo: %v", err)
+		}
+	}
+
+	for _, sort := range []domain.TodoSort{domain.SortManual, domain.SortCreated, domain.SortPriority, domain.SortDue} {
+		filter := domain.TodoFilter{Sort: sort}
+		all, _, err := todoRepo.GetByUserID(user.ID, filter, domain.PageRequest{})
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", sort, err)
+		}
+
+		var paged []*domain.Todo
+		page := domain.PageRequest{Limit: 2}
+		for {
+			todos, next, err := todoRepo.GetByUserID(user.ID, filter, page)
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", sort, err)
+			}
+			paged = append(paged, todos...)
+			if next == "" {
+				break
+			}
+			page.Cursor = next
+		}
+
+		if len(paged) != len(all) {
+			t.Fatalf("%s: expected %d todos across pages, got %d", sort, len(all), len(paged))
+		}
+		for i := range all {
+			if paged[i].ID != all[i].ID {
+				t.Errorf("%s: position %d: expected %s, got %s", sort, i, all[i].ID, paged[i].ID)
+			}
+		}
+	}
+}
+
+func TestTodoRepo_GetByUserID_LastPageHasNoCursor(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleUser,
+	}
+	userRepo.Create(user)
+	todoRepo.Create(domain.NewTodo(user.ID, "First", ""))
+	todoRepo.Create(domain.NewTodo(user.ID, "Second", ""))
+
+	todos, next, err := todoRepo.GetByUserID(user.ID, domain.TodoFilter{}, domain.PageRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(todos) != 2 {
+		t.Errorf("expected 2 todos, got %d", len(todos))
+	}
+	if next != "" {
+		t.Errorf("expected no next cursor on the last page, got %q", next)
+	}
+}
+
+func TestTodoRepo_GetByUserID_InvalidCursor(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleUser,
+	}
+	userRepo.Create(user)
+	for i := 0; i < 3; i++ {
+		todoRepo.Create(domain.NewTodo(user.ID, "Todo", ""))
+	}
+
+	_, next, err := todoRepo.GetByUserID(user.ID, domain.TodoFilter{Sort: domain.SortCreated}, domain.PageRequest{Limit: 1})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, _, err = todoRepo.GetByUserID(user.ID, domain.TodoFilter{}, domain.PageRequest{Limit: 1, Cursor: "not-a-cursor"})
+	if !errors.Is(err, domain.ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for garbage, got %v", err)
+	}
+
+	// A cursor only resumes the ordering it was issued for
+	_, _, err = todoRepo.GetByUserID(user.ID, domain.TodoFilter{Sort: domain.SortPriority}, domain.PageRequest{Limit: 1, Cursor: next})
+	if !errors.Is(err, domain.ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for another sort, got %v", err)
+	}
+}
+
+func TestTodoRepo_Recurrence_RoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+
+	user := &domain.User{
+		ID:           domain.NewID(),
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+		Role:         domain.RoleUser,
+	}
+	userRepo.Create(user)
+
+	recurrence, err := domain.ParseRecurrence("RRULE:freq=weekly;byday=th,mo;interval=2")
+	if err != nil {
+		t.Fatalf("failed to parse recurrence: %v", err)
+	}
+
+	todo := domain.NewTodo(user.ID, "Chores", "")
+	todo.Recurrence = recurrence
+	if err := todoRepo.Create(todo); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	saved, _ := todoRepo.GetByID(todo.ID)
+	if saved.Recurrence == nil || saved.Recurrence.String() != "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH" {
+		t.Fatalf("unexpected recurrence %v", saved.Recurrence)
+	}
+
+	saved.Recurrence = nil
+	if err := todoRepo.Update(saved); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cleared, _ := todoRepo.GetByID(todo.ID)
+	if cleared.Recurrence != nil {
+		t.Errorf("expected recurrence to be cleared, got %v", cleared.Recurrence)
+	}
+}
+
+func TestTodoRepo_Search(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+
+	user := createTagTestUser(t, userRepo)
+	other := createTagTestUser(t, userRepo)
+
+	inTitle := domain.NewTodo(user.ID, "Renew passport", "")
+	inDescription := domain.NewTodo(user.ID, "Travel prep", "Check the passport expiry <soon>")
+	unrelated := domain.NewTodo(user.ID, "Groceries", "milk")
+	foreign := domain.NewTodo(other.ID, "Passport photos", "")
+	for _, todo := range []*domain.Todo{inTitle, inDescription, unrelated, foreign} {
+		if err := todoRepo.Create(todo); err != nil {
+			t.Fatalf("failed to create todo: %v", err)
+		}
+	}
+
+	results, err := todoRepo.Search(user.ID, "pass", 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Todo.ID != inTitle.ID {
+		t.Errorf("expected the title match to rank first, got %q", results[0].Todo.Title)
+	}
+	if results[0].Title != "Renew <mark>passport</mark>" {
+		t.Errorf("unexpected title highlight %q", results[0].Title)
+	}
+	if results[1].Snippet != "Check the <mark>passport</mark> expiry &lt;soon&gt;" {
+		t.Errorf("unexpected snippet %q", results[1].Snippet)
+	}
+
+	all, _ := todoRepo.Search("", "passport", 10)
+	if len(all) != 3 {
+		t.Errorf("expected 3 results across users, got %d", len(all))
+	}
+
+	if empty, _ := todoRepo.Search(user.ID, `"*()`, 10); len(empty) != 0 {
+		t.Errorf("expected punctuation to match nothing, got %d results", len(empty))
+	}
+}
+
+func TestTodoRepo_Search_FollowsChanges(t *testing.T) {
+	db := setupTestDB(t)
+	todoRepo := NewTodoRepo(db)
+	user := createTagTestUser(t, NewUserRepo(db))
+
+	todo := domain.NewTodo(user.ID, "Call plumber", "")
+	todoRepo.Create(todo)
+
+	todo.Title = "Call electrician"
+	if err := todoRepo.Update(todo); err != nil {
+		t.Fatalf("failed to update todo: %v", err)
+	}
+
+	if results, _ := todoRepo.Search(user.ID, "plumber", 10); len(results) != 0 {
+		t.Errorf("expected the old title to be gone from the index")
+	}
+	if results, _ := todoRepo.Search(user.ID, "electrician", 10); len(results) != 1 {
+		t.Errorf("expected the new title to be indexed")
+	}
+
+	todoRepo.Delete(todo.ID)
+	if results, _ := todoRepo.Search(user.ID, "electrician", 10); len(results) != 0 {
+		t.Errorf("expected deleted todos to leave the index")
+	}
+}
+
+func TestTodoRepo_ArchiveCompleted(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+
+	archiving := &domain.User{ID: domain.NewID(), Email: "archiving@example.com", PasswordHash: "hash", Role: domain.RoleUser, AutoArchiveDays: 7}
+	keeping := &domain.User{ID: domain.NewID(), Email: "keeping@example.com", PasswordHash: "hash", Role: domain.RoleUser}
+	userRepo.Create(archiving)
+	userRepo.Create(keeping)
+
+	completedAt := time.Now().Add(-10 * 24 * time.Hour)
+	newTodo := func(userID, title string, completed bool) *domain.Todo {
+		todo := domain.NewTodo(userID, title, "")
+		todo.Completed = completed
+		if completed {
+			todo.CompletedAt = &completedAt
+		}
+		todo.UpdatedAt = completedAt
+		todoRepo.Create(todo)
+		return todo
+	}
+	old := newTodo(archiving.ID, "Old", true)
+	open := newTodo(archiving.ID, "Open", false)
+	other := newTodo(keeping.ID, "Other", true)
+
+	archived, err := todoRepo.ArchiveCompleted(time.Now())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if archived != 1 {
+		t.Errorf("expected 1 todo archived, got %d", archived)
+	}
+
+	todos, _, _ := todoRepo.GetByUserID(archiving.ID, domain.TodoFilter{}, domain.PageRequest{})
+	if len(todos) != 1 || todos[0].ID != open.ID {
+		t.Errorf("expected only the open todo to be listed, got %d todos", len(todos))
+	}
+	todos, _, _ = todoRepo.GetByUserID(archiving.ID, domain.TodoFilter{IncludeArchived: true}, domain.PageRequest{})
+	if len(todos) != 2 {
+		t.Errorf("expected archived todos to be listed on request, got %d todos", len(todos))
+	}
+	if todo, _ := todoRepo.GetByID(other.ID); todo.ArchivedAt != nil {
+		t.Error("expected todos of users without auto-archiving to be left alone")
+	}
+
+	archive, _, err := todoRepo.GetArchive(archiving.ID, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(archive) != 1 || archive[0].ID != old.ID || archive[0].ArchivedAt == nil {
+		t.Fatalf("expected the old todo in the archive, got %d todos", len(archive))
+	}
+
+	if err := todoRepo.Unarchive(old.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := todoRepo.Unarchive(old.ID); err != domain.ErrTodoNotFound {
+		t.Errorf("expected ErrTodoNotFound unarchiving twice, got %v", err)
+	}
+
+	// Unarchiving counts as an update, so the todo isn't archived straight away again
+	if archived, _ := todoRepo.ArchiveCompleted(time.Now()); archived != 0 {
+		t.Errorf("expected the unarchived todo to stay out of the archive, got %d archived", archived)
+	}
+}
+
+func TestTodoRepo_Assignee(t *testing.T) {
+	db := setupTestDB(t)
+	userRepo := NewUserRepo(db)
+	todoRepo := NewTodoRepo(db)
+	todo := createSubtaskTestTodo(t, todoRepo, userRepo)
+	assignee := createTagTestUser(t, userRepo)
+
+	todo.AssigneeID = assignee.ID
+	if err := todoRepo.Update(todo); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	todos, _, err := todoRepo.GetAll(domain.TodoFilter{AssigneeID: assignee.ID}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(todos) != 1 || todos[0].AssigneeEmail != assignee.Email {
+		t.Fatalf("expected the todo assigned to %s, got %d todos", assignee.Email, len(todos))
+	}
+
+	// Deleting the assignee leaves the todo unassigned
+	if err := userRepo.Delete(assignee.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	got, err := todoRepo.GetByID(todo.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.AssigneeID != "" || got.AssigneeEmail != "" {
+		t.Errorf("expected no assignee, got %q", got.AssigneeID)
+	}
+}
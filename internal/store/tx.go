@@ -0,0 +1,70 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"godo/internal/domain"
+)
+
+// dbtx is what repositories need from a database handle, so the same queries
+// run on their own or inside a transaction.
+type dbtx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// inTx runs fn in a new transaction, or directly when db already is one so
+// that repository methods join a transaction their caller opened.
+func inTx(db dbtx, fn func(tx dbtx) error) error {
+	conn, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Transactor runs work against repositories that share a single
+// transaction.
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// InTx commits the transaction when fn succeeds and rolls it back otherwise.
+func (t *Transactor) InTx(fn func(repos domain.Repos) error) error {
+	return inTx(t.db, func(tx dbtx) error {
+		return fn(domain.Repos{
+			Todos:          &TodoRepo{db: tx},
+			Tags:           &TagRepo{db: tx},
+			Subtasks:       &SubtaskRepo{db: tx},
+			Projects:       &ProjectRepo{db: tx},
+			Shares:         &ShareRepo{db: tx},
+			Users:          &UserRepo{db: tx},
+			Dependencies:   &DependencyRepo{db: tx},
+			Audit:          &AuditRepo{db: tx},
+			Revisions:      &RevisionRepo{db: tx},
+			Comments:       &CommentRepo{db: tx},
+			Attachments:    &AttachmentRepo{db: tx},
+			PasswordResets: &PasswordResetRepo{db: tx},
+		})
+	})
+}
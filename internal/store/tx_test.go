@@ -0,0 +1,33 @@
+package store
+
+import (
+	"errors"
+	"godo/internal/domain"
+	"testing"
+)
+
+func TestTransactor_RollsBackOnError(t *testing.T) {
+	db := setupTestDB(t)
+	todoRepo := NewTodoRepo(db)
+	todo := createSubtaskTestTodo(t, todoRepo, NewUserRepo(db))
+
+	failure := errors.New("boom")
+	err := NewTransactor(db).InTx(func(repos domain.Repos) error {
+		todo.Title = "Renamed"
+		if err := repos.Todos.Update(todo); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the callback's error, got %v", err)
+	}
+
+	got, err := todoRepo.GetByID(todo.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.Title == "Renamed" {
+		t.Error("expected the update to be rolled back")
+	}
+}
@@ -9,7 +9,7 @@ import (
 // UserRepo Note to self: this implements the UserRepository interface by having all of the required methods.
 // Go does not have an explicit "implements" keyword.
 type UserRepo struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewUserRepo(db *sql.DB) *UserRepo {
@@ -17,10 +17,10 @@ func NewUserRepo(db *sql.DB) *UserRepo {
 }
 
 func (r *UserRepo) Create(user *domain.User) error {
-	query := `INSERT INTO users (id, email, password_hash, role, created_at)
-		VALUES (?, ?, ?, ?, ?)`
+	query := `INSERT INTO users (id, email, password_hash, role, created_at, auto_archive_days)
+		VALUES (?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.Exec(query, user.ID, user.Email, user.PasswordHash, user.Role, user.CreatedAt)
+	_, err := r.db.Exec(query, user.ID, user.Email, user.PasswordHash, user.Role, user.CreatedAt.UTC(), user.AutoArchiveDays)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -29,7 +29,7 @@ func (r *UserRepo) Create(user *domain.User) error {
 }
 
 func (r *UserRepo) GetByEmail(email string) (*domain.User, error) {
-	query := `SELECT id, email, password_hash, role, created_at
+	query := `SELECT id, email, password_hash, role, created_at, auto_archive_days, token_version
 		FROM users WHERE email = ?`
 
 	var user domain.User
@@ -39,6 +39,8 @@ func (r *UserRepo) GetByEmail(email string) (*domain.User, error) {
 		&user.PasswordHash,
 		&user.Role,
 		&user.CreatedAt,
+		&user.AutoArchiveDays,
+		&user.TokenVersion,
 	)
 
 	if err == sql.ErrNoRows {
@@ -53,7 +55,7 @@ func (r *UserRepo) GetByEmail(email string) (*domain.User, error) {
 }
 
 func (r *UserRepo) GetByID(id string) (*domain.User, error) {
-	query := `SELECT id, email, password_hash, role, created_at
+	query := `SELECT id, email, password_hash, role, created_at, auto_archive_days, token_version
 			  FROM users WHERE id = ?`
 
 	var user domain.User
@@ -63,6 +65,8 @@ func (r *UserRepo) GetByID(id string) (*domain.User, error) {
 		&user.PasswordHash,
 		&user.Role,
 		&user.CreatedAt,
+		&user.AutoArchiveDays,
+		&user.TokenVersion,
 	)
 
 	if err == sql.ErrNoRows {
@@ -76,17 +80,35 @@ func (r *UserRepo) GetByID(id string) (*domain.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepo) GetAll() ([]*domain.User, error) {
-	query := `SELECT id, email, role, created_at
-		FROM users ORDER BY created_at DESC`
+// userSortKeys orders users newest first.
+var userSortKeys = []sortKey{{expr: "created_at", desc: true}, {expr: "id", desc: true}}
 
-	rows, err := r.db.Query(query)
+// GetAll lists users one page at a time, returning the cursor of the next
+// page or "" on the last one.
+func (r *UserRepo) GetAll(page domain.PageRequest) ([]*domain.User, string, error) {
+	after, args, limit, err := pageQuery(userSortKeys, "users", page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query users: %w", err)
+		return nil, "", err
+	}
+
+	query := `SELECT id, email, role, created_at, auto_archive_days FROM users`
+	if after != "" {
+		query += ` WHERE ` + after
+	}
+	query += ` ORDER BY ` + orderBy(userSortKeys)
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query users: %w", err)
 	}
 	defer rows.Close()
 
 	users := make([]*domain.User, 0)
+	hasMore := false
 	for rows.Next() {
 		var user domain.User
 		err := rows.Scan(
@@ -94,24 +116,35 @@ func (r *UserRepo) GetAll() ([]*domain.User, error) {
 			&user.Email,
 			&user.Role,
 			&user.CreatedAt,
+			&user.AutoArchiveDays,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan users: %w", err)
+			return nil, "", fmt.Errorf("failed to scan users: %w", err)
+		}
+		if limit > 0 && len(users) == page.Limit {
+			hasMore = true
+			break
 		}
 		users = append(users, &user)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating users: %w", err)
+		return nil, "", fmt.Errorf("error iterating users: %w", err)
+	}
+
+	var next string
+	if hasMore {
+		last := users[len(users)-1]
+		next = encodeCursor("users", []any{last.CreatedAt, last.ID})
 	}
 
-	return users, nil
+	return users, next, nil
 }
 
 func (r *UserRepo) Update(user *domain.User) error {
-	query := `UPDATE users SET email = ?, password_hash = ?, role = ? WHERE id = ?`
+	query := `UPDATE users SET email = ?, password_hash = ?, role = ?, auto_archive_days = ? WHERE id = ?`
 
-	result, err := r.db.Exec(query, user.Email, user.PasswordHash, user.Role, user.ID)
+	result, err := r.db.Exec(query, user.Email, user.PasswordHash, user.Role, user.AutoArchiveDays, user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -161,3 +194,21 @@ func (r *UserRepo) CountByRole(role string) (int, error) {
 
 	return userCount, nil
 }
+
+func (r *UserRepo) BumpTokenVersion(id string) error {
+	result, err := r.db.Exec(`UPDATE users SET token_version = token_version + 1 WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to bump token version: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
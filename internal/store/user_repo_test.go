@@ -1,8 +1,10 @@
 package store
 
 import (
+	"fmt"
 	"godo/internal/domain"
 	"testing"
+	"time"
 )
 
 func TestUserRepo_Create_Success(t *testing.T) {
@@ -144,6 +146,7 @@ func TestUserRepo_Update_Success(t *testing.T) {
 	user.Email = updatedEmail
 	user.PasswordHash = updatedPasswordHash
 	user.Role = updatedRole
+	user.AutoArchiveDays = 30
 
 	err := userRepo.Update(user)
 	if err != nil {
@@ -166,6 +169,10 @@ func TestUserRepo_Update_Success(t *testing.T) {
 	if updated.Role != updatedRole {
 		t.Errorf("expected role %q, got %q", updatedRole, updated.Role)
 	}
+
+	if updated.AutoArchiveDays != 30 {
+		t.Errorf("expected auto-archive after 30 days, got %d", updated.AutoArchiveDays)
+	}
 }
 
 func TestUserRepo_Update_NotFound(t *testing.T) {
@@ -232,7 +239,7 @@ func TestUserRepo_GetAll_Success(t *testing.T) {
 	userRepo.Create(user1)
 	userRepo.Create(user2)
 
-	users, err := userRepo.GetAll()
+	users, _, err := userRepo.GetAll(domain.PageRequest{})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -245,7 +252,7 @@ func TestUserRepo_GetAll_Success(t *testing.T) {
 func TestUserRepo_GetAll_Empty(t *testing.T) {
 	db := setupTestDB(t)
 	userRepo := NewUserRepo(db)
-	users, err := userRepo.GetAll()
+	users, _, err := userRepo.GetAll(domain.PageRequest{})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -254,6 +261,58 @@ func TestUserRepo_GetAll_Empty(t *testing.T) {
 	}
 }
 
+func TestUserRepo_GetAll_Paginates(t *testing.T) {
+	inEachZone(t, func(t *testing.T) {
+		db := setupTestDB(t)
+		userRepo := NewUserRepo(db)
+
+		createdAt := time.Now()
+		for i := 0; i < 5; i++ {
+			user := &domain.User{
+				ID:           domain.NewID(),
+				Email:        fmt.Sprintf("user%d@example.com", i),
+				PasswordHash: "hash",
+				Role:         domain.RoleUser,
+				CreatedAt:    createdAt.Add(time.Duration(i/2) * time.Second),
+			}
+			if err := userRepo.Create(user); err != nil {
+				t.Fatalf("failed to create user: %v", err)
+			}
+		}
+
+		seen := make(map[string]bool)
+		page := domain.PageRequest{Limit: 2}
+		pages := 0
+		for {
+			users, next, err := userRepo.GetAll(page)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			pages++
+			for _, user := range users {
+				if seen[user.ID] {
+					t.Errorf("user %s returned twice", user.Email)
+				}
+				seen[user.ID] = true
+			}
+			if next == "" {
+				break
+			}
+			if pages > 5 {
+				t.Fatal("paging never ends")
+			}
+			page.Cursor = next
+		}
+
+		if len(seen) != 5 {
+			t.Errorf("expected 5 users across pages, got %d", len(seen))
+		}
+		if pages != 3 {
+			t.Errorf("expected 3 pages, got %d", pages)
+		}
+	})
+}
+
 func TestUserRepo_CountByRole_Empty(t *testing.T) {
 	db := setupTestDB(t)
 	userRepo := NewUserRepo(db)
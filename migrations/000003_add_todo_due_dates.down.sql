@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_todos_due_at;
+ALTER TABLE todos DROP COLUMN due_at;
+ALTER TABLE todos DROP COLUMN due_timezone;
+ALTER TABLE todos DROP COLUMN due_time;
+ALTER TABLE todos DROP COLUMN due_date;
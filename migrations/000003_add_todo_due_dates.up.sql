@@ -0,0 +1,6 @@
+ALTER TABLE todos ADD COLUMN due_date TEXT;
+ALTER TABLE todos ADD COLUMN due_time TEXT;
+ALTER TABLE todos ADD COLUMN due_timezone TEXT;
+ALTER TABLE todos ADD COLUMN due_at DATETIME;
+
+CREATE INDEX idx_todos_due_at ON todos(due_at);
@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN priority INTEGER NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 4);
+
+CREATE INDEX idx_todos_priority ON todos(priority);
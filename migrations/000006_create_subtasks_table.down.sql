@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_subtasks_todo_id;
+DROP TABLE IF EXISTS subtasks;
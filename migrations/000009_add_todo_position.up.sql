@@ -0,0 +1,11 @@
+ALTER TABLE todos ADD COLUMN position TEXT NOT NULL DEFAULT '';
+
+-- Rank existing todos newest first, matching how new todos are ranked. The
+-- id suffix keeps todos created in the same millisecond apart.
+UPDATE todos SET position = rtrim(
+    printf('%019d', 9223372036854775807 - CAST((julianday(created_at) - 2440587.5) * 86400000000000 AS INTEGER))
+        || replace(id, '-', ''),
+    '0'
+);
+
+CREATE INDEX idx_todos_position ON todos(user_id, position);
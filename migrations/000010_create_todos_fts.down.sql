@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS todos_fts_delete;
+DROP TRIGGER IF EXISTS todos_fts_update;
+DROP TRIGGER IF EXISTS todos_fts_insert;
+DROP TABLE IF EXISTS todos_fts;
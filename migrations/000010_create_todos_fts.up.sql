@@ -0,0 +1,24 @@
+-- Search index over todo titles and descriptions. It keeps its own copy of
+-- the text keyed by todo_id: todos has no INTEGER PRIMARY KEY, so its rowids
+-- may change on VACUUM and can't be used to link the two tables.
+CREATE VIRTUAL TABLE todos_fts USING fts5(
+    todo_id UNINDEXED,
+    title,
+    description,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO todos_fts (todo_id, title, description)
+SELECT id, title, description FROM todos;
+
+CREATE TRIGGER todos_fts_insert AFTER INSERT ON todos BEGIN
+    INSERT INTO todos_fts (todo_id, title, description) VALUES (new.id, new.title, new.description);
+END;
+
+CREATE TRIGGER todos_fts_update AFTER UPDATE OF title, description ON todos BEGIN
+    UPDATE todos_fts SET title = new.title, description = new.description WHERE todo_id = old.id;
+END;
+
+CREATE TRIGGER todos_fts_delete AFTER DELETE ON todos BEGIN
+    DELETE FROM todos_fts WHERE todo_id = old.id;
+END;
@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN deleted_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_todos_deleted_at ON todos(deleted_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_todos_archived_at;
+ALTER TABLE users DROP COLUMN auto_archive_days;
+ALTER TABLE todos DROP COLUMN archived_at;
+ALTER TABLE todos DROP COLUMN completed_at;
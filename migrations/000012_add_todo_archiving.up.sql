@@ -0,0 +1,8 @@
+ALTER TABLE todos ADD COLUMN completed_at TEXT;
+ALTER TABLE todos ADD COLUMN archived_at TEXT;
+ALTER TABLE users ADD COLUMN auto_archive_days INTEGER NOT NULL DEFAULT 0;
+
+-- Completion times weren't recorded before; the last update is the best guess
+UPDATE todos SET completed_at = updated_at WHERE completed = 1;
+
+CREATE INDEX IF NOT EXISTS idx_todos_archived_at ON todos(archived_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_comments_todo_id;
+DROP TABLE IF EXISTS comments;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS attachments_queue_blob_deletion;
+DROP TABLE IF EXISTS blob_deletions;
+DROP INDEX IF EXISTS idx_attachments_todo_id;
+DROP TABLE IF EXISTS attachments;
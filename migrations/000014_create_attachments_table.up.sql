@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id TEXT PRIMARY KEY,
+    todo_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_attachments_todo_id ON attachments(todo_id, created_at);
+
+-- File contents live in the blob store, outside the database. Removing an
+-- attachment row, including through the cascade when its todo or user is
+-- deleted, queues its blob here until a worker deletes it.
+CREATE TABLE IF NOT EXISTS blob_deletions (
+    storage_key TEXT PRIMARY KEY,
+    queued_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER attachments_queue_blob_deletion AFTER DELETE ON attachments BEGIN
+    INSERT OR IGNORE INTO blob_deletions (storage_key) VALUES (old.storage_key);
+END;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_shares_user_id;
+DROP INDEX IF EXISTS idx_shares_project_user;
+DROP INDEX IF EXISTS idx_shares_todo_user;
+DROP TABLE IF EXISTS shares;
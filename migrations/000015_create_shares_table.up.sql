@@ -0,0 +1,15 @@
+-- A share grants one user access to a todo or to every todo in a project.
+-- Exactly one of todo_id and project_id is set.
+CREATE TABLE IF NOT EXISTS shares (
+    id TEXT PRIMARY KEY,
+    todo_id TEXT REFERENCES todos(id) ON DELETE CASCADE,
+    project_id TEXT REFERENCES projects(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'editor')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((todo_id IS NULL) != (project_id IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_shares_todo_user ON shares(todo_id, user_id) WHERE todo_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_shares_project_user ON shares(project_id, user_id) WHERE project_id IS NOT NULL;
+CREATE INDEX idx_shares_user_id ON shares(user_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS todo_dependencies (
+    todo_id TEXT NOT NULL,
+    blocker_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (todo_id, blocker_id),
+    CHECK (todo_id != blocker_id),
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE,
+    FOREIGN KEY (blocker_id) REFERENCES todos(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_todo_dependencies_blocker_id ON todo_dependencies(blocker_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS todo_revisions (
+    id TEXT PRIMARY KEY,
+    todo_id TEXT NOT NULL,
+    revision INTEGER NOT NULL,
+    user_id TEXT NOT NULL DEFAULT '',
+    state TEXT NOT NULL,
+    changes TEXT NOT NULL DEFAULT '{}',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (todo_id, revision),
+    FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE
+);
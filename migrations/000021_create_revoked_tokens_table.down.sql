@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN token_version;
+DROP INDEX IF EXISTS idx_revoked_tokens_expires_at;
+DROP TABLE IF EXISTS revoked_tokens;
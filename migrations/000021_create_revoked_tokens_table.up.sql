@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- Bumping a user's token version revokes every access token issued before
+ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+
+-- Each live family of refresh tokens is a session
+INSERT INTO sessions (id, user_id, created_at, last_seen_at, expires_at)
+SELECT family_id, user_id, MIN(created_at), MAX(created_at), MAX(expires_at)
+FROM refresh_tokens
+WHERE revoked_at IS NULL
+GROUP BY family_id, user_id;
@@ -0,0 +1 @@
+-- Times stay in UTC; the offsets they were stored with are not kept.
@@ -0,0 +1,17 @@
+-- Times used to be stored with the server's UTC offset, which breaks the text
+-- comparisons that filters and page cursors rely on. Rewrite them in UTC,
+-- keeping their fractional seconds.
+UPDATE todos SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, -6))
+    || CASE WHEN instr(created_at, '.') > 0 THEN substr(created_at, instr(created_at, '.'), length(created_at) - 5 - instr(created_at, '.')) ELSE '' END
+    || 'Z'
+WHERE created_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE todos SET updated_at = strftime('%Y-%m-%dT%H:%M:%S', substr(updated_at, 1, 19) || substr(updated_at, -6))
+    || CASE WHEN instr(updated_at, '.') > 0 THEN substr(updated_at, instr(updated_at, '.'), length(updated_at) - 5 - instr(updated_at, '.')) ELSE '' END
+    || 'Z'
+WHERE updated_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE users SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, -6))
+    || CASE WHEN instr(created_at, '.') > 0 THEN substr(created_at, instr(created_at, '.'), length(created_at) - 5 - instr(created_at, '.')) ELSE '' END
+    || 'Z'
+WHERE created_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
@@ -0,0 +1,57 @@
+-- Like 000024 for the tables around todos, whose times were stored with the
+-- server's UTC offset too.
+
+UPDATE tags SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, -6))
+    || CASE WHEN instr(created_at, '.') > 0 THEN substr(created_at, instr(created_at, '.'), length(created_at) - 5 - instr(created_at, '.')) ELSE '' END
+    || 'Z'
+WHERE created_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE subtasks SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, -6))
+    || CASE WHEN instr(created_at, '.') > 0 THEN substr(created_at, instr(created_at, '.'), length(created_at) - 5 - instr(created_at, '.')) ELSE '' END
+    || 'Z'
+WHERE created_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE subtasks SET updated_at = strftime('%Y-%m-%dT%H:%M:%S', substr(updated_at, 1, 19) || substr(updated_at, -6))
+    || CASE WHEN instr(updated_at, '.') > 0 THEN substr(updated_at, instr(updated_at, '.'), length(updated_at) - 5 - instr(updated_at, '.')) ELSE '' END
+    || 'Z'
+WHERE updated_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE projects SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, -6))
+    || CASE WHEN instr(created_at, '.') > 0 THEN substr(created_at, instr(created_at, '.'), length(created_at) - 5 - instr(created_at, '.')) ELSE '' END
+    || 'Z'
+WHERE created_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE projects SET updated_at = strftime('%Y-%m-%dT%H:%M:%S', substr(updated_at, 1, 19) || substr(updated_at, -6))
+    || CASE WHEN instr(updated_at, '.') > 0 THEN substr(updated_at, instr(updated_at, '.'), length(updated_at) - 5 - instr(updated_at, '.')) ELSE '' END
+    || 'Z'
+WHERE updated_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE comments SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, -6))
+    || CASE WHEN instr(created_at, '.') > 0 THEN substr(created_at, instr(created_at, '.'), length(created_at) - 5 - instr(created_at, '.')) ELSE '' END
+    || 'Z'
+WHERE created_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE comments SET updated_at = strftime('%Y-%m-%dT%H:%M:%S', substr(updated_at, 1, 19) || substr(updated_at, -6))
+    || CASE WHEN instr(updated_at, '.') > 0 THEN substr(updated_at, instr(updated_at, '.'), length(updated_at) - 5 - instr(updated_at, '.')) ELSE '' END
+    || 'Z'
+WHERE updated_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE attachments SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, -6))
+    || CASE WHEN instr(created_at, '.') > 0 THEN substr(created_at, instr(created_at, '.'), length(created_at) - 5 - instr(created_at, '.')) ELSE '' END
+    || 'Z'
+WHERE created_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE shares SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, -6))
+    || CASE WHEN instr(created_at, '.') > 0 THEN substr(created_at, instr(created_at, '.'), length(created_at) - 5 - instr(created_at, '.')) ELSE '' END
+    || 'Z'
+WHERE created_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE todo_dependencies SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, -6))
+    || CASE WHEN instr(created_at, '.') > 0 THEN substr(created_at, instr(created_at, '.'), length(created_at) - 5 - instr(created_at, '.')) ELSE '' END
+    || 'Z'
+WHERE created_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
+
+UPDATE todo_revisions SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, -6))
+    || CASE WHEN instr(created_at, '.') > 0 THEN substr(created_at, instr(created_at, '.'), length(created_at) - 5 - instr(created_at, '.')) ELSE '' END
+    || 'Z'
+WHERE created_at GLOB '*[+-][0-9][0-9]:[0-9][0-9]';
@@ -0,0 +1,92 @@
+package pages
+
+import (
+	"net/url"
+
+	"godo/internal/domain"
+)
+
+// todosURL builds a /todos link for the project or shared listing, sort, tag
+// filter and archived toggle.
+func todosURL(filter domain.TodoFilter) string {
+	q := url.Values{}
+	if filter.ProjectID != "" {
+		q.Set("project", filter.ProjectID)
+	}
+	if filter.Shared {
+		q.Set("shared", "true")
+	}
+	if filter.Sort != "" && filter.Sort != domain.SortManual {
+		q.Set("sort", string(filter.Sort))
+	}
+	for _, tag := range filter.Tags {
+		q.Add("tag", tag)
+	}
+	if filter.MatchAllTags && len(filter.Tags) > 1 {
+		q.Set("tag_mode", "all")
+	}
+	if filter.IncludeArchived {
+		q.Set("include_archived", "true")
+	}
+
+	if len(q) == 0 {
+		return "/todos"
+	}
+	return "/todos?" + q.Encode()
+}
+
+// withSort keeps the project and tag filter but switches the ordering.
+func withSort(filter domain.TodoFilter, sort domain.TodoSort) domain.TodoFilter {
+	return domain.TodoFilter{ProjectID: filter.ProjectID, Shared: filter.Shared, Sort: sort, Tags: filter.Tags, MatchAllTags: filter.MatchAllTags, IncludeArchived: filter.IncludeArchived}
+}
+
+// withoutTags keeps the project and sort but drops the tag filter.
+func withoutTags(filter domain.TodoFilter) domain.TodoFilter {
+	return domain.TodoFilter{ProjectID: filter.ProjectID, Shared: filter.Shared, Sort: filter.Sort, IncludeArchived: filter.IncludeArchived}
+}
+
+// withProject switches to another project, keeping only the sort and the
+// archived toggle.
+func withProject(filter domain.TodoFilter, projectID string) domain.TodoFilter {
+	return domain.TodoFilter{ProjectID: projectID, Sort: filter.Sort, IncludeArchived: filter.IncludeArchived}
+}
+
+// withShared switches to the todos shared with the user, keeping only the
+// sort and the archived toggle.
+func withShared(filter domain.TodoFilter) domain.TodoFilter {
+	return domain.TodoFilter{Shared: true, Sort: filter.Sort, IncludeArchived: filter.IncludeArchived}
+}
+
+// withArchived keeps the current filter but shows or hides archived todos.
+func withArchived(filter domain.TodoFilter, include bool) domain.TodoFilter {
+	filter.IncludeArchived = include
+	return filter
+}
+
+// pageHeading names the selected project or the shared listing, falling
+// back to "My Todos".
+func pageHeading(projects, sharedProjects []*domain.Project, filter domain.TodoFilter) string {
+	if filter.Shared {
+		return "Shared with me"
+	}
+	for _, project := range append(projects, sharedProjects...) {
+		if project.ID == filter.ProjectID {
+			return project.Name
+		}
+	}
+	return "My Todos"
+}
+
+// viewingShared reports whether the listing shows other users' todos, where
+// new todos can't be added and the order can't be changed.
+func viewingShared(sharedProjects []*domain.Project, filter domain.TodoFilter) bool {
+	if filter.Shared {
+		return true
+	}
+	for _, project := range sharedProjects {
+		if project.ID == filter.ProjectID {
+			return true
+		}
+	}
+	return false
+}